@@ -0,0 +1,240 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+
+	"github.com/eroatta/freqtable/adapter/persistence"
+	"github.com/eroatta/freqtable/adapter/wordcount"
+	"github.com/eroatta/freqtable/adapter/wordcount/cloner"
+	"github.com/eroatta/freqtable/adapter/wordcount/miner"
+	"github.com/eroatta/freqtable/repository"
+	"github.com/eroatta/freqtable/usecase"
+	"github.com/joho/godotenv"
+)
+
+const (
+	memoryStorage   = "memory"
+	postgresStorage = "postgres"
+)
+
+// defaultMaxRevisions defines the number of revisions extracted on a series when MAX_REVISIONS
+// isn't set, since each one is a full extraction.
+const defaultMaxRevisions = 100
+
+var (
+	// errUsage indicates that the command line arguments are invalid.
+	errUsage = errors.New("invalid command line arguments")
+	// errUnknownStorage indicates that the selected storage isn't supported.
+	errUnknownStorage = errors.New("unknown storage, use memory or postgres")
+	// errMemoryStorage indicates that the command reads the stored frequency tables, which a new
+	// memory storage never holds.
+	errMemoryStorage = errors.New("the memory storage starts empty on each run, use postgres to merge, show or export stored frequency tables")
+)
+
+// dependencies holds the use cases available for the commands.
+type dependencies struct {
+	usecase.Usecases
+	startJobs func(ctx context.Context) error
+}
+
+// dependenciesBuilder creates the dependencies of the given command for the given storage. It also
+// returns a deferrable operation to release the resources.
+type dependenciesBuilder func(storage string, command string) (dependencies, func(), error)
+
+// command executes a subcommand with its arguments, writing its results on the given output and
+// its diagnostics on the given error output.
+type command func(ctx context.Context, deps dependencies, args []string, out io.Writer, errOut io.Writer) error
+
+// memoryCommands holds the commands that can run on the memory storage, since they don't read the
+// frequency tables stored by a previous run.
+var memoryCommands = map[string]bool{
+	"extract": true,
+	"serve":   true,
+}
+
+var commands = map[string]command{
+	"extract": extract,
+	"merge":   merge,
+	"show":    show,
+	"export":  export,
+	"serve":   serve,
+}
+
+// Run executes the command described by the given command line arguments, and returns the
+// exit code for the process. If no command is given, the REST API is served.
+func Run(args []string, stdout io.Writer, stderr io.Writer) int {
+	return run(args, stdout, stderr, newDependencies)
+}
+
+func run(args []string, stdout io.Writer, stderr io.Writer, build dependenciesBuilder) int {
+	flags := flag.NewFlagSet("freqtable", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	storage := flags.String("storage", postgresStorage, "storage for the frequency tables: memory or postgres")
+	flags.Usage = func() { usage(stderr, flags) }
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+
+	name := "serve"
+	cmdArgs := flags.Args()
+	if len(cmdArgs) > 0 {
+		name, cmdArgs = cmdArgs[0], cmdArgs[1:]
+	}
+
+	cmd, ok := commands[name]
+	if !ok {
+		fmt.Fprintf(stderr, "unknown command %q\n", name)
+		usage(stderr, flags)
+		return 2
+	}
+
+	if *storage == memoryStorage && !memoryCommands[name] {
+		fmt.Fprintln(stderr, errMemoryStorage)
+		return 1
+	}
+
+	deps, deferrable, err := build(*storage, name)
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return 1
+	}
+	defer deferrable()
+
+	switch err := cmd(context.Background(), deps, cmdArgs, stdout, stderr); err {
+	case nil:
+		return 0
+	case errUsage, flag.ErrHelp:
+		usage(stderr, flags)
+		return 2
+	default:
+		fmt.Fprintln(stderr, err)
+		return 1
+	}
+}
+
+func usage(out io.Writer, flags *flag.FlagSet) {
+	fmt.Fprint(out, `Usage: freqtable [--storage memory|postgres] <command> [arguments]
+
+Commands:
+  extract [--format text|csv|json] [--granularity repository|module|package] <url|path>...
+                                                           extracts a frequency table for each repository
+  merge --name <name> <id> <id>...                         merges the frequency tables into a new one
+  show [--sort count|word] [--offset n] [--limit n] <id>   shows a frequency table and its words
+  export [--format csv|json] [<id>...]                     exports the frequency tables (all by default)
+  serve [--addr address]                                   serves the REST API (default command)
+
+Flags:
+`)
+	flags.PrintDefaults()
+}
+
+// newDependencies creates the processor, the repositories and the use cases of the given command for
+// the given storage. Local repositories can only be extracted by the extract command, so that the
+// REST API doesn't expose the files of the server.
+func newDependencies(storage string, command string) (dependencies, func(), error) {
+	// processor configuration
+	var credentials cloner.HostCredentials
+	if path := os.Getenv("GIT_CREDENTIALS_FILE"); path != "" {
+		var err error
+		if credentials, err = cloner.LoadCredentials(path); err != nil {
+			return dependencies{}, func() {}, err
+		}
+	}
+
+	var maxSize, maxGoFiles, maxFileSize, cacheSize int64
+	var maxRevisions int64 = defaultMaxRevisions
+	limits := []struct {
+		name  string
+		value *int64
+	}{
+		{"MAX_REPOSITORY_SIZE", &maxSize},
+		{"MAX_GO_FILES", &maxGoFiles},
+		{"MAX_FILE_SIZE", &maxFileSize},
+		{"GIT_CACHE_MAX_SIZE", &cacheSize},
+		{"MAX_REVISIONS", &maxRevisions},
+	}
+	for _, limit := range limits {
+		if value := os.Getenv(limit.name); value != "" {
+			var err error
+			if *limit.value, err = strconv.ParseInt(value, 10, 64); err != nil {
+				return dependencies{}, func() {}, fmt.Errorf("invalid %s %s: %v", limit.name, value, err)
+			}
+		}
+	}
+
+	gitCloner := cloner.New(credentials, maxSize)
+	if dir := os.Getenv("GIT_CACHE_DIR"); dir != "" {
+		gitCloner = cloner.NewCached(credentials, dir, cacheSize, maxSize)
+	}
+
+	config := wordcount.ProcessorConfig{
+		Cloner:        gitCloner,
+		ArchiveCloner: cloner.NewArchive(maxSize),
+		ModuleCloner:  cloner.NewModule(os.Getenv("GOPROXY"), maxSize),
+		MinerFactory:  func() wordcount.Miner { return miner.NewCount() },
+		Limits: wordcount.Limits{
+			MaxGoFiles:   int(maxGoFiles),
+			MaxFileSize:  maxFileSize,
+			MaxRevisions: int(maxRevisions),
+		},
+	}
+	if command == "extract" {
+		config.LocalCloner = cloner.NewLocal()
+	}
+	processor := wordcount.NewProcessor(config)
+
+	// storage configuration
+	var ftStorage repository.FrequencyTableRepository
+	var jobStorage repository.JobRepository
+	var seriesStorage repository.SeriesRepository
+	deferrable := func() {}
+	switch storage {
+	case memoryStorage:
+		ftStorage = persistence.NewInMemory()
+		jobStorage = persistence.NewInMemoryJob()
+		seriesStorage = persistence.NewInMemorySeries()
+
+	case postgresStorage:
+		if err := godotenv.Load(); err != nil {
+			return dependencies{}, deferrable, fmt.Errorf("error while loading the env configuration: %v", err)
+		}
+
+		conn, closer, err := persistence.NewConnection(os.Getenv("DB_HOST"),
+			os.Getenv("DB_PORT"), os.Getenv("DB_USER"), os.Getenv("DB_PASSWORD"), os.Getenv("DB_NAME"))
+		if err != nil {
+			return dependencies{}, deferrable, err
+		}
+		deferrable = closer
+		ftStorage = persistence.NewPostgreSQL(conn)
+		jobStorage = persistence.NewPostgreSQLJob(conn)
+		seriesStorage = persistence.NewPostgreSQLSeries(conn)
+
+	default:
+		return dependencies{}, deferrable, errUnknownStorage
+	}
+
+	// rules engine configuration
+	createFreqTableUC := usecase.NewCreateFrequencyTableUsecase(processor, ftStorage)
+	updateFreqTableUC := usecase.NewUpdateFrequencyTableUsecase(processor, ftStorage)
+	seriesUC := usecase.NewSeriesUsecase(processor, ftStorage, seriesStorage)
+	jobUC := usecase.NewExtractionJobUsecase(createFreqTableUC, updateFreqTableUC, seriesUC, ftStorage, jobStorage, 2)
+
+	return dependencies{
+		Usecases: usecase.Usecases{
+			Create: createFreqTableUC,
+			Merge:  usecase.NewMergeFrequencyTableUsecase(ftStorage),
+			Get:    usecase.NewGetFrequencyTableUsecase(ftStorage),
+			Update: updateFreqTableUC,
+			Job:    jobUC,
+			Query:  usecase.NewQueryFrequencyTableUsecase(ftStorage),
+			Series: seriesUC,
+		},
+		startJobs: jobUC.Start,
+	}, deferrable, nil
+}
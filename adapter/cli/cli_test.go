@@ -0,0 +1,251 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/eroatta/freqtable/adapter/persistence"
+	"github.com/eroatta/freqtable/entity"
+	"github.com/eroatta/freqtable/repository"
+	"github.com/eroatta/freqtable/usecase"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRun_OnUnknownCommand_ShouldReturnUsageExitCode(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+	code := run([]string{"unknown"}, &stdout, &stderr, testDependencies())
+
+	assert.Equal(t, 2, code)
+	assert.Contains(t, stderr.String(), `unknown command "unknown"`)
+	assert.Contains(t, stderr.String(), "Usage: freqtable")
+}
+
+func TestRun_OnUnknownStorage_ShouldReturnError(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+	code := run([]string{"--storage", "mongodb", "show", "1"}, &stdout, &stderr, newDependencies)
+
+	assert.Equal(t, 1, code)
+	assert.Equal(t, "unknown storage, use memory or postgres\n", stderr.String())
+}
+
+func TestRun_OnMemoryStorageWithStoredTablesCommand_ShouldReturnError(t *testing.T) {
+	for _, args := range [][]string{{"merge", "--name", "global", "1", "2"}, {"show", "1"}, {"export"}} {
+		var stdout, stderr bytes.Buffer
+		built := false
+		build := func(storage string, command string) (dependencies, func(), error) {
+			built = true
+			return testDependencies()(storage, command)
+		}
+		code := run(append([]string{"--storage", "memory"}, args...), &stdout, &stderr, build)
+
+		assert.Equal(t, 1, code, args[0])
+		assert.Equal(t, errMemoryStorage.Error()+"\n", stderr.String(), args[0])
+		assert.False(t, built, args[0])
+	}
+}
+
+func TestRun_OnExtractWithoutURLs_ShouldReturnUsageExitCode(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+	code := run([]string{"extract"}, &stdout, &stderr, testDependencies())
+
+	assert.Equal(t, 2, code)
+	assert.Contains(t, stderr.String(), "Usage: freqtable")
+}
+
+func TestRun_OnExtractWithUnknownGranularity_ShouldReturnUsageExitCode(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+	code := run([]string{"extract", "--granularity", "file", "https://github.com/eroatta/freqtable"}, &stdout, &stderr, testDependencies())
+
+	assert.Equal(t, 2, code)
+	assert.Contains(t, stderr.String(), "Usage: freqtable")
+}
+
+func TestRun_OnExtract_ShouldPrintFrequencyTables(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+	code := run([]string{"extract", "https://github.com/eroatta/freqtable", "https://github.com/eroatta/token"},
+		&stdout, &stderr, testDependencies())
+
+	assert.Equal(t, 0, code)
+	lines := strings.Split(strings.TrimSpace(stdout.String()), "\n")
+	assert.Equal(t, 3, len(lines))
+	assert.Regexp(t, "^ID +NAME +VOCABULARY +DATE CREATED$", lines[0])
+	assert.Regexp(t, "^[12] +https://github.com/eroatta/freqtable +3 ", lines[1])
+	assert.Regexp(t, "^[12] +https://github.com/eroatta/token +2 ", lines[2])
+}
+
+func TestRun_OnExtractWithCSVFormat_ShouldPrintWords(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+	code := run([]string{"extract", "--format", "csv", "https://github.com/eroatta/freqtable"},
+		&stdout, &stderr, testDependencies())
+
+	assert.Equal(t, 0, code)
+	assert.Equal(t, "id,name,word,count\n"+
+		"1,https://github.com/eroatta/freqtable,table,5\n"+
+		"1,https://github.com/eroatta/freqtable,frequency,2\n"+
+		"1,https://github.com/eroatta/freqtable,word,2\n", stdout.String())
+}
+
+func TestRun_OnExtractWithFailingURL_ShouldReturnError(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+	code := run([]string{"extract", "https://github.com/eroatta/unknown", "https://github.com/eroatta/token"},
+		&stdout, &stderr, testDependencies())
+
+	assert.Equal(t, 1, code)
+	assert.NotContains(t, stdout.String(), "error extracting")
+	assert.Contains(t, stdout.String(), "https://github.com/eroatta/token")
+	assert.Equal(t, "error extracting https://github.com/eroatta/unknown: repository not found\n"+
+		"one or more extractions failed\n", stderr.String())
+}
+
+func TestRun_OnMergeWithoutName_ShouldReturnUsageExitCode(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+	code := run([]string{"merge", "1", "2"}, &stdout, &stderr, testDependencies())
+
+	assert.Equal(t, 2, code)
+}
+
+func TestRun_OnMergeWithInvalidID_ShouldReturnError(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+	code := run([]string{"merge", "--name", "global", "1", "two"}, &stdout, &stderr, testDependencies())
+
+	assert.Equal(t, 1, code)
+	assert.Equal(t, "invalid frequency table ID \"two\"\n", stderr.String())
+}
+
+func TestRun_OnShowWithNonExistingID_ShouldReturnError(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+	code := run([]string{"show", "1"}, &stdout, &stderr, testDependencies())
+
+	assert.Equal(t, 1, code)
+	assert.Equal(t, "No results for the given query\n", stderr.String())
+}
+
+func TestRun_OnShowExtractedFrequencyTable_ShouldPrintReport(t *testing.T) {
+	deps, _, _ := testDependencies()(memoryStorage, "")
+	build := func(storage string, command string) (dependencies, func(), error) {
+		return deps, func() {}, nil
+	}
+
+	var stdout, stderr bytes.Buffer
+	code := run([]string{"extract", "https://github.com/eroatta/token"}, &stdout, &stderr, build)
+	assert.Equal(t, 0, code)
+
+	stdout.Reset()
+	code = run([]string{"show", "1"}, &stdout, &stderr, build)
+	assert.Equal(t, 0, code)
+	output := stdout.String()
+	assert.Regexp(t, "Revision: +0a1b2c3d4e5f60718293a4b5c6d7e8f901234567 \\(2020-03-01T10:00:00Z\\)\n", output)
+	assert.Regexp(t, "Files: +2 \\(1 Go files, 0 excluded, 1 parsed\\)\n", output)
+	assert.Regexp(t, "Extraction time: +0s\n", output)
+	assert.NotContains(t, output, "SKIPPED FILE")
+	assert.Empty(t, stderr.String())
+}
+
+func TestRun_OnExtractMergeShowAndExport_ShouldShareTheStorage(t *testing.T) {
+	deps, _, _ := testDependencies()(memoryStorage, "")
+	build := func(storage string, command string) (dependencies, func(), error) {
+		return deps, func() {}, nil
+	}
+
+	var stdout, stderr bytes.Buffer
+	code := run([]string{"extract", "https://github.com/eroatta/freqtable", "https://github.com/eroatta/token"},
+		&stdout, &stderr, build)
+	assert.Equal(t, 0, code)
+
+	stdout.Reset()
+	code = run([]string{"merge", "--name", "global", "1", "2"}, &stdout, &stderr, build)
+	assert.Equal(t, 0, code)
+	assert.Regexp(t, "3 +global +4 ", stdout.String())
+
+	stdout.Reset()
+	code = run([]string{"show", "--limit", "2", "3"}, &stdout, &stderr, build)
+	assert.Equal(t, 0, code)
+	output := stdout.String()
+	assert.Regexp(t, "Name: +global\n", output)
+	assert.Regexp(t, "Vocabulary: +4\n", output)
+	assert.Regexp(t, "WORD +COUNT\ntable +6\ntoken +3\n$", output)
+
+	stdout.Reset()
+	code = run([]string{"export", "--format", "json"}, &stdout, &stderr, build)
+	assert.Equal(t, 0, code)
+	var exported []map[string]interface{}
+	if err := json.Unmarshal(stdout.Bytes(), &exported); err != nil {
+		assert.FailNow(t, "unexpected unmarshalling err: %v", err)
+	}
+	assert.Equal(t, 3, len(exported))
+	assert.Equal(t, "global", exported[2]["name"])
+	assert.Equal(t, map[string]interface{}{
+		"table":     float64(6),
+		"frequency": float64(2),
+		"word":      float64(2),
+		"token":     float64(3),
+	}, exported[2]["values"])
+	assert.Empty(t, stderr.String())
+}
+
+func TestNewDependencies_OnServe_ShouldRejectLocalRepositories(t *testing.T) {
+	deps, deferrable, err := newDependencies(memoryStorage, "serve")
+	assert.NoError(t, err)
+	defer deferrable()
+
+	for _, url := range []string{"file:///etc", "/var/backups/freqtable.tar.gz"} {
+		_, err = deps.Create.Create(context.TODO(), url, entity.ExtractionOptions{})
+		assert.Equal(t, repository.ErrLocalRepository, err, url)
+	}
+}
+
+func TestNewDependencies_OnInvalidLimit_ShouldReturnError(t *testing.T) {
+	os.Setenv("MAX_FILE_SIZE", "1MB")
+	defer os.Unsetenv("MAX_FILE_SIZE")
+
+	_, deferrable, err := newDependencies(memoryStorage, "extract")
+	defer deferrable()
+
+	assert.EqualError(t, err, `invalid MAX_FILE_SIZE 1MB: strconv.ParseInt: parsing "1MB": invalid syntax`)
+}
+
+// testDependencies creates an in-memory set of dependencies, with a fixed set of extractions.
+func testDependencies() dependenciesBuilder {
+	return func(storage string, command string) (dependencies, func(), error) {
+		wcr := testWordCountRepository{
+			"https://github.com/eroatta/freqtable": {"frequency": 2, "table": 5, "word": 2},
+			"https://github.com/eroatta/token":     {"token": 3, "table": 1},
+		}
+		ftr := persistence.NewInMemory()
+
+		createUC := usecase.NewCreateFrequencyTableUsecase(wcr, ftr)
+		return dependencies{
+			Usecases: usecase.Usecases{
+				Create: createUC,
+				Merge:  usecase.NewMergeFrequencyTableUsecase(ftr),
+				Get:    usecase.NewGetFrequencyTableUsecase(ftr),
+			},
+			startJobs: func(ctx context.Context) error { return nil },
+		}, func() {}, nil
+	}
+}
+
+type testWordCountRepository map[string]map[string]int
+
+func (t testWordCountRepository) Extract(ctx context.Context, url string, options entity.ExtractionOptions) (entity.Extraction, error) {
+	if values, ok := t[url]; ok {
+		return entity.Extraction{
+			Values:   values,
+			Options:  options,
+			Revision: &entity.Revision{Hash: "0a1b2c3d4e5f60718293a4b5c6d7e8f901234567", Date: time.Date(2020, time.March, 1, 10, 0, 0, 0, time.UTC)},
+			Report:   entity.ExtractionReport{Files: 2, GoFiles: 1, ParsedFiles: 1},
+		}, nil
+	}
+
+	return entity.Extraction{}, errors.New("repository not found")
+}
+
+func (t testWordCountRepository) ExtractHistory(ctx context.Context, url string, selection entity.RevisionSelection, options entity.ExtractionOptions, fn func(entity.Extraction) error) error {
+	return errors.New("history not available")
+}
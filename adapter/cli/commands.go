@@ -0,0 +1,211 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"strconv"
+
+	"github.com/eroatta/freqtable/adapter/rest"
+	"github.com/eroatta/freqtable/entity"
+	"github.com/eroatta/freqtable/repository"
+	"github.com/eroatta/freqtable/usecase"
+)
+
+const (
+	textFormat = "text"
+	csvFormat  = "csv"
+	jsonFormat = "json"
+)
+
+// exportPageSize defines the number of frequency tables retrieved at once while exporting.
+const exportPageSize = 100
+
+// errFailedExtractions indicates that at least one of the requested extractions failed.
+var errFailedExtractions = errors.New("one or more extractions failed")
+
+// newFlagSet creates a flag set for a subcommand, which doesn't print errors on its own.
+func newFlagSet(name string) *flag.FlagSet {
+	flags := flag.NewFlagSet(name, flag.ContinueOnError)
+	flags.SetOutput(ioutil.Discard)
+	return flags
+}
+
+// extract creates a frequency table for each given URL and prints the results, along with the errors
+// of the failed extractions on the error output. Unless the granularity is the whole repository, a
+// child frequency table is also created for each module or package, but only the parent ones are printed.
+func extract(ctx context.Context, deps dependencies, args []string, out io.Writer, errOut io.Writer) error {
+	flags := newFlagSet("extract")
+	format := flags.String("format", textFormat, "output format: text, csv or json")
+	granularity := flags.String("granularity", string(entity.GranularityRepository), "extraction granularity: repository, module or package")
+	if err := flags.Parse(args); err != nil || flags.NArg() == 0 || !validFormat(*format, textFormat, csvFormat, jsonFormat) ||
+		!validFormat(*granularity, string(entity.GranularityRepository), string(entity.GranularityModule), string(entity.GranularityPackage)) {
+		return errUsage
+	}
+
+	options := entity.ExtractionOptions{Granularity: entity.Granularity(*granularity)}
+	results := deps.Create.CreateMultiple(ctx, flags.Args(), options)
+
+	failed := false
+	tables := make([]entity.FrequencyTable, 0, len(results))
+	for _, result := range results {
+		if result.Error != nil {
+			failed = true
+			fmt.Fprintf(errOut, "error extracting %s: %v\n", result.URL, result.Error)
+			continue
+		}
+		tables = append(tables, result.FrequencyTable)
+	}
+
+	if err := writeFrequencyTables(out, tables, *format); err != nil {
+		return err
+	}
+
+	if failed {
+		return errFailedExtractions
+	}
+
+	return nil
+}
+
+// merge merges the frequency tables identified by the given IDs and prints the resulting one.
+func merge(ctx context.Context, deps dependencies, args []string, out io.Writer, errOut io.Writer) error {
+	flags := newFlagSet("merge")
+	name := flags.String("name", "", "name for the merged frequency table")
+	if err := flags.Parse(args); err != nil || *name == "" {
+		return errUsage
+	}
+
+	ids, err := parseIDs(flags.Args())
+	if err != nil {
+		return err
+	}
+
+	ft, err := deps.Merge.Merge(ctx, *name, ids)
+	if err != nil {
+		return err
+	}
+
+	return writeFrequencyTables(out, []entity.FrequencyTable{ft}, textFormat)
+}
+
+// show prints the details of a frequency table and a page of its words.
+func show(ctx context.Context, deps dependencies, args []string, out io.Writer, errOut io.Writer) error {
+	flags := newFlagSet("show")
+	sort := flags.String("sort", string(usecase.OrderByCount), "words order: count or word")
+	offset := flags.Int("offset", 0, "number of words to skip")
+	limit := flags.Int("limit", 20, "maximum number of words to show, zero for all of them")
+	if err := flags.Parse(args); err != nil || flags.NArg() != 1 ||
+		!validFormat(*sort, string(usecase.OrderByCount), string(usecase.OrderByWord)) {
+		return errUsage
+	}
+
+	ids, err := parseIDs(flags.Args())
+	if err != nil {
+		return err
+	}
+
+	ft, words, err := deps.Get.Get(ctx, ids[0], usecase.WordsQuery{
+		Order:  usecase.WordOrder(*sort),
+		Offset: *offset,
+		Limit:  *limit,
+	})
+	if err != nil {
+		return err
+	}
+
+	return writeDetails(out, ft, words)
+}
+
+// export prints the given frequency tables, or every stored frequency table if none is given,
+// including all their words.
+func export(ctx context.Context, deps dependencies, args []string, out io.Writer, errOut io.Writer) error {
+	flags := newFlagSet("export")
+	format := flags.String("format", csvFormat, "output format: csv or json")
+	if err := flags.Parse(args); err != nil || !validFormat(*format, csvFormat, jsonFormat) {
+		return errUsage
+	}
+
+	ids, err := parseIDs(flags.Args())
+	if err != nil {
+		return err
+	}
+
+	if len(ids) == 0 {
+		filter := repository.FrequencyTableFilter{Limit: exportPageSize}
+		for {
+			summaries, next, err := deps.Get.List(ctx, filter)
+			if err != nil {
+				return err
+			}
+
+			for _, summary := range summaries {
+				ids = append(ids, summary.ID)
+			}
+
+			if next == 0 {
+				break
+			}
+			filter.Cursor = next
+		}
+	}
+
+	tables := make([]entity.FrequencyTable, 0, len(ids))
+	for _, id := range ids {
+		ft, err := deps.Get.Find(ctx, id)
+		if err != nil {
+			return err
+		}
+		tables = append(tables, ft)
+	}
+
+	return writeFrequencyTables(out, tables, *format)
+}
+
+// serve starts the extraction workers and serves the REST API.
+func serve(ctx context.Context, deps dependencies, args []string, out io.Writer, errOut io.Writer) error {
+	flags := newFlagSet("serve")
+	addr := flags.String("addr", "", "address to listen on (defaults to $PORT or :8080)")
+	if err := flags.Parse(args); err != nil || flags.NArg() != 0 {
+		return errUsage
+	}
+
+	if err := deps.startJobs(ctx); err != nil {
+		return fmt.Errorf("error while starting the extraction workers: %v", err)
+	}
+
+	r := rest.NewServer(deps.Usecases)
+	if *addr == "" {
+		return r.Run()
+	}
+
+	return r.Run(*addr)
+}
+
+// parseIDs converts the given arguments into frequency table IDs.
+func parseIDs(args []string) ([]int64, error) {
+	ids := make([]int64, 0, len(args))
+	for _, arg := range args {
+		id, err := strconv.ParseInt(arg, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid frequency table ID %q", arg)
+		}
+		ids = append(ids, id)
+	}
+
+	return ids, nil
+}
+
+// validFormat checks if the value is one of the allowed ones.
+func validFormat(value string, allowed ...string) bool {
+	for _, a := range allowed {
+		if value == a {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,149 @@
+package cli
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"text/tabwriter"
+	"time"
+
+	"github.com/eroatta/freqtable/entity"
+)
+
+type freqTableOutput struct {
+	ID          int64          `json:"id"`
+	Name        string         `json:"name"`
+	DateCreated string         `json:"date_created"`
+	LastUpdated string         `json:"last_updated,omitempty"`
+	Values      map[string]int `json:"values"`
+}
+
+// writeFrequencyTables writes the given frequency tables using the given format. The text format
+// only includes the metadata, while the csv and json formats also include every word.
+func writeFrequencyTables(out io.Writer, tables []entity.FrequencyTable, format string) error {
+	switch format {
+	case csvFormat:
+		return writeCSV(out, tables)
+	case jsonFormat:
+		return writeJSON(out, tables)
+	default:
+		return writeText(out, tables)
+	}
+}
+
+func writeText(out io.Writer, tables []entity.FrequencyTable) error {
+	w := tabwriter.NewWriter(out, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "ID\tNAME\tVOCABULARY\tDATE CREATED")
+	for _, ft := range tables {
+		fmt.Fprintf(w, "%d\t%s\t%d\t%s\n", ft.ID, ft.Name, len(ft.Values), ft.DateCreated.Format(time.RFC3339))
+	}
+
+	return w.Flush()
+}
+
+func writeCSV(out io.Writer, tables []entity.FrequencyTable) error {
+	w := csv.NewWriter(out)
+	if err := w.Write([]string{"id", "name", "word", "count"}); err != nil {
+		return err
+	}
+
+	for _, ft := range tables {
+		id := strconv.FormatInt(ft.ID, 10)
+		for _, wc := range sortedWords(ft.Values) {
+			if err := w.Write([]string{id, ft.Name, wc.Word, strconv.Itoa(wc.Count)}); err != nil {
+				return err
+			}
+		}
+	}
+	w.Flush()
+
+	return w.Error()
+}
+
+func writeJSON(out io.Writer, tables []entity.FrequencyTable) error {
+	output := make([]freqTableOutput, 0, len(tables))
+	for _, ft := range tables {
+		item := freqTableOutput{
+			ID:          ft.ID,
+			Name:        ft.Name,
+			DateCreated: ft.DateCreated.Format(time.RFC3339),
+			Values:      ft.Values,
+		}
+		if !ft.LastUpdated.IsZero() {
+			item.LastUpdated = ft.LastUpdated.Format(time.RFC3339)
+		}
+		output = append(output, item)
+	}
+
+	encoder := json.NewEncoder(out)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(output)
+}
+
+// writeDetails writes the metadata of a frequency table and the given words.
+func writeDetails(out io.Writer, ft entity.FrequencyTable, words []entity.WordCount) error {
+	w := tabwriter.NewWriter(out, 0, 4, 2, ' ', 0)
+	fmt.Fprintf(w, "ID:\t%d\n", ft.ID)
+	fmt.Fprintf(w, "Name:\t%s\n", ft.Name)
+	fmt.Fprintf(w, "Date created:\t%s\n", ft.DateCreated.Format(time.RFC3339))
+	if !ft.LastUpdated.IsZero() {
+		fmt.Fprintf(w, "Last updated:\t%s\n", ft.LastUpdated.Format(time.RFC3339))
+	}
+	if ft.Revision != nil {
+		fmt.Fprintf(w, "Revision:\t%s (%s)\n", ft.Revision.Hash, ft.Revision.Date.Format(time.RFC3339))
+	}
+	fmt.Fprintf(w, "Vocabulary:\t%d\n", len(ft.Values))
+	if ft.Report != nil {
+		fmt.Fprintf(w, "Files:\t%d (%d Go files, %d excluded, %d parsed)\n",
+			ft.Report.Files, ft.Report.GoFiles, ft.Report.ExcludedFiles, ft.Report.ParsedFiles)
+		fmt.Fprintf(w, "Extraction time:\t%s\n", ft.Report.TotalDuration.Round(time.Millisecond))
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+
+	if ft.Report != nil && len(ft.Report.SkippedFiles) > 0 {
+		fmt.Fprintln(out)
+		w = tabwriter.NewWriter(out, 0, 4, 2, ' ', 0)
+		fmt.Fprintln(w, "SKIPPED FILE\tREASON")
+		for _, f := range ft.Report.SkippedFiles {
+			name := f.Name
+			if f.Line > 0 {
+				name = fmt.Sprintf("%s:%d:%d", f.Name, f.Line, f.Column)
+			}
+			fmt.Fprintf(w, "%s\t%s\n", name, f.Reason)
+		}
+		if err := w.Flush(); err != nil {
+			return err
+		}
+	}
+
+	fmt.Fprintln(out)
+	w = tabwriter.NewWriter(out, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "WORD\tCOUNT")
+	for _, wc := range words {
+		fmt.Fprintf(w, "%s\t%d\n", wc.Word, wc.Count)
+	}
+
+	return w.Flush()
+}
+
+// sortedWords sorts the values from the most frequent to the least frequent word.
+func sortedWords(values map[string]int) []entity.WordCount {
+	words := make([]entity.WordCount, 0, len(values))
+	for word, count := range values {
+		words = append(words, entity.WordCount{Word: word, Count: count})
+	}
+
+	sort.Slice(words, func(i, j int) bool {
+		if words[i].Count != words[j].Count {
+			return words[i].Count > words[j].Count
+		}
+		return words[i].Word < words[j].Word
+	})
+
+	return words
+}
@@ -2,14 +2,17 @@ package persistence
 
 import (
 	"context"
-	"errors"
-	"math/rand"
+	"sort"
+	"strings"
+	"sync"
 
 	"github.com/eroatta/freqtable/entity"
 	"github.com/eroatta/freqtable/repository"
 )
 
 type memory struct {
+	sync.RWMutex
+	lastID   int64
 	elements map[int64]entity.FrequencyTable
 }
 
@@ -21,18 +24,202 @@ func NewInMemory() repository.FrequencyTableRepository {
 }
 
 func (m *memory) Save(ctx context.Context, ft entity.FrequencyTable) (int64, error) {
-	r := rand.New(rand.NewSource(99))
-	ft.ID = r.Int63()
+	return m.SaveWithChildren(ctx, ft, nil)
+}
+
+func (m *memory) SaveWithChildren(ctx context.Context, ft entity.FrequencyTable, children []entity.FrequencyTable) (int64, error) {
+	m.Lock()
+	defer m.Unlock()
+
+	names := map[string]bool{ft.Name: true}
+	for _, child := range children {
+		if names[child.Name] {
+			return 0, ErrDuplicated
+		}
+		names[child.Name] = true
+	}
+	for _, existing := range m.elements {
+		if names[existing.Name] {
+			return 0, ErrDuplicated
+		}
+	}
+
+	m.lastID++
+	ft.ID = m.lastID
 	m.elements[ft.ID] = ft
 
+	for _, child := range children {
+		m.lastID++
+		child.ID = m.lastID
+		child.ParentID = ft.ID
+		m.elements[child.ID] = child
+	}
+
 	return ft.ID, nil
 }
 
 func (m *memory) Get(ctx context.Context, id int64) (entity.FrequencyTable, error) {
+	m.RLock()
+	defer m.RUnlock()
+
 	ft, ok := m.elements[id]
 	if !ok {
-		return entity.FrequencyTable{}, errors.New("not found")
+		return entity.FrequencyTable{}, ErrNoResults
 	}
 
 	return ft, nil
 }
+
+func (m *memory) Update(ctx context.Context, ft entity.FrequencyTable) error {
+	m.Lock()
+	defer m.Unlock()
+
+	current, ok := m.elements[ft.ID]
+	if !ok {
+		return ErrNoResults
+	}
+
+	current.Values = ft.Values
+	current.LastUpdated = ft.LastUpdated
+	current.Options = ft.Options
+	current.Revision = ft.Revision
+	current.Report = ft.Report
+	m.elements[ft.ID] = current
+
+	return nil
+}
+
+func (m *memory) FindByName(ctx context.Context, name string) (int64, error) {
+	m.RLock()
+	defer m.RUnlock()
+
+	for id, ft := range m.elements {
+		if ft.Name == name {
+			return id, nil
+		}
+	}
+
+	return 0, ErrNoResults
+}
+
+func (m *memory) List(ctx context.Context, filter repository.FrequencyTableFilter) ([]entity.FrequencyTableSummary, int64, error) {
+	m.RLock()
+	defer m.RUnlock()
+
+	ids := make([]int64, 0, len(m.elements))
+	for id := range m.elements {
+		if id > filter.Cursor {
+			ids = append(ids, id)
+		}
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+
+	summaries := make([]entity.FrequencyTableSummary, 0)
+	for _, id := range ids {
+		ft := m.elements[id]
+		if !matches(ft, filter) {
+			continue
+		}
+
+		if filter.Limit > 0 && len(summaries) == filter.Limit {
+			return summaries, summaries[len(summaries)-1].ID, nil
+		}
+
+		summaries = append(summaries, entity.FrequencyTableSummary{
+			ID:          ft.ID,
+			Name:        ft.Name,
+			DateCreated: ft.DateCreated,
+			LastUpdated: ft.LastUpdated,
+			Vocabulary:  len(ft.Values),
+		})
+	}
+
+	return summaries, 0, nil
+}
+
+func (m *memory) Top(ctx context.Context, id int64, n int, min int) ([]entity.WordCount, int, error) {
+	m.RLock()
+	defer m.RUnlock()
+
+	ft, ok := m.elements[id]
+	if !ok {
+		return nil, 0, ErrNoResults
+	}
+
+	total := 0
+	words := make([]entity.WordCount, 0)
+	for word, count := range ft.Values {
+		total += count
+		if count >= min {
+			words = append(words, entity.WordCount{Word: word, Count: count})
+		}
+	}
+
+	sort.Slice(words, func(i, j int) bool {
+		if words[i].Count != words[j].Count {
+			return words[i].Count > words[j].Count
+		}
+		return words[i].Word < words[j].Word
+	})
+
+	if len(words) > n {
+		words = words[:n]
+	}
+
+	return words, total, nil
+}
+
+func (m *memory) FindWord(ctx context.Context, word string) ([]entity.WordUsage, error) {
+	m.RLock()
+	defer m.RUnlock()
+
+	usages := make([]entity.WordUsage, 0)
+	for _, ft := range m.elements {
+		count, ok := ft.Values[word]
+		if !ok {
+			continue
+		}
+
+		total := 0
+		for _, c := range ft.Values {
+			total += c
+		}
+
+		usages = append(usages, entity.WordUsage{
+			FrequencyTableID:   ft.ID,
+			FrequencyTableName: ft.Name,
+			Count:              count,
+			Total:              total,
+		})
+	}
+
+	sort.Slice(usages, func(i, j int) bool {
+		if usages[i].Count != usages[j].Count {
+			return usages[i].Count > usages[j].Count
+		}
+		return usages[i].FrequencyTableID < usages[j].FrequencyTableID
+	})
+
+	return usages, nil
+}
+
+// matches checks if the given entity.FrequencyTable satisfies the filtering criteria.
+func matches(ft entity.FrequencyTable, filter repository.FrequencyTableFilter) bool {
+	if filter.Name != "" && !strings.Contains(strings.ToLower(ft.Name), strings.ToLower(filter.Name)) {
+		return false
+	}
+
+	if !filter.CreatedAfter.IsZero() && !ft.DateCreated.After(filter.CreatedAfter) {
+		return false
+	}
+
+	if !filter.CreatedBefore.IsZero() && !ft.DateCreated.Before(filter.CreatedBefore) {
+		return false
+	}
+
+	if filter.ParentID > 0 && ft.ParentID != filter.ParentID {
+		return false
+	}
+
+	return len(ft.Values) >= filter.MinVocabulary
+}
@@ -0,0 +1,82 @@
+package persistence
+
+import (
+	"context"
+	"sort"
+	"sync"
+
+	"github.com/eroatta/freqtable/entity"
+	"github.com/eroatta/freqtable/repository"
+)
+
+type memoryJob struct {
+	sync.RWMutex
+	lastID   int64
+	elements map[int64]entity.Job
+}
+
+// NewInMemoryJob creates a new JobRepository on memory.
+func NewInMemoryJob() repository.JobRepository {
+	return &memoryJob{
+		elements: make(map[int64]entity.Job),
+	}
+}
+
+func (m *memoryJob) Get(ctx context.Context, id int64) (entity.Job, error) {
+	m.RLock()
+	defer m.RUnlock()
+
+	job, ok := m.elements[id]
+	if !ok {
+		return entity.Job{}, ErrNoResults
+	}
+
+	return job, nil
+}
+
+func (m *memoryJob) Save(ctx context.Context, job entity.Job) (int64, error) {
+	m.Lock()
+	defer m.Unlock()
+
+	m.lastID++
+	job.ID = m.lastID
+	m.elements[job.ID] = job
+
+	return job.ID, nil
+}
+
+func (m *memoryJob) Update(ctx context.Context, job entity.Job) error {
+	m.Lock()
+	defer m.Unlock()
+
+	current, ok := m.elements[job.ID]
+	if !ok {
+		return ErrNoResults
+	}
+
+	current.Status = job.Status
+	current.FrequencyTableID = job.FrequencyTableID
+	current.Error = job.Error
+	current.LastUpdated = job.LastUpdated
+	m.elements[job.ID] = current
+
+	return nil
+}
+
+func (m *memoryJob) FindByStatus(ctx context.Context, statuses ...entity.JobStatus) ([]entity.Job, error) {
+	m.RLock()
+	defer m.RUnlock()
+
+	jobs := make([]entity.Job, 0)
+	for _, job := range m.elements {
+		for _, status := range statuses {
+			if job.Status == status {
+				jobs = append(jobs, job)
+				break
+			}
+		}
+	}
+	sort.Slice(jobs, func(i, j int) bool { return jobs[i].ID < jobs[j].ID })
+
+	return jobs, nil
+}
@@ -0,0 +1,64 @@
+package persistence_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/eroatta/freqtable/adapter/persistence"
+	"github.com/eroatta/freqtable/entity"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewInMemoryJob_ShouldReturnNewJobRepository(t *testing.T) {
+	jr := persistence.NewInMemoryJob()
+
+	assert.NotNil(t, jr)
+}
+
+func TestGet_OnMemoryJobWhenNonExistingJob_ShouldReturnError(t *testing.T) {
+	jr := persistence.NewInMemoryJob()
+	job, err := jr.Get(context.TODO(), 1)
+
+	assert.Empty(t, job)
+	assert.Equal(t, persistence.ErrNoResults, err)
+}
+
+func TestUpdate_OnMemoryJobWhenNonExistingJob_ShouldReturnError(t *testing.T) {
+	jr := persistence.NewInMemoryJob()
+	err := jr.Update(context.TODO(), entity.Job{ID: 1, Status: entity.JobRunning})
+
+	assert.Equal(t, persistence.ErrNoResults, err)
+}
+
+func TestUpdate_OnMemoryJob_ShouldUpdateStatusAndResults(t *testing.T) {
+	now := time.Now()
+	jr := persistence.NewInMemoryJob()
+	id, err := jr.Save(context.TODO(), entity.Job{URL: "https://github.com/eroatta/freqtable", Status: entity.JobQueued, DateCreated: now})
+	assert.NoError(t, err)
+
+	err = jr.Update(context.TODO(), entity.Job{ID: id, Status: entity.JobSucceeded, FrequencyTableID: 1234, LastUpdated: now})
+	assert.NoError(t, err)
+
+	job, err := jr.Get(context.TODO(), id)
+	assert.NoError(t, err)
+	assert.Equal(t, "https://github.com/eroatta/freqtable", job.URL)
+	assert.Equal(t, entity.JobSucceeded, job.Status)
+	assert.Equal(t, int64(1234), job.FrequencyTableID)
+	assert.Equal(t, now, job.DateCreated)
+	assert.Equal(t, now, job.LastUpdated)
+}
+
+func TestFindByStatus_OnMemoryJob_ShouldReturnMatchingJobsSortedByID(t *testing.T) {
+	jr := persistence.NewInMemoryJob()
+	jr.Save(context.TODO(), entity.Job{URL: "https://github.com/eroatta/freqtable", Status: entity.JobRunning})
+	jr.Save(context.TODO(), entity.Job{URL: "https://github.com/eroatta/token", Status: entity.JobSucceeded})
+	jr.Save(context.TODO(), entity.Job{URL: "https://github.com/eroatta/src-reader", Status: entity.JobQueued})
+
+	jobs, err := jr.FindByStatus(context.TODO(), entity.JobQueued, entity.JobRunning)
+
+	assert.NoError(t, err)
+	assert.Equal(t, 2, len(jobs))
+	assert.Equal(t, "https://github.com/eroatta/freqtable", jobs[0].URL)
+	assert.Equal(t, "https://github.com/eroatta/src-reader", jobs[1].URL)
+}
@@ -0,0 +1,46 @@
+package persistence
+
+import (
+	"context"
+	"sync"
+
+	"github.com/eroatta/freqtable/entity"
+	"github.com/eroatta/freqtable/repository"
+)
+
+type memorySeries struct {
+	sync.RWMutex
+	lastID   int64
+	elements map[int64]entity.Series
+}
+
+// NewInMemorySeries creates a new SeriesRepository on memory.
+func NewInMemorySeries() repository.SeriesRepository {
+	return &memorySeries{
+		elements: make(map[int64]entity.Series),
+	}
+}
+
+func (m *memorySeries) Get(ctx context.Context, id int64) (entity.Series, error) {
+	m.RLock()
+	defer m.RUnlock()
+
+	series, ok := m.elements[id]
+	if !ok {
+		return entity.Series{}, ErrNoResults
+	}
+
+	return series, nil
+}
+
+func (m *memorySeries) Save(ctx context.Context, series entity.Series) (int64, error) {
+	m.Lock()
+	defer m.Unlock()
+
+	m.lastID++
+	series.ID = m.lastID
+	series.Entries = append([]entity.SeriesEntry(nil), series.Entries...)
+	m.elements[series.ID] = series
+
+	return series.ID, nil
+}
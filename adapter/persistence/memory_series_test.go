@@ -0,0 +1,49 @@
+package persistence_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/eroatta/freqtable/adapter/persistence"
+	"github.com/eroatta/freqtable/entity"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewInMemorySeries_ShouldReturnNewSeriesRepository(t *testing.T) {
+	sr := persistence.NewInMemorySeries()
+
+	assert.NotNil(t, sr)
+}
+
+func TestGet_OnMemorySeriesWhenNonExistingSeries_ShouldReturnError(t *testing.T) {
+	sr := persistence.NewInMemorySeries()
+	series, err := sr.Get(context.TODO(), 1)
+
+	assert.Empty(t, series)
+	assert.Equal(t, persistence.ErrNoResults, err)
+}
+
+func TestSave_OnMemorySeries_ShouldStoreEntriesInOrder(t *testing.T) {
+	now := time.Now()
+	sr := persistence.NewInMemorySeries()
+	entries := []entity.SeriesEntry{
+		{FrequencyTableID: 2, Ref: "v0.1.0", Revision: entity.Revision{Hash: "a1", Date: now}},
+		{FrequencyTableID: 1, Ref: "v0.2.0", Revision: entity.Revision{Hash: "b2", Date: now}},
+	}
+	id, err := sr.Save(context.TODO(), entity.Series{
+		Name:        "https://github.com/eroatta/freqtable",
+		Selection:   entity.RevisionSelection{Tags: true},
+		DateCreated: now,
+		Entries:     entries,
+	})
+	assert.NoError(t, err)
+	other, _ := sr.Save(context.TODO(), entity.Series{Name: "https://github.com/eroatta/token"})
+	assert.NotEqual(t, id, other)
+
+	series, err := sr.Get(context.TODO(), id)
+	assert.NoError(t, err)
+	assert.Equal(t, id, series.ID)
+	assert.Equal(t, entity.RevisionSelection{Tags: true}, series.Selection)
+	assert.Equal(t, entries, series.Entries)
+}
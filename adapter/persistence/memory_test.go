@@ -0,0 +1,231 @@
+package persistence_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/eroatta/freqtable/adapter/persistence"
+	"github.com/eroatta/freqtable/entity"
+	"github.com/eroatta/freqtable/repository"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewInMemory_ShouldReturnNewFrequencyTableRepository(t *testing.T) {
+	ftr := persistence.NewInMemory()
+
+	assert.NotNil(t, ftr)
+}
+
+func TestGet_OnMemoryWhenNonExistingFrequencyTable_ShouldReturnError(t *testing.T) {
+	ftr := persistence.NewInMemory()
+	ft, err := ftr.Get(context.TODO(), 1234567890)
+
+	assert.Empty(t, ft)
+	assert.Equal(t, persistence.ErrNoResults, err)
+}
+
+func TestSave_OnMemory_ShouldAssignDifferentIDs(t *testing.T) {
+	ftr := persistence.NewInMemory()
+	first, _ := ftr.Save(context.TODO(), entity.FrequencyTable{Name: "first", Values: map[string]int{"cars": 1}})
+	second, _ := ftr.Save(context.TODO(), entity.FrequencyTable{Name: "second", Values: map[string]int{"house": 3}})
+
+	assert.NotEqual(t, first, second)
+
+	ft, err := ftr.Get(context.TODO(), first)
+	assert.NoError(t, err)
+	assert.Equal(t, "first", ft.Name)
+	assert.Equal(t, map[string]int{"cars": 1}, ft.Values)
+}
+
+func TestSave_OnMemoryWhenDuplicatedName_ShouldReturnError(t *testing.T) {
+	ftr := persistence.NewInMemory()
+	ftr.Save(context.TODO(), entity.FrequencyTable{Name: "testname", Values: map[string]int{"cars": 1}})
+	id, err := ftr.Save(context.TODO(), entity.FrequencyTable{Name: "testname", Values: map[string]int{"house": 3}})
+
+	assert.Equal(t, int64(0), id)
+	assert.Equal(t, persistence.ErrDuplicated, err)
+}
+
+func TestSaveWithChildren_OnMemory_ShouldSetParentID(t *testing.T) {
+	ftr := persistence.NewInMemory()
+	id, err := ftr.SaveWithChildren(context.TODO(), entity.FrequencyTable{Name: "testname", Values: map[string]int{"cars": 1}},
+		[]entity.FrequencyTable{{Name: "testname#api", Path: "api", Values: map[string]int{"cars": 1}}})
+	assert.NoError(t, err)
+
+	summaries, _, err := ftr.List(context.TODO(), repository.FrequencyTableFilter{ParentID: id})
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(summaries))
+	assert.Equal(t, "testname#api", summaries[0].Name)
+}
+
+func TestSaveWithChildren_OnMemoryWhenDuplicatedChildName_ShouldSaveNothing(t *testing.T) {
+	ftr := persistence.NewInMemory()
+	ftr.Save(context.TODO(), entity.FrequencyTable{Name: "testname#api", Values: map[string]int{"cars": 1}})
+	id, err := ftr.SaveWithChildren(context.TODO(), entity.FrequencyTable{Name: "testname", Values: map[string]int{"cars": 1}},
+		[]entity.FrequencyTable{{Name: "testname#api", Path: "api", Values: map[string]int{"cars": 1}}})
+
+	assert.Equal(t, int64(0), id)
+	assert.Equal(t, persistence.ErrDuplicated, err)
+	summaries, _, _ := ftr.List(context.TODO(), repository.FrequencyTableFilter{Name: "testname"})
+	assert.Equal(t, 1, len(summaries))
+}
+
+func TestUpdate_OnMemoryWhenNonExistingFrequencyTable_ShouldReturnError(t *testing.T) {
+	ftr := persistence.NewInMemory()
+	err := ftr.Update(context.TODO(), entity.FrequencyTable{ID: 1234567890, Values: map[string]int{}})
+
+	assert.Equal(t, persistence.ErrNoResults, err)
+}
+
+func TestUpdate_OnMemory_ShouldReplaceValuesAndLastUpdated(t *testing.T) {
+	now := time.Now()
+	ftr := persistence.NewInMemory()
+	id, _ := ftr.Save(context.TODO(), entity.FrequencyTable{Name: "testname", DateCreated: now.Add(-time.Hour),
+		Values: map[string]int{"cars": 1}})
+
+	err := ftr.Update(context.TODO(), entity.FrequencyTable{ID: id, LastUpdated: now, Values: map[string]int{"house": 3}})
+	assert.NoError(t, err)
+
+	ft, _ := ftr.Get(context.TODO(), id)
+	assert.Equal(t, "testname", ft.Name)
+	assert.Equal(t, now.Add(-time.Hour), ft.DateCreated)
+	assert.Equal(t, now, ft.LastUpdated)
+	assert.Equal(t, map[string]int{"house": 3}, ft.Values)
+}
+
+func TestFindByName_OnMemory_ShouldMatchExactName(t *testing.T) {
+	ftr := persistence.NewInMemory()
+	ftr.Save(context.TODO(), entity.FrequencyTable{Name: "https://github.com/eroatta/freqtable@v0.1.0-rc1"})
+	expected, _ := ftr.Save(context.TODO(), entity.FrequencyTable{Name: "https://github.com/eroatta/freqtable@v0.1.0"})
+
+	id, err := ftr.FindByName(context.TODO(), "https://github.com/eroatta/freqtable@v0.1.0")
+
+	assert.NoError(t, err)
+	assert.Equal(t, expected, id)
+
+	id, err = ftr.FindByName(context.TODO(), "https://github.com/eroatta/freqtable")
+
+	assert.Equal(t, int64(0), id)
+	assert.Equal(t, persistence.ErrNoResults, err)
+}
+
+func TestList_OnMemory_ShouldApplyFiltersAndPagination(t *testing.T) {
+	now := time.Now()
+	ftr := persistence.NewInMemory()
+	ftr.Save(context.TODO(), entity.FrequencyTable{Name: "https://github.com/eroatta/freqtable", DateCreated: now,
+		Values: map[string]int{"frequency": 1, "table": 2}})
+	ftr.Save(context.TODO(), entity.FrequencyTable{Name: "https://github.com/src-d/go-git", DateCreated: now,
+		Values: map[string]int{"git": 1, "clone": 2, "repository": 3}})
+	ftr.Save(context.TODO(), entity.FrequencyTable{Name: "https://github.com/eroatta/token", DateCreated: now.Add(-time.Hour),
+		Values: map[string]int{"token": 1, "split": 2, "word": 3}})
+	ftr.Save(context.TODO(), entity.FrequencyTable{Name: "https://github.com/eroatta/src-reader", DateCreated: now,
+		Values: map[string]int{"source": 1, "reader": 2, "file": 3}})
+
+	var tests = []struct {
+		name        string
+		filter      repository.FrequencyTableFilter
+		expectedIDs []int64
+		next        int64
+	}{
+		{"NoFilter", repository.FrequencyTableFilter{}, []int64{1, 2, 3, 4}, 0},
+		{"ByName", repository.FrequencyTableFilter{Name: "EROATTA"}, []int64{1, 3, 4}, 0},
+		{"ByCreatedAfter", repository.FrequencyTableFilter{CreatedAfter: now.Add(-time.Minute)}, []int64{1, 2, 4}, 0},
+		{"ByCreatedBefore", repository.FrequencyTableFilter{CreatedBefore: now.Add(-time.Minute)}, []int64{3}, 0},
+		{"ByMinVocabulary", repository.FrequencyTableFilter{MinVocabulary: 3}, []int64{2, 3, 4}, 0},
+		{"FirstPage", repository.FrequencyTableFilter{Name: "eroatta", Limit: 2}, []int64{1, 3}, 3},
+		{"LastPage", repository.FrequencyTableFilter{Name: "eroatta", Cursor: 3, Limit: 2}, []int64{4}, 0},
+	}
+
+	for _, fixture := range tests {
+		t.Run(fixture.name, func(t *testing.T) {
+			summaries, next, err := ftr.List(context.TODO(), fixture.filter)
+
+			assert.NoError(t, err)
+			assert.Equal(t, fixture.next, next)
+			ids := make([]int64, 0)
+			for _, summary := range summaries {
+				ids = append(ids, summary.ID)
+			}
+			assert.Equal(t, fixture.expectedIDs, ids)
+		})
+	}
+}
+
+func TestList_OnMemoryByParentID_ShouldReturnChildFrequencyTables(t *testing.T) {
+	ftr := persistence.NewInMemory()
+	ftr.Save(context.TODO(), entity.FrequencyTable{Name: "https://github.com/eroatta/freqtable",
+		Values: map[string]int{"frequency": 1, "table": 2}})
+	ftr.Save(context.TODO(), entity.FrequencyTable{Name: "https://github.com/eroatta/freqtable#adapter/rest", ParentID: 1,
+		Path: "adapter/rest", Values: map[string]int{"table": 1}})
+	ftr.Save(context.TODO(), entity.FrequencyTable{Name: "https://github.com/eroatta/token",
+		Values: map[string]int{"token": 1}})
+	ftr.Save(context.TODO(), entity.FrequencyTable{Name: "https://github.com/eroatta/freqtable#entity", ParentID: 1,
+		Path: "entity", Values: map[string]int{"frequency": 1, "table": 1}})
+
+	summaries, next, err := ftr.List(context.TODO(), repository.FrequencyTableFilter{ParentID: 1})
+
+	assert.NoError(t, err)
+	assert.Equal(t, int64(0), next)
+	assert.Equal(t, 2, len(summaries))
+	assert.Equal(t, int64(2), summaries[0].ID)
+	assert.Equal(t, int64(4), summaries[1].ID)
+
+	child, _ := ftr.Get(context.TODO(), 4)
+	assert.Equal(t, int64(1), child.ParentID)
+	assert.Equal(t, "entity", child.Path)
+}
+
+func TestTop_OnMemoryWhenNonExistingFrequencyTable_ShouldReturnError(t *testing.T) {
+	ftr := persistence.NewInMemory()
+	words, total, err := ftr.Top(context.TODO(), 1234567890, 10, 0)
+
+	assert.Nil(t, words)
+	assert.Equal(t, 0, total)
+	assert.Equal(t, persistence.ErrNoResults, err)
+}
+
+func TestTop_OnMemory_ShouldReturnMostFrequentWords(t *testing.T) {
+	ftr := persistence.NewInMemory()
+	id, _ := ftr.Save(context.TODO(), entity.FrequencyTable{Name: "testname", DateCreated: time.Now(),
+		Values: map[string]int{"ctx": 8, "cfg": 5, "err": 8, "buf": 2, "tmp": 1}})
+
+	words, total, err := ftr.Top(context.TODO(), id, 3, 2)
+
+	assert.NoError(t, err)
+	assert.Equal(t, 24, total)
+	assert.Equal(t, []entity.WordCount{
+		{Word: "ctx", Count: 8},
+		{Word: "err", Count: 8},
+		{Word: "cfg", Count: 5},
+	}, words)
+}
+
+func TestFindWord_OnMemory_ShouldReturnUsagesOnEveryFrequencyTable(t *testing.T) {
+	ftr := persistence.NewInMemory()
+	ftr.Save(context.TODO(), entity.FrequencyTable{Name: "https://github.com/eroatta/freqtable", DateCreated: time.Now(),
+		Values: map[string]int{"ctx": 2, "table": 8}})
+	ftr.Save(context.TODO(), entity.FrequencyTable{Name: "https://github.com/eroatta/token", DateCreated: time.Now(),
+		Values: map[string]int{"token": 5}})
+	ftr.Save(context.TODO(), entity.FrequencyTable{Name: "https://github.com/eroatta/src-reader", DateCreated: time.Now(),
+		Values: map[string]int{"ctx": 6, "reader": 2}})
+
+	usages, err := ftr.FindWord(context.TODO(), "ctx")
+
+	assert.NoError(t, err)
+	assert.Equal(t, []entity.WordUsage{
+		{FrequencyTableID: 3, FrequencyTableName: "https://github.com/eroatta/src-reader", Count: 6, Total: 8},
+		{FrequencyTableID: 1, FrequencyTableName: "https://github.com/eroatta/freqtable", Count: 2, Total: 10},
+	}, usages)
+}
+
+func TestFindWord_OnMemoryWhenUnknownWord_ShouldReturnEmptyResults(t *testing.T) {
+	ftr := persistence.NewInMemory()
+	ftr.Save(context.TODO(), entity.FrequencyTable{Name: "testname", DateCreated: time.Now(),
+		Values: map[string]int{"token": 5}})
+
+	usages, err := ftr.FindWord(context.TODO(), "ctx")
+
+	assert.NoError(t, err)
+	assert.Empty(t, usages)
+}
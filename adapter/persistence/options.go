@@ -0,0 +1,101 @@
+package persistence
+
+import (
+	"database/sql"
+	"encoding/json"
+
+	"github.com/eroatta/freqtable/entity"
+)
+
+// optionsRecord is the JSON representation of an entity.ExtractionOptions stored on the database.
+type optionsRecord struct {
+	Ref           string   `json:"ref,omitempty"`
+	Include       []string `json:"include,omitempty"`
+	Exclude       []string `json:"exclude,omitempty"`
+	SkipGenerated *bool    `json:"skip_generated,omitempty"`
+	TestFiles     string   `json:"test_files,omitempty"`
+	Granularity   string   `json:"granularity,omitempty"`
+}
+
+// selectionRecord is the JSON representation of an entity.RevisionSelection stored on the database.
+type selectionRecord struct {
+	Tags  bool   `json:"tags,omitempty"`
+	Every int    `json:"every,omitempty"`
+	From  string `json:"from,omitempty"`
+	To    string `json:"to,omitempty"`
+}
+
+// marshalOptions converts the given extraction options into their JSON representation. Missing
+// options are converted into a NULL value.
+func marshalOptions(options *entity.ExtractionOptions) (sql.NullString, error) {
+	if options == nil {
+		return sql.NullString{}, nil
+	}
+
+	bytes, err := json.Marshal(optionsRecord{
+		Ref:           options.Ref,
+		Include:       options.Include,
+		Exclude:       options.Exclude,
+		SkipGenerated: options.SkipGenerated,
+		TestFiles:     string(options.TestFiles),
+		Granularity:   string(options.Granularity),
+	})
+	if err != nil {
+		return sql.NullString{}, err
+	}
+
+	return sql.NullString{String: string(bytes), Valid: true}, nil
+}
+
+// unmarshalOptions converts the given JSON representation into extraction options. A NULL value
+// is converted into missing options.
+func unmarshalOptions(value sql.NullString) (*entity.ExtractionOptions, error) {
+	if !value.Valid {
+		return nil, nil
+	}
+
+	var record optionsRecord
+	if err := json.Unmarshal([]byte(value.String), &record); err != nil {
+		return nil, err
+	}
+
+	return &entity.ExtractionOptions{
+		Ref:           record.Ref,
+		Include:       record.Include,
+		Exclude:       record.Exclude,
+		SkipGenerated: record.SkipGenerated,
+		TestFiles:     entity.TestFilesMode(record.TestFiles),
+		Granularity:   entity.Granularity(record.Granularity),
+	}, nil
+}
+
+// marshalSelection converts the given revision selection into its JSON representation. A missing
+// selection is converted into a NULL value.
+func marshalSelection(selection *entity.RevisionSelection) (sql.NullString, error) {
+	if selection == nil {
+		return sql.NullString{}, nil
+	}
+
+	bytes, err := json.Marshal(selectionRecord(*selection))
+	if err != nil {
+		return sql.NullString{}, err
+	}
+
+	return sql.NullString{String: string(bytes), Valid: true}, nil
+}
+
+// unmarshalSelection converts the given JSON representation into a revision selection. A NULL
+// value is converted into a missing selection.
+func unmarshalSelection(value sql.NullString) (*entity.RevisionSelection, error) {
+	if !value.Valid {
+		return nil, nil
+	}
+
+	var record selectionRecord
+	if err := json.Unmarshal([]byte(value.String), &record); err != nil {
+		return nil, err
+	}
+
+	selection := entity.RevisionSelection(record)
+	return &selection, nil
+}
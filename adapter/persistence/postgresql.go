@@ -4,24 +4,34 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"strings"
 
 	"database/sql"
 
 	"github.com/eroatta/freqtable/entity"
 	"github.com/eroatta/freqtable/repository"
+	"github.com/lib/pq"
 
 	log "github.com/sirupsen/logrus"
 )
 
 var (
 	// ErrNoResults indicates that the given query has no results.
-	ErrNoResults = errors.New("No results for the given query")
+	ErrNoResults = repository.ErrNoResults
+	// ErrDuplicated indicates that the element violates an unique constraint.
+	ErrDuplicated = repository.ErrDuplicated
 	// ErrUnexpected indicatates that the current operation couldn't be completed because of an internal issue.
 	ErrUnexpected = errors.New("Unexpected error performing the current operation")
 	// ErrMissingFields indicates that one or more required fields are missing.
 	ErrMissingFields = errors.New("Missing mandatory fields")
 )
 
+// uniqueViolation is the PostgreSQL error code for unique constraints violations.
+const uniqueViolation = "23505"
+
+// likeEscaper escapes the wildcard characters of a LIKE pattern.
+var likeEscaper = strings.NewReplacer("\\", "\\\\", "%", "\\%", "_", "\\_")
+
 type postgresql struct {
 	db *sql.DB
 }
@@ -54,9 +64,18 @@ func NewConnection(host string, port string, user string, password string, dbnam
 }
 
 func (r *postgresql) Save(ctx context.Context, ft entity.FrequencyTable) (int64, error) {
+	return r.SaveWithChildren(ctx, ft, nil)
+}
+
+func (r *postgresql) SaveWithChildren(ctx context.Context, ft entity.FrequencyTable, children []entity.FrequencyTable) (int64, error) {
 	if ft.Values == nil {
 		return 0, ErrMissingFields
 	}
+	for _, child := range children {
+		if child.Values == nil {
+			return 0, ErrMissingFields
+		}
+	}
 
 	tx, err := r.db.Begin()
 	if err != nil {
@@ -64,17 +83,67 @@ func (r *postgresql) Save(ctx context.Context, ft entity.FrequencyTable) (int64,
 		return 0, ErrUnexpected
 	}
 
+	id, err := insert(ctx, tx, ft)
+	if err != nil {
+		return 0, err
+	}
+
+	for _, child := range children {
+		child.ParentID = id
+		if _, err := insert(ctx, tx, child); err != nil {
+			return 0, err
+		}
+	}
+
+	if err = tx.Commit(); err != nil {
+		log.WithField("error", err).Error("error committing a transaction")
+		defer tx.Rollback()
+		return 0, ErrUnexpected
+	}
+
+	return id, nil
+}
+
+// insert inserts the frequency_table record and the frequency_table_item records of the given
+// frequency table on the given transaction, which is rolled back on error. It returns the ID of the
+// new record.
+func insert(ctx context.Context, tx *sql.Tx, ft entity.FrequencyTable) (int64, error) {
+	options, err := marshalOptions(ft.Options)
+	if err != nil {
+		log.WithError(err).Error("error marshalling the extraction options")
+		defer tx.Rollback()
+		return 0, ErrUnexpected
+	}
+
+	report, err := marshalReport(ft.Report)
+	if err != nil {
+		log.WithError(err).Error("error marshalling the extraction report")
+		defer tx.Rollback()
+		return 0, ErrUnexpected
+	}
+
 	ftStmt, err := tx.PrepareContext(ctx,
-		"INSERT INTO frequency_table(name, date_created) VALUES($1, $2) RETURNING id")
+		"INSERT INTO frequency_table(name, date_created, revision_hash, revision_date, options, report, parent_id, path, source) "+
+			"VALUES($1, $2, $3, $4, $5, $6, $7, $8, $9) RETURNING id")
 	if err != nil {
 		log.WithField("error", err).Error("error preparing statement for frequency_table insertion")
+		defer tx.Rollback()
 		return 0, ErrUnexpected
 	}
 
 	var id int64
-	err = ftStmt.QueryRowContext(ctx, ft.Name, ft.DateCreated).Scan(&id)
+	revisionHash, revisionDate := revisionColumns(ft.Revision)
+	parentID, path := parentColumns(ft)
+	err = ftStmt.QueryRowContext(ctx, ft.Name, ft.DateCreated, revisionHash, revisionDate, options, report, parentID, path,
+		sourceColumn(ft.Source)).Scan(&id)
+	if pqErr, ok := err.(*pq.Error); ok && pqErr.Code == uniqueViolation {
+		log.WithField("name", ft.Name).Debug("frequency_table record already exists")
+		defer tx.Rollback()
+		return 0, ErrDuplicated
+	}
 	if err != nil {
 		log.WithField("error", err).Error("error inserting new frequency_table record")
+		defer tx.Rollback()
 		return 0, ErrUnexpected
 	}
 
@@ -82,6 +151,7 @@ func (r *postgresql) Save(ctx context.Context, ft entity.FrequencyTable) (int64,
 		"INSERT INTO frequency_table_item(frequency_table_id, word, times) VALUES ($1, $2, $3)")
 	if err != nil {
 		log.WithField("error", err).Error("error preparing statement for frequency_table_item insertion")
+		defer tx.Rollback()
 		return 0, ErrUnexpected
 	}
 
@@ -93,17 +163,86 @@ func (r *postgresql) Save(ctx context.Context, ft entity.FrequencyTable) (int64,
 		}
 	}
 
+	return id, nil
+}
+
+func (r *postgresql) Update(ctx context.Context, ft entity.FrequencyTable) error {
+	if ft.Values == nil {
+		return ErrMissingFields
+	}
+
+	options, err := marshalOptions(ft.Options)
+	if err != nil {
+		log.WithError(err).Error("error marshalling the extraction options")
+		return ErrUnexpected
+	}
+
+	report, err := marshalReport(ft.Report)
+	if err != nil {
+		log.WithError(err).Error("error marshalling the extraction report")
+		return ErrUnexpected
+	}
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		log.WithError(err).Error("error beginning a transaction")
+		return ErrUnexpected
+	}
+
+	revisionHash, revisionDate := revisionColumns(ft.Revision)
+	result, err := tx.ExecContext(ctx,
+		"UPDATE frequency_table SET last_updated=$1, revision_hash=$2, revision_date=$3, options=$4, report=$5 WHERE id=$6",
+		ft.LastUpdated, revisionHash, revisionDate, options, report, ft.ID)
+	if err != nil {
+		log.WithError(err).Error("error updating frequency_table record")
+		defer tx.Rollback()
+		return ErrUnexpected
+	}
+
+	if affected, err := result.RowsAffected(); err != nil || affected == 0 {
+		defer tx.Rollback()
+		if err != nil {
+			log.WithError(err).Error("error checking the updated frequency_table records")
+			return ErrUnexpected
+		}
+		return ErrNoResults
+	}
+
+	if _, err = tx.ExecContext(ctx,
+		"DELETE FROM frequency_table_item WHERE frequency_table_id=$1", ft.ID); err != nil {
+		log.WithError(err).Error("error deleting frequency_table_item records")
+		defer tx.Rollback()
+		return ErrUnexpected
+	}
+
+	stmt, err := tx.PrepareContext(ctx,
+		"INSERT INTO frequency_table_item(frequency_table_id, word, times) VALUES ($1, $2, $3)")
+	if err != nil {
+		log.WithError(err).Error("error preparing statement for frequency_table_item insertion")
+		defer tx.Rollback()
+		return ErrUnexpected
+	}
+
+	for word, times := range ft.Values {
+		if _, err = stmt.ExecContext(ctx, ft.ID, word, times); err != nil {
+			log.WithError(err).Error("error inserting new frequency_table_item record")
+			defer tx.Rollback()
+			return ErrUnexpected
+		}
+	}
+
 	if err = tx.Commit(); err != nil {
-		log.WithField("error", err).Error("error committing a transaction")
+		log.WithError(err).Error("error committing a transaction")
 		defer tx.Rollback()
-		return 0, ErrUnexpected
+		return ErrUnexpected
 	}
 
-	return id, nil
+	return nil
 }
 
 func (r *postgresql) Get(ctx context.Context, ID int64) (entity.FrequencyTable, error) {
-	query := "SELECT id, \"name\", date_created, last_updated FROM frequency_table WHERE id=$1"
+	query := "SELECT id, \"name\", date_created, last_updated, revision_hash, revision_date, options, report, parent_id, path, source " +
+		"FROM frequency_table WHERE id=$1"
 	ftGetStmt, err := r.db.PrepareContext(ctx, query)
 	if err != nil {
 		log.WithError(err).Error("error preparing frequency_table select statement")
@@ -111,11 +250,21 @@ func (r *postgresql) Get(ctx context.Context, ID int64) (entity.FrequencyTable,
 	}
 
 	var frequencyTable entity.FrequencyTable
+	var lastUpdated, revisionDate sql.NullTime
+	var revisionHash, options, report, path, source sql.NullString
+	var parentID sql.NullInt64
 	row := ftGetStmt.QueryRowContext(ctx, ID)
 	switch err := row.Scan(&frequencyTable.ID,
 		&frequencyTable.Name,
 		&frequencyTable.DateCreated,
-		&frequencyTable.LastUpdated); err {
+		&lastUpdated,
+		&revisionHash,
+		&revisionDate,
+		&options,
+		&report,
+		&parentID,
+		&path,
+		&source); err {
 	case sql.ErrNoRows:
 		return entity.FrequencyTable{}, ErrNoResults
 	case nil:
@@ -124,6 +273,25 @@ func (r *postgresql) Get(ctx context.Context, ID int64) (entity.FrequencyTable,
 		log.WithError(err).Error("error executing select on frequency_table")
 		return entity.FrequencyTable{}, ErrUnexpected
 	}
+	frequencyTable.LastUpdated = lastUpdated.Time
+	frequencyTable.ParentID = parentID.Int64
+	frequencyTable.Path = path.String
+	frequencyTable.Source = source.String
+	if revisionHash.Valid {
+		frequencyTable.Revision = &entity.Revision{Hash: revisionHash.String, Date: revisionDate.Time}
+	}
+
+	frequencyTable.Options, err = unmarshalOptions(options)
+	if err != nil {
+		log.WithError(err).Error("error unmarshalling the extraction options")
+		return entity.FrequencyTable{}, ErrUnexpected
+	}
+
+	frequencyTable.Report, err = unmarshalReport(report)
+	if err != nil {
+		log.WithError(err).Error("error unmarshalling the extraction report")
+		return entity.FrequencyTable{}, ErrUnexpected
+	}
 
 	itemsQuery := "SELECT word, times FROM frequency_table_item WHERE frequency_table_id=$1"
 	itemsSelectStmt, err := r.db.PrepareContext(ctx, itemsQuery)
@@ -135,6 +303,7 @@ func (r *postgresql) Get(ctx context.Context, ID int64) (entity.FrequencyTable,
 	rows, err := itemsSelectStmt.QueryContext(ctx, frequencyTable.ID)
 	if err != nil {
 		log.WithError(err).Error("error executing select on frequency_table_item")
+		return entity.FrequencyTable{}, ErrUnexpected
 	}
 	defer rows.Close()
 
@@ -151,3 +320,193 @@ func (r *postgresql) Get(ctx context.Context, ID int64) (entity.FrequencyTable,
 
 	return frequencyTable, nil
 }
+
+func (r *postgresql) FindByName(ctx context.Context, name string) (int64, error) {
+	query := "SELECT id FROM frequency_table WHERE \"name\"=$1"
+	ftFindStmt, err := r.db.PrepareContext(ctx, query)
+	if err != nil {
+		log.WithError(err).Error("error preparing frequency_table select statement")
+		return 0, ErrUnexpected
+	}
+
+	var id int64
+	switch err := ftFindStmt.QueryRowContext(ctx, name).Scan(&id); err {
+	case sql.ErrNoRows:
+		return 0, ErrNoResults
+	case nil:
+		return id, nil
+	default:
+		log.WithError(err).Error("error executing select on frequency_table")
+		return 0, ErrUnexpected
+	}
+}
+
+func (r *postgresql) List(ctx context.Context, filter repository.FrequencyTableFilter) ([]entity.FrequencyTableSummary, int64, error) {
+	args := []interface{}{filter.Cursor}
+	conditions := []string{"ft.id > $1"}
+	if filter.Name != "" {
+		args = append(args, "%"+likeEscaper.Replace(filter.Name)+"%")
+		conditions = append(conditions, fmt.Sprintf("ft.\"name\" ILIKE $%d", len(args)))
+	}
+
+	if !filter.CreatedAfter.IsZero() {
+		args = append(args, filter.CreatedAfter)
+		conditions = append(conditions, fmt.Sprintf("ft.date_created > $%d", len(args)))
+	}
+
+	if !filter.CreatedBefore.IsZero() {
+		args = append(args, filter.CreatedBefore)
+		conditions = append(conditions, fmt.Sprintf("ft.date_created < $%d", len(args)))
+	}
+
+	if filter.ParentID > 0 {
+		args = append(args, filter.ParentID)
+		conditions = append(conditions, fmt.Sprintf("ft.parent_id = $%d", len(args)))
+	}
+
+	query := "SELECT ft.id, ft.\"name\", ft.date_created, ft.last_updated, COUNT(fti.word) " +
+		"FROM frequency_table ft LEFT JOIN frequency_table_item fti ON fti.frequency_table_id = ft.id " +
+		"WHERE " + strings.Join(conditions, " AND ") + " GROUP BY ft.id"
+
+	if filter.MinVocabulary > 0 {
+		args = append(args, filter.MinVocabulary)
+		query += fmt.Sprintf(" HAVING COUNT(fti.word) >= $%d", len(args))
+	}
+
+	query += " ORDER BY ft.id"
+	if filter.Limit > 0 {
+		// an extra element is requested to find out if there is a next page
+		args = append(args, filter.Limit+1)
+		query += fmt.Sprintf(" LIMIT $%d", len(args))
+	}
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		log.WithError(err).Error("error executing select on frequency_table")
+		return nil, 0, ErrUnexpected
+	}
+	defer rows.Close()
+
+	summaries := make([]entity.FrequencyTableSummary, 0)
+	for rows.Next() {
+		var summary entity.FrequencyTableSummary
+		var lastUpdated sql.NullTime
+		if err := rows.Scan(&summary.ID, &summary.Name, &summary.DateCreated, &lastUpdated, &summary.Vocabulary); err != nil {
+			log.WithError(err).Error("error scanning row results")
+			return nil, 0, ErrUnexpected
+		}
+		summary.LastUpdated = lastUpdated.Time
+		summaries = append(summaries, summary)
+	}
+
+	if err := rows.Err(); err != nil {
+		log.WithError(err).Error("error iterating row results")
+		return nil, 0, ErrUnexpected
+	}
+
+	var next int64
+	if filter.Limit > 0 && len(summaries) > filter.Limit {
+		summaries = summaries[:filter.Limit]
+		next = summaries[filter.Limit-1].ID
+	}
+
+	return summaries, next, nil
+}
+
+func (r *postgresql) Top(ctx context.Context, ID int64, n int, min int) ([]entity.WordCount, int, error) {
+	var total int
+	row := r.db.QueryRowContext(ctx,
+		"SELECT COALESCE(SUM(fti.times), 0) FROM frequency_table ft "+
+			"LEFT JOIN frequency_table_item fti ON fti.frequency_table_id = ft.id WHERE ft.id=$1 GROUP BY ft.id", ID)
+	switch err := row.Scan(&total); err {
+	case sql.ErrNoRows:
+		return nil, 0, ErrNoResults
+	case nil:
+		// continue
+	default:
+		log.WithError(err).Error("error executing select on frequency_table")
+		return nil, 0, ErrUnexpected
+	}
+
+	rows, err := r.db.QueryContext(ctx,
+		"SELECT word, times FROM frequency_table_item WHERE frequency_table_id=$1 AND times >= $2 "+
+			"ORDER BY times DESC, word LIMIT $3", ID, min, n)
+	if err != nil {
+		log.WithError(err).Error("error executing select on frequency_table_item")
+		return nil, 0, ErrUnexpected
+	}
+	defer rows.Close()
+
+	words := make([]entity.WordCount, 0)
+	for rows.Next() {
+		var wc entity.WordCount
+		if err := rows.Scan(&wc.Word, &wc.Count); err != nil {
+			log.WithError(err).Error("error scanning row results")
+			return nil, 0, ErrUnexpected
+		}
+		words = append(words, wc)
+	}
+
+	if err := rows.Err(); err != nil {
+		log.WithError(err).Error("error iterating row results")
+		return nil, 0, ErrUnexpected
+	}
+
+	return words, total, nil
+}
+
+func (r *postgresql) FindWord(ctx context.Context, word string) ([]entity.WordUsage, error) {
+	rows, err := r.db.QueryContext(ctx,
+		"SELECT ft.id, ft.\"name\", fti.times, "+
+			"(SELECT SUM(t.times) FROM frequency_table_item t WHERE t.frequency_table_id = ft.id) "+
+			"FROM frequency_table_item fti JOIN frequency_table ft ON ft.id = fti.frequency_table_id "+
+			"WHERE fti.word=$1 ORDER BY fti.times DESC, ft.id", word)
+	if err != nil {
+		log.WithError(err).Error("error executing select on frequency_table_item")
+		return nil, ErrUnexpected
+	}
+	defer rows.Close()
+
+	usages := make([]entity.WordUsage, 0)
+	for rows.Next() {
+		var usage entity.WordUsage
+		if err := rows.Scan(&usage.FrequencyTableID, &usage.FrequencyTableName, &usage.Count, &usage.Total); err != nil {
+			log.WithError(err).Error("error scanning row results")
+			return nil, ErrUnexpected
+		}
+		usages = append(usages, usage)
+	}
+
+	if err := rows.Err(); err != nil {
+		log.WithError(err).Error("error iterating row results")
+		return nil, ErrUnexpected
+	}
+
+	return usages, nil
+}
+
+// revisionColumns converts the given revision into the values of its columns, which are NULL
+// if the revision is missing.
+func revisionColumns(revision *entity.Revision) (sql.NullString, sql.NullTime) {
+	if revision == nil {
+		return sql.NullString{}, sql.NullTime{}
+	}
+
+	return sql.NullString{String: revision.Hash, Valid: true}, sql.NullTime{Time: revision.Date, Valid: true}
+}
+
+// sourceColumn converts the source of a frequency table into the value of its column, which is NULL
+// for the tables that weren't extracted from a source, such as the merged ones.
+func sourceColumn(source string) sql.NullString {
+	return sql.NullString{String: source, Valid: source != ""}
+}
+
+// parentColumns converts the parent ID and path of the given frequency table into the values of
+// their columns, which are NULL if the table has no parent.
+func parentColumns(ft entity.FrequencyTable) (sql.NullInt64, sql.NullString) {
+	if ft.ParentID == 0 {
+		return sql.NullInt64{}, sql.NullString{}
+	}
+
+	return sql.NullInt64{Int64: ft.ParentID, Valid: true}, sql.NullString{String: ft.Path, Valid: true}
+}
@@ -0,0 +1,168 @@
+package persistence
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"github.com/eroatta/freqtable/entity"
+	"github.com/eroatta/freqtable/repository"
+
+	log "github.com/sirupsen/logrus"
+)
+
+type postgresqlJob struct {
+	db *sql.DB
+}
+
+// NewPostgreSQLJob creates a new JobRepository backed up by a Relational Database.
+func NewPostgreSQLJob(conn *sql.DB) repository.JobRepository {
+	return &postgresqlJob{
+		db: conn,
+	}
+}
+
+func (r *postgresqlJob) Get(ctx context.Context, ID int64) (entity.Job, error) {
+	row := r.db.QueryRowContext(ctx,
+		"SELECT id, url, options, selection, status, frequency_table_id, series_id, error, date_created, last_updated FROM extraction_job WHERE id=$1", ID)
+
+	job, err := scanJob(row)
+	switch err {
+	case nil:
+		return job, nil
+	case sql.ErrNoRows:
+		return entity.Job{}, ErrNoResults
+	default:
+		log.WithError(err).Error("error executing select on extraction_job")
+		return entity.Job{}, ErrUnexpected
+	}
+}
+
+func (r *postgresqlJob) Save(ctx context.Context, job entity.Job) (int64, error) {
+	if job.URL == "" || job.Status == "" {
+		return 0, ErrMissingFields
+	}
+
+	options, err := marshalOptions(&job.Options)
+	if err != nil {
+		log.WithError(err).Error("error marshalling the extraction options")
+		return 0, ErrUnexpected
+	}
+
+	selection, err := marshalSelection(job.Selection)
+	if err != nil {
+		log.WithError(err).Error("error marshalling the revision selection")
+		return 0, ErrUnexpected
+	}
+
+	var id int64
+	err = r.db.QueryRowContext(ctx,
+		"INSERT INTO extraction_job(url, options, selection, status, date_created) VALUES($1, $2, $3, $4, $5) RETURNING id",
+		job.URL, options, selection, job.Status, job.DateCreated).Scan(&id)
+	if err != nil {
+		log.WithError(err).Error("error inserting new extraction_job record")
+		return 0, ErrUnexpected
+	}
+
+	return id, nil
+}
+
+func (r *postgresqlJob) Update(ctx context.Context, job entity.Job) error {
+	result, err := r.db.ExecContext(ctx,
+		"UPDATE extraction_job SET status=$1, frequency_table_id=$2, series_id=$3, error=$4, last_updated=$5 WHERE id=$6",
+		job.Status, sql.NullInt64{Int64: job.FrequencyTableID, Valid: job.FrequencyTableID != 0},
+		sql.NullInt64{Int64: job.SeriesID, Valid: job.SeriesID != 0},
+		sql.NullString{String: job.Error, Valid: job.Error != ""}, job.LastUpdated, job.ID)
+	if err != nil {
+		log.WithError(err).Error("error updating extraction_job record")
+		return ErrUnexpected
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		log.WithError(err).Error("error checking the updated extraction_job records")
+		return ErrUnexpected
+	}
+
+	if affected == 0 {
+		return ErrNoResults
+	}
+
+	return nil
+}
+
+func (r *postgresqlJob) FindByStatus(ctx context.Context, statuses ...entity.JobStatus) ([]entity.Job, error) {
+	if len(statuses) == 0 {
+		return []entity.Job{}, nil
+	}
+
+	placeholders := make([]string, len(statuses))
+	args := make([]interface{}, len(statuses))
+	for i, status := range statuses {
+		placeholders[i] = fmt.Sprintf("$%d", i+1)
+		args[i] = status
+	}
+
+	query := "SELECT id, url, options, selection, status, frequency_table_id, series_id, error, date_created, last_updated FROM extraction_job " +
+		"WHERE status IN (" + strings.Join(placeholders, ", ") + ") ORDER BY id"
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		log.WithError(err).Error("error executing select on extraction_job")
+		return nil, ErrUnexpected
+	}
+	defer rows.Close()
+
+	jobs := make([]entity.Job, 0)
+	for rows.Next() {
+		job, err := scanJob(rows)
+		if err != nil {
+			log.WithError(err).Error("error scanning row results")
+			return nil, ErrUnexpected
+		}
+		jobs = append(jobs, job)
+	}
+
+	if err := rows.Err(); err != nil {
+		log.WithError(err).Error("error iterating row results")
+		return nil, ErrUnexpected
+	}
+
+	return jobs, nil
+}
+
+// scanner represents either a single row or a set of rows.
+type scanner interface {
+	Scan(dest ...interface{}) error
+}
+
+// scanJob reads an entity.Job from the current row.
+func scanJob(row scanner) (entity.Job, error) {
+	var job entity.Job
+	var status string
+	var ftID, seriesID sql.NullInt64
+	var jobErr sql.NullString
+	var lastUpdated sql.NullTime
+	var options, selection sql.NullString
+	if err := row.Scan(&job.ID, &job.URL, &options, &selection, &status, &ftID, &seriesID, &jobErr, &job.DateCreated, &lastUpdated); err != nil {
+		return entity.Job{}, err
+	}
+
+	if stored, err := unmarshalOptions(options); err != nil {
+		return entity.Job{}, err
+	} else if stored != nil {
+		job.Options = *stored
+	}
+	selected, err := unmarshalSelection(selection)
+	if err != nil {
+		return entity.Job{}, err
+	}
+	job.Selection = selected
+	job.Status = entity.JobStatus(status)
+	job.FrequencyTableID = ftID.Int64
+	job.SeriesID = seriesID.Int64
+	job.Error = jobErr.String
+	job.LastUpdated = lastUpdated.Time
+
+	return job, nil
+}
@@ -0,0 +1,215 @@
+package persistence_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/eroatta/freqtable/adapter/persistence"
+	"github.com/eroatta/freqtable/entity"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewPostgreSQLJob_ShouldReturnNewJobRepository(t *testing.T) {
+	jr := persistence.NewPostgreSQLJob(nil)
+
+	assert.NotNil(t, jr)
+}
+
+func TestGet_OnRelationalJobWhenNonExistingJob_ShouldReturnError(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		assert.FailNow(t, fmt.Sprintf("Unexpected error mocking a database connection: %v", err))
+	}
+	defer db.Close()
+	mock.ExpectQuery("SELECT (.+) FROM extraction_job WHERE id=(.+)").
+		WithArgs(42).
+		WillReturnRows(mock.NewRows([]string{"id"}))
+
+	jr := persistence.NewPostgreSQLJob(db)
+	job, err := jr.Get(context.TODO(), 42)
+
+	assert.Empty(t, job)
+	assert.Equal(t, persistence.ErrNoResults, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGet_OnRelationalJobWhenSQLError_ShouldReturnError(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		assert.FailNow(t, fmt.Sprintf("Unexpected error mocking a database connection: %v", err))
+	}
+	defer db.Close()
+	mock.ExpectQuery("SELECT (.+) FROM extraction_job WHERE id=(.+)").
+		WithArgs(42).
+		WillReturnError(errors.New("Connection refused"))
+
+	jr := persistence.NewPostgreSQLJob(db)
+	job, err := jr.Get(context.TODO(), 42)
+
+	assert.Empty(t, job)
+	assert.Equal(t, persistence.ErrUnexpected, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGet_OnRelationalJobWhenExistingJob_ShouldReturnElement(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		assert.FailNow(t, fmt.Sprintf("Unexpected error mocking a database connection: %v", err))
+	}
+	defer db.Close()
+	now := time.Now()
+	rows := mock.NewRows([]string{"id", "url", "options", "selection", "status", "frequency_table_id", "series_id", "error", "date_created", "last_updated"}).
+		AddRow(42, "https://github.com/eroatta/freqtable", `{"exclude":["vendor"],"test_files":"exclude"}`, nil, "succeeded", 1234, nil, nil, now, now)
+	mock.ExpectQuery("SELECT id, url, options, selection, status, frequency_table_id, series_id, error, date_created, last_updated FROM extraction_job WHERE id=(.+)").
+		WithArgs(42).
+		WillReturnRows(rows)
+
+	jr := persistence.NewPostgreSQLJob(db)
+	job, err := jr.Get(context.TODO(), 42)
+
+	assert.NoError(t, err)
+	assert.Equal(t, entity.Job{
+		ID:  42,
+		URL: "https://github.com/eroatta/freqtable",
+		Options: entity.ExtractionOptions{
+			Exclude:   []string{"vendor"},
+			TestFiles: entity.TestFilesExclude,
+		},
+		Status:           entity.JobSucceeded,
+		FrequencyTableID: 1234,
+		DateCreated:      now,
+		LastUpdated:      now,
+	}, job)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestSave_OnRelationalJobWhenMissingMandatoryValues_ShouldReturnError(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		assert.FailNow(t, fmt.Sprintf("Unexpected error mocking a database connection: %v", err))
+	}
+	defer db.Close()
+
+	jr := persistence.NewPostgreSQLJob(db)
+	id, err := jr.Save(context.TODO(), entity.Job{})
+
+	assert.Equal(t, int64(0), id)
+	assert.Equal(t, persistence.ErrMissingFields, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestSave_OnRelationalJobWhenValidJob_ShouldReturnID(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		assert.FailNow(t, fmt.Sprintf("Unexpected error mocking a database connection: %v", err))
+	}
+	defer db.Close()
+	now := time.Now()
+	mock.ExpectQuery("INSERT INTO extraction_job(.+) VALUES(.+) RETURNING id").
+		WithArgs("https://github.com/eroatta/freqtable", `{"skip_generated":true}`, nil, entity.JobQueued, now).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(int64(42)))
+
+	jr := persistence.NewPostgreSQLJob(db)
+	skip := true
+	id, err := jr.Save(context.TODO(), entity.Job{
+		URL:         "https://github.com/eroatta/freqtable",
+		Options:     entity.ExtractionOptions{SkipGenerated: &skip},
+		Status:      entity.JobQueued,
+		DateCreated: now,
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, int64(42), id)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestSave_OnRelationalJobWhenSeriesJob_ShouldStoreSelection(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		assert.FailNow(t, fmt.Sprintf("Unexpected error mocking a database connection: %v", err))
+	}
+	defer db.Close()
+	now := time.Now()
+	mock.ExpectQuery("INSERT INTO extraction_job(.+) VALUES(.+) RETURNING id").
+		WithArgs("https://github.com/eroatta/freqtable", "{}", `{"tags":true,"from":"v0.1.0"}`, entity.JobQueued, now).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(int64(42)))
+
+	jr := persistence.NewPostgreSQLJob(db)
+	id, err := jr.Save(context.TODO(), entity.Job{
+		URL:         "https://github.com/eroatta/freqtable",
+		Selection:   &entity.RevisionSelection{Tags: true, From: "v0.1.0"},
+		Status:      entity.JobQueued,
+		DateCreated: now,
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, int64(42), id)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestUpdate_OnRelationalJobWhenNonExistingJob_ShouldReturnError(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		assert.FailNow(t, fmt.Sprintf("Unexpected error mocking a database connection: %v", err))
+	}
+	defer db.Close()
+	now := time.Now()
+	mock.ExpectExec("UPDATE extraction_job SET (.+) WHERE id=(.+)").
+		WithArgs(entity.JobRunning, nil, nil, nil, now, 42).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	jr := persistence.NewPostgreSQLJob(db)
+	err = jr.Update(context.TODO(), entity.Job{ID: 42, Status: entity.JobRunning, LastUpdated: now})
+
+	assert.Equal(t, persistence.ErrNoResults, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestUpdate_OnRelationalJobWhenFailedJob_ShouldStoreError(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		assert.FailNow(t, fmt.Sprintf("Unexpected error mocking a database connection: %v", err))
+	}
+	defer db.Close()
+	now := time.Now()
+	mock.ExpectExec("UPDATE extraction_job SET status=(.+), frequency_table_id=(.+), series_id=(.+), error=(.+), last_updated=(.+) WHERE id=(.+)").
+		WithArgs(entity.JobFailed, nil, nil, "error cloning repository", now, 42).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	jr := persistence.NewPostgreSQLJob(db)
+	err = jr.Update(context.TODO(), entity.Job{ID: 42, Status: entity.JobFailed, Error: "error cloning repository", LastUpdated: now})
+
+	assert.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestFindByStatus_OnRelationalJob_ShouldReturnMatchingJobs(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		assert.FailNow(t, fmt.Sprintf("Unexpected error mocking a database connection: %v", err))
+	}
+	defer db.Close()
+	now := time.Now()
+	rows := mock.NewRows([]string{"id", "url", "options", "selection", "status", "frequency_table_id", "series_id", "error", "date_created", "last_updated"}).
+		AddRow(41, "https://github.com/eroatta/freqtable", "{}", nil, "running", nil, nil, nil, now, now).
+		AddRow(42, "https://github.com/eroatta/token", nil, `{"every":10}`, "queued", nil, nil, nil, now, nil)
+	mock.ExpectQuery("SELECT (.+) FROM extraction_job WHERE status IN \\(\\$1, \\$2\\) ORDER BY id").
+		WithArgs(entity.JobQueued, entity.JobRunning).
+		WillReturnRows(rows)
+
+	jr := persistence.NewPostgreSQLJob(db)
+	jobs, err := jr.FindByStatus(context.TODO(), entity.JobQueued, entity.JobRunning)
+
+	assert.NoError(t, err)
+	assert.Equal(t, 2, len(jobs))
+	assert.Equal(t, entity.JobRunning, jobs[0].Status)
+	assert.Equal(t, entity.JobQueued, jobs[1].Status)
+	assert.Nil(t, jobs[0].Selection)
+	assert.Equal(t, &entity.RevisionSelection{Every: 10}, jobs[1].Selection)
+	assert.True(t, jobs[1].LastUpdated.IsZero())
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,142 @@
+package persistence
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+
+	"github.com/eroatta/freqtable/entity"
+	"github.com/eroatta/freqtable/repository"
+
+	log "github.com/sirupsen/logrus"
+)
+
+type postgresqlSeries struct {
+	db *sql.DB
+}
+
+// NewPostgreSQLSeries creates a new SeriesRepository backed up by a Relational Database.
+func NewPostgreSQLSeries(conn *sql.DB) repository.SeriesRepository {
+	return &postgresqlSeries{
+		db: conn,
+	}
+}
+
+func (r *postgresqlSeries) Get(ctx context.Context, ID int64) (entity.Series, error) {
+	var series entity.Series
+	var selection, options sql.NullString
+	row := r.db.QueryRowContext(ctx,
+		"SELECT id, \"name\", selection, options, date_created FROM series WHERE id=$1", ID)
+	switch err := row.Scan(&series.ID, &series.Name, &selection, &options, &series.DateCreated); err {
+	case sql.ErrNoRows:
+		return entity.Series{}, ErrNoResults
+	case nil:
+		// continue
+	default:
+		log.WithError(err).Error("error executing select on series")
+		return entity.Series{}, ErrUnexpected
+	}
+
+	if selection.Valid {
+		var record selectionRecord
+		if err := json.Unmarshal([]byte(selection.String), &record); err != nil {
+			log.WithError(err).Error("error unmarshalling the revision selection")
+			return entity.Series{}, ErrUnexpected
+		}
+		series.Selection = entity.RevisionSelection(record)
+	}
+
+	stored, err := unmarshalOptions(options)
+	if err != nil {
+		log.WithError(err).Error("error unmarshalling the extraction options")
+		return entity.Series{}, ErrUnexpected
+	}
+	if stored != nil {
+		series.Options = *stored
+	}
+
+	rows, err := r.db.QueryContext(ctx,
+		"SELECT frequency_table_id, ref, revision_hash, revision_date FROM series_item WHERE series_id=$1 ORDER BY position", ID)
+	if err != nil {
+		log.WithError(err).Error("error executing select on series_item")
+		return entity.Series{}, ErrUnexpected
+	}
+	defer rows.Close()
+
+	series.Entries = make([]entity.SeriesEntry, 0)
+	for rows.Next() {
+		var entry entity.SeriesEntry
+		if err := rows.Scan(&entry.FrequencyTableID, &entry.Ref, &entry.Revision.Hash, &entry.Revision.Date); err != nil {
+			log.WithError(err).Error("error scanning row results")
+			return entity.Series{}, ErrUnexpected
+		}
+		series.Entries = append(series.Entries, entry)
+	}
+
+	if err := rows.Err(); err != nil {
+		log.WithError(err).Error("error iterating row results")
+		return entity.Series{}, ErrUnexpected
+	}
+
+	return series, nil
+}
+
+func (r *postgresqlSeries) Save(ctx context.Context, series entity.Series) (int64, error) {
+	if series.Name == "" {
+		return 0, ErrMissingFields
+	}
+
+	selection, err := json.Marshal(selectionRecord(series.Selection))
+	if err != nil {
+		log.WithError(err).Error("error marshalling the revision selection")
+		return 0, ErrUnexpected
+	}
+
+	options, err := marshalOptions(&series.Options)
+	if err != nil {
+		log.WithError(err).Error("error marshalling the extraction options")
+		return 0, ErrUnexpected
+	}
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		log.WithError(err).Error("error beginning a transaction")
+		return 0, ErrUnexpected
+	}
+
+	var id int64
+	err = tx.QueryRowContext(ctx,
+		"INSERT INTO series(name, selection, options, date_created) VALUES($1, $2, $3, $4) RETURNING id",
+		series.Name, string(selection), options, series.DateCreated).Scan(&id)
+	if err != nil {
+		log.WithError(err).Error("error inserting new series record")
+		defer tx.Rollback()
+		return 0, ErrUnexpected
+	}
+
+	stmt, err := tx.PrepareContext(ctx,
+		"INSERT INTO series_item(series_id, position, frequency_table_id, ref, revision_hash, revision_date) "+
+			"VALUES ($1, $2, $3, $4, $5, $6)")
+	if err != nil {
+		log.WithError(err).Error("error preparing statement for series_item insertion")
+		defer tx.Rollback()
+		return 0, ErrUnexpected
+	}
+
+	for position, entry := range series.Entries {
+		if _, err = stmt.ExecContext(ctx, id, position, entry.FrequencyTableID, entry.Ref,
+			entry.Revision.Hash, entry.Revision.Date); err != nil {
+			log.WithError(err).Error("error inserting new series_item record")
+			defer tx.Rollback()
+			return 0, ErrUnexpected
+		}
+	}
+
+	if err = tx.Commit(); err != nil {
+		log.WithError(err).Error("error committing a transaction")
+		defer tx.Rollback()
+		return 0, ErrUnexpected
+	}
+
+	return id, nil
+}
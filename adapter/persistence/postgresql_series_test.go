@@ -0,0 +1,155 @@
+package persistence_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/eroatta/freqtable/adapter/persistence"
+	"github.com/eroatta/freqtable/entity"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewPostgreSQLSeries_ShouldReturnNewSeriesRepository(t *testing.T) {
+	sr := persistence.NewPostgreSQLSeries(nil)
+
+	assert.NotNil(t, sr)
+}
+
+func TestGet_OnRelationalSeriesWhenNonExistingSeries_ShouldReturnError(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		assert.FailNow(t, fmt.Sprintf("Unexpected error mocking a database connection: %v", err))
+	}
+	defer db.Close()
+	mock.ExpectQuery("SELECT (.+) FROM series WHERE id=(.+)").
+		WithArgs(42).
+		WillReturnRows(mock.NewRows([]string{"id"}))
+
+	sr := persistence.NewPostgreSQLSeries(db)
+	series, err := sr.Get(context.TODO(), 42)
+
+	assert.Empty(t, series)
+	assert.Equal(t, persistence.ErrNoResults, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGet_OnRelationalSeriesWhenExistingSeries_ShouldReturnElementWithEntries(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		assert.FailNow(t, fmt.Sprintf("Unexpected error mocking a database connection: %v", err))
+	}
+	defer db.Close()
+	now := time.Now()
+	mock.ExpectQuery("SELECT id, \"name\", selection, options, date_created FROM series WHERE id=(.+)").
+		WithArgs(42).
+		WillReturnRows(mock.NewRows([]string{"id", "name", "selection", "options", "date_created"}).
+			AddRow(42, "https://github.com/eroatta/freqtable", `{"every":10,"from":"v0.1.0"}`, `{"test_files":"exclude"}`, now))
+	mock.ExpectQuery("SELECT frequency_table_id, ref, revision_hash, revision_date FROM series_item WHERE series_id=(.+) ORDER BY position").
+		WithArgs(42).
+		WillReturnRows(mock.NewRows([]string{"frequency_table_id", "ref", "revision_hash", "revision_date"}).
+			AddRow(7, "a1", "a1", now).
+			AddRow(8, "b2", "b2", now))
+
+	sr := persistence.NewPostgreSQLSeries(db)
+	series, err := sr.Get(context.TODO(), 42)
+
+	assert.NoError(t, err)
+	assert.Equal(t, entity.Series{
+		ID:          42,
+		Name:        "https://github.com/eroatta/freqtable",
+		Selection:   entity.RevisionSelection{Every: 10, From: "v0.1.0"},
+		Options:     entity.ExtractionOptions{TestFiles: entity.TestFilesExclude},
+		DateCreated: now,
+		Entries: []entity.SeriesEntry{
+			{FrequencyTableID: 7, Ref: "a1", Revision: entity.Revision{Hash: "a1", Date: now}},
+			{FrequencyTableID: 8, Ref: "b2", Revision: entity.Revision{Hash: "b2", Date: now}},
+		},
+	}, series)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestSave_OnRelationalSeriesWhenMissingMandatoryValues_ShouldReturnError(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		assert.FailNow(t, fmt.Sprintf("Unexpected error mocking a database connection: %v", err))
+	}
+	defer db.Close()
+
+	sr := persistence.NewPostgreSQLSeries(db)
+	id, err := sr.Save(context.TODO(), entity.Series{})
+
+	assert.Equal(t, int64(0), id)
+	assert.Equal(t, persistence.ErrMissingFields, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestSave_OnRelationalSeriesWhenErrorInsertingEntries_ShouldRollbackAndReturnError(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		assert.FailNow(t, fmt.Sprintf("Unexpected error mocking a database connection: %v", err))
+	}
+	defer db.Close()
+	now := time.Now()
+	mock.ExpectBegin()
+	mock.ExpectQuery("INSERT INTO series(.+) VALUES(.+) RETURNING id").
+		WithArgs("https://github.com/eroatta/freqtable", `{"tags":true}`, `{}`, now).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(int64(42)))
+	mock.ExpectPrepare("INSERT INTO series_item(.+) VALUES(.+)")
+	mock.ExpectExec("INSERT INTO series_item(.+) VALUES(.+)").
+		WithArgs(42, 0, 7, "v0.1.0", "a1", now).
+		WillReturnError(errors.New("sql: invalid value"))
+	mock.ExpectRollback()
+
+	sr := persistence.NewPostgreSQLSeries(db)
+	id, err := sr.Save(context.TODO(), entity.Series{
+		Name:        "https://github.com/eroatta/freqtable",
+		Selection:   entity.RevisionSelection{Tags: true},
+		DateCreated: now,
+		Entries:     []entity.SeriesEntry{{FrequencyTableID: 7, Ref: "v0.1.0", Revision: entity.Revision{Hash: "a1", Date: now}}},
+	})
+
+	assert.Equal(t, int64(0), id)
+	assert.Equal(t, persistence.ErrUnexpected, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestSave_OnRelationalSeriesWhenValidSeries_ShouldReturnID(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		assert.FailNow(t, fmt.Sprintf("Unexpected error mocking a database connection: %v", err))
+	}
+	defer db.Close()
+	now := time.Now()
+	mock.ExpectBegin()
+	mock.ExpectQuery("INSERT INTO series(.+) VALUES(.+) RETURNING id").
+		WithArgs("https://github.com/eroatta/freqtable", `{"every":5}`, `{"exclude":["vendor/**"]}`, now).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(int64(42)))
+	mock.ExpectPrepare("INSERT INTO series_item(.+) VALUES(.+)")
+	mock.ExpectExec("INSERT INTO series_item(.+) VALUES(.+)").
+		WithArgs(42, 0, 7, "a1", "a1", now).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("INSERT INTO series_item(.+) VALUES(.+)").
+		WithArgs(42, 1, 8, "b2", "b2", now).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	sr := persistence.NewPostgreSQLSeries(db)
+	id, err := sr.Save(context.TODO(), entity.Series{
+		Name:        "https://github.com/eroatta/freqtable",
+		Selection:   entity.RevisionSelection{Every: 5},
+		Options:     entity.ExtractionOptions{Exclude: []string{"vendor/**"}},
+		DateCreated: now,
+		Entries: []entity.SeriesEntry{
+			{FrequencyTableID: 7, Ref: "a1", Revision: entity.Revision{Hash: "a1", Date: now}},
+			{FrequencyTableID: 8, Ref: "b2", Revision: entity.Revision{Hash: "b2", Date: now}},
+		},
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, int64(42), id)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
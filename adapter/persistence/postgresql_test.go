@@ -10,6 +10,8 @@ import (
 	"github.com/DATA-DOG/go-sqlmock"
 	"github.com/eroatta/freqtable/adapter/persistence"
 	"github.com/eroatta/freqtable/entity"
+	"github.com/eroatta/freqtable/repository"
+	"github.com/lib/pq"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -25,8 +27,8 @@ func TestGet_OnRelationalWhenSQLError_ShouldReturnError(t *testing.T) {
 		assert.FailNow(t, fmt.Sprintf("Unexpected error mocking a database connection: %v", err))
 	}
 	defer db.Close()
-	mock.ExpectPrepare("SELECT id, \"name\", date_created, last_updated FROM frequency_table WHERE id=(.+)")
-	mock.ExpectQuery("SELECT id, \"name\", date_created, last_updated FROM frequency_table WHERE id=(.+)").
+	mock.ExpectPrepare("SELECT id, \"name\", date_created, last_updated, revision_hash, revision_date, options, report, parent_id, path, source FROM frequency_table WHERE id=(.+)")
+	mock.ExpectQuery("SELECT id, \"name\", date_created, last_updated, revision_hash, revision_date, options, report, parent_id, path, source FROM frequency_table WHERE id=(.+)").
 		WithArgs(1234567890).
 		WillReturnError(errors.New("Connection refused"))
 
@@ -45,8 +47,8 @@ func TestGet_OnRelationalWhenNonExistingFrequencyTable_ShouldReturnError(t *test
 	}
 	defer db.Close()
 	rows := mock.NewRows([]string{"id"})
-	mock.ExpectPrepare("SELECT id, \"name\", date_created, last_updated FROM frequency_table WHERE id=(.+)")
-	mock.ExpectQuery("SELECT id, \"name\", date_created, last_updated FROM frequency_table WHERE id=(.+)").
+	mock.ExpectPrepare("SELECT id, \"name\", date_created, last_updated, revision_hash, revision_date, options, report, parent_id, path, source FROM frequency_table WHERE id=(.+)")
+	mock.ExpectQuery("SELECT id, \"name\", date_created, last_updated, revision_hash, revision_date, options, report, parent_id, path, source FROM frequency_table WHERE id=(.+)").
 		WithArgs(1234567890).
 		WillReturnRows(rows)
 
@@ -65,9 +67,9 @@ func TestGet_OnRelationalWhenExistingFrequencyTable_ShouldReturnElement(t *testi
 	}
 	defer db.Close()
 	now := time.Now()
-	rows := mock.NewRows([]string{"id", "name", "date_created", "last_updated"}).AddRow(1234567890, "testname", now, now)
-	mock.ExpectPrepare("SELECT id, \"name\", date_created, last_updated FROM frequency_table WHERE id=(.+)")
-	mock.ExpectQuery("SELECT id, \"name\", date_created, last_updated FROM frequency_table WHERE id=(.+)").
+	rows := mock.NewRows([]string{"id", "name", "date_created", "last_updated", "revision_hash", "revision_date", "options", "report", "parent_id", "path", "source"}).AddRow(1234567890, "testname", now, now, nil, nil, nil, nil, nil, nil, nil)
+	mock.ExpectPrepare("SELECT id, \"name\", date_created, last_updated, revision_hash, revision_date, options, report, parent_id, path, source FROM frequency_table WHERE id=(.+)")
+	mock.ExpectQuery("SELECT id, \"name\", date_created, last_updated, revision_hash, revision_date, options, report, parent_id, path, source FROM frequency_table WHERE id=(.+)").
 		WithArgs(1234567890).
 		WillReturnRows(rows)
 
@@ -92,6 +94,106 @@ func TestGet_OnRelationalWhenExistingFrequencyTable_ShouldReturnElement(t *testi
 	assert.NoError(t, mock.ExpectationsWereMet())
 }
 
+func TestGet_OnRelationalWhenExtractedFrequencyTable_ShouldReturnElementWithRevisionOptionsAndReport(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		assert.FailNow(t, fmt.Sprintf("Unexpected error mocking a database connection: %v", err))
+	}
+	defer db.Close()
+	now := time.Now()
+	report := `{"files":3,"go_files":2,"parsed_files":1,"skipped_files":[{"name":"main.go","reason":"expected ';'"}],"bytes":512}`
+	options := `{"include":["cmd/**"],"skip_generated":false,"test_files":"only"}`
+	rows := mock.NewRows([]string{"id", "name", "date_created", "last_updated", "revision_hash", "revision_date", "options", "report", "parent_id", "path", "source"}).
+		AddRow(1234567890, "testname", now, now, "9f3a1c2b4d5e6f708192a3b4c5d6e7f801234567", now, options, report, 7, "api", "https://github.com/eroatta/freqtable")
+	mock.ExpectPrepare("SELECT id, \"name\", date_created, last_updated, revision_hash, revision_date, options, report, parent_id, path, source FROM frequency_table WHERE id=(.+)")
+	mock.ExpectQuery("SELECT id, \"name\", date_created, last_updated, revision_hash, revision_date, options, report, parent_id, path, source FROM frequency_table WHERE id=(.+)").
+		WithArgs(1234567890).
+		WillReturnRows(rows)
+
+	rowsItems := mock.NewRows([]string{"word", "times"}).AddRow("cars", 1)
+	mock.ExpectPrepare("SELECT word, times FROM frequency_table_item WHERE frequency_table_id=(.+)")
+	mock.ExpectQuery("SELECT word, times FROM frequency_table_item WHERE frequency_table_id=(.+)").
+		WithArgs(1234567890).
+		WillReturnRows(rowsItems)
+
+	ftr := persistence.NewPostgreSQL(db)
+	ft, err := ftr.Get(context.TODO(), 1234567890)
+
+	assert.Equal(t, &entity.Revision{Hash: "9f3a1c2b4d5e6f708192a3b4c5d6e7f801234567", Date: now}, ft.Revision)
+	assert.Equal(t, int64(7), ft.ParentID)
+	assert.Equal(t, "api", ft.Path)
+	assert.Equal(t, "https://github.com/eroatta/freqtable", ft.Source)
+	skip := false
+	assert.Equal(t, &entity.ExtractionOptions{
+		Include:       []string{"cmd/**"},
+		SkipGenerated: &skip,
+		TestFiles:     entity.TestFilesOnly,
+	}, ft.Options)
+	assert.Equal(t, &entity.ExtractionReport{
+		Files:        3,
+		GoFiles:      2,
+		ParsedFiles:  1,
+		SkippedFiles: []entity.SkippedFile{{Name: "main.go", Reason: "expected ';'"}},
+		Bytes:        512,
+	}, ft.Report)
+	assert.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGet_OnRelationalWhenNeverUpdatedFrequencyTable_ShouldReturnElementWithoutLastUpdated(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		assert.FailNow(t, fmt.Sprintf("Unexpected error mocking a database connection: %v", err))
+	}
+	defer db.Close()
+	now := time.Now()
+	rows := mock.NewRows([]string{"id", "name", "date_created", "last_updated", "revision_hash", "revision_date", "options", "report", "parent_id", "path", "source"}).AddRow(1234567890, "testname", now, nil, nil, nil, nil, nil, nil, nil, nil)
+	mock.ExpectPrepare("SELECT id, \"name\", date_created, last_updated, revision_hash, revision_date, options, report, parent_id, path, source FROM frequency_table WHERE id=(.+)")
+	mock.ExpectQuery("SELECT id, \"name\", date_created, last_updated, revision_hash, revision_date, options, report, parent_id, path, source FROM frequency_table WHERE id=(.+)").
+		WithArgs(1234567890).
+		WillReturnRows(rows)
+
+	rowsItems := mock.NewRows([]string{"word", "times"}).AddRow("cars", 1)
+	mock.ExpectPrepare("SELECT word, times FROM frequency_table_item WHERE frequency_table_id=(.+)")
+	mock.ExpectQuery("SELECT word, times FROM frequency_table_item WHERE frequency_table_id=(.+)").
+		WithArgs(1234567890).
+		WillReturnRows(rowsItems)
+
+	ftr := persistence.NewPostgreSQL(db)
+	ft, err := ftr.Get(context.TODO(), 1234567890)
+
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1234567890), ft.ID)
+	assert.True(t, ft.LastUpdated.IsZero())
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGet_OnRelationalWhenSQLErrorOnItems_ShouldReturnError(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		assert.FailNow(t, fmt.Sprintf("Unexpected error mocking a database connection: %v", err))
+	}
+	defer db.Close()
+	now := time.Now()
+	rows := mock.NewRows([]string{"id", "name", "date_created", "last_updated", "revision_hash", "revision_date", "options", "report", "parent_id", "path", "source"}).AddRow(1234567890, "testname", now, now, nil, nil, nil, nil, nil, nil, nil)
+	mock.ExpectPrepare("SELECT id, \"name\", date_created, last_updated, revision_hash, revision_date, options, report, parent_id, path, source FROM frequency_table WHERE id=(.+)")
+	mock.ExpectQuery("SELECT id, \"name\", date_created, last_updated, revision_hash, revision_date, options, report, parent_id, path, source FROM frequency_table WHERE id=(.+)").
+		WithArgs(1234567890).
+		WillReturnRows(rows)
+
+	mock.ExpectPrepare("SELECT word, times FROM frequency_table_item WHERE frequency_table_id=(.+)")
+	mock.ExpectQuery("SELECT word, times FROM frequency_table_item WHERE frequency_table_id=(.+)").
+		WithArgs(1234567890).
+		WillReturnError(errors.New("Connection refused"))
+
+	ftr := persistence.NewPostgreSQL(db)
+	ft, err := ftr.Get(context.TODO(), 1234567890)
+
+	assert.Empty(t, ft)
+	assert.Equal(t, persistence.ErrUnexpected, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
 func TestSave_OnRelationalWhenMissingMandatoryValues_ShouldReturnError(t *testing.T) {
 	db, mock, err := sqlmock.New()
 	if err != nil {
@@ -120,7 +222,7 @@ func TestSave_OnRelationalWhenSQLError_ShouldReturnError(t *testing.T) {
 	mock.ExpectPrepare("INSERT INTO frequency_table(.+) VALUES(.+) RETURNING id")
 	now := time.Now()
 	mock.ExpectQuery("INSERT INTO frequency_table(.+) VALUES(.+) RETURNING id").
-		WithArgs("testname", now).
+		WithArgs("testname", now, nil, nil, nil, nil, nil, nil, nil).
 		WillReturnError(errors.New("sql: unexisting table"))
 
 	ftr := persistence.NewPostgreSQL(db)
@@ -152,7 +254,7 @@ func TestSave_OnRelationalWhenErrorInsertingItems_ShouldReturnError(t *testing.T
 	now := time.Now()
 	rows := sqlmock.NewRows([]string{"id"}).AddRow(int64(1234567890))
 	mock.ExpectQuery("INSERT INTO frequency_table(.+) VALUES(.+) RETURNING id").
-		WithArgs("testname", now).
+		WithArgs("testname", now, nil, nil, nil, nil, nil, nil, nil).
 		WillReturnRows(rows)
 
 	mock.ExpectPrepare("INSERT INTO frequency_table_item(.+) VALUES(.+)")
@@ -189,7 +291,7 @@ func TestSave_OnRelationalWhenValidFrequencyTable_ShouldReturnNoError(t *testing
 	now := time.Now()
 	rows := sqlmock.NewRows([]string{"id"}).AddRow(int64(1234567890))
 	mock.ExpectQuery("INSERT INTO frequency_table(.+) VALUES(.+) RETURNING id").
-		WithArgs("testname", now).
+		WithArgs("testname", now, nil, nil, nil, nil, nil, nil, nil).
 		WillReturnRows(rows)
 
 	mock.ExpectPrepare("INSERT INTO frequency_table_item(.+) VALUES(.+)")
@@ -213,3 +315,493 @@ func TestSave_OnRelationalWhenValidFrequencyTable_ShouldReturnNoError(t *testing
 	assert.NoError(t, err)
 	assert.NoError(t, mock.ExpectationsWereMet())
 }
+
+func TestSave_OnRelationalWhenExtractedFrequencyTable_ShouldStoreRevisionOptionsAndReport(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		assert.FailNow(t, fmt.Sprintf("Unexpected error mocking a database connection: %v", err))
+	}
+	defer db.Close()
+
+	mock.ExpectBegin()
+	mock.ExpectPrepare("INSERT INTO frequency_table(.+) VALUES(.+) RETURNING id")
+	now := time.Now()
+	report := `{"files":3,"go_files":2,"excluded_files":0,"parsed_files":1,"skipped_files":[{"name":"main.go","reason":"expected ';'","line":3,"column":5}],` +
+		`"bytes":512,"clone_duration":1000,"read_duration":2000,"parse_duration":3000,"mine_duration":4000,"total_duration":10000}`
+	rows := sqlmock.NewRows([]string{"id"}).AddRow(int64(1234567890))
+	mock.ExpectQuery("INSERT INTO frequency_table(.+) VALUES(.+) RETURNING id").
+		WithArgs("testname", now, "9f3a1c2b4d5e6f708192a3b4c5d6e7f801234567", now, `{"ref":"v1.0.0","exclude":["vendor"],"test_files":"exclude","granularity":"package"}`, report, 7, "api",
+			"https://github.com/eroatta/freqtable").
+		WillReturnRows(rows)
+
+	mock.ExpectPrepare("INSERT INTO frequency_table_item(.+) VALUES(.+)")
+	mock.ExpectExec("INSERT INTO frequency_table_item(.+) VALUES(.+)").
+		WithArgs(1234567890, "cars", 1).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	ftr := persistence.NewPostgreSQL(db)
+
+	ft := entity.FrequencyTable{
+		Name:        "testname",
+		DateCreated: now,
+		Values: map[string]int{
+			"cars": 1,
+		},
+		Options: &entity.ExtractionOptions{
+			Ref:         "v1.0.0",
+			Exclude:     []string{"vendor"},
+			TestFiles:   entity.TestFilesExclude,
+			Granularity: entity.GranularityPackage,
+		},
+		Revision: &entity.Revision{Hash: "9f3a1c2b4d5e6f708192a3b4c5d6e7f801234567", Date: now},
+		ParentID: 7,
+		Path:     "api",
+		Source:   "https://github.com/eroatta/freqtable",
+		Report: &entity.ExtractionReport{
+			Files:       3,
+			GoFiles:     2,
+			ParsedFiles: 1,
+			SkippedFiles: []entity.SkippedFile{
+				{Name: "main.go", Reason: "expected ';'", Line: 3, Column: 5},
+			},
+			Bytes:         512,
+			CloneDuration: time.Microsecond,
+			ReadDuration:  2 * time.Microsecond,
+			ParseDuration: 3 * time.Microsecond,
+			MineDuration:  4 * time.Microsecond,
+			TotalDuration: 10 * time.Microsecond,
+		},
+	}
+	id, err := ftr.Save(context.TODO(), ft)
+
+	assert.Equal(t, int64(1234567890), id)
+	assert.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestFindByName_OnRelationalWhenNonExistingFrequencyTable_ShouldReturnError(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		assert.FailNow(t, fmt.Sprintf("Unexpected error mocking a database connection: %v", err))
+	}
+	defer db.Close()
+	mock.ExpectPrepare("SELECT id FROM frequency_table WHERE \"name\"=(.+)")
+	mock.ExpectQuery("SELECT id FROM frequency_table WHERE \"name\"=(.+)").
+		WithArgs("testname").
+		WillReturnRows(mock.NewRows([]string{"id"}))
+
+	ftr := persistence.NewPostgreSQL(db)
+	id, err := ftr.FindByName(context.TODO(), "testname")
+
+	assert.Equal(t, int64(0), id)
+	assert.Equal(t, persistence.ErrNoResults, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestFindByName_OnRelationalWhenExistingFrequencyTable_ShouldReturnID(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		assert.FailNow(t, fmt.Sprintf("Unexpected error mocking a database connection: %v", err))
+	}
+	defer db.Close()
+	mock.ExpectPrepare("SELECT id FROM frequency_table WHERE \"name\"=(.+)")
+	mock.ExpectQuery("SELECT id FROM frequency_table WHERE \"name\"=(.+)").
+		WithArgs("testname").
+		WillReturnRows(mock.NewRows([]string{"id"}).AddRow(1234567890))
+
+	ftr := persistence.NewPostgreSQL(db)
+	id, err := ftr.FindByName(context.TODO(), "testname")
+
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1234567890), id)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestList_OnRelationalWhenSQLError_ShouldReturnError(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		assert.FailNow(t, fmt.Sprintf("Unexpected error mocking a database connection: %v", err))
+	}
+	defer db.Close()
+	mock.ExpectQuery("SELECT (.+) FROM frequency_table ft LEFT JOIN frequency_table_item fti (.+) WHERE ft.id > (.+) GROUP BY ft.id ORDER BY ft.id").
+		WithArgs(0).
+		WillReturnError(errors.New("Connection refused"))
+
+	ftr := persistence.NewPostgreSQL(db)
+	summaries, next, err := ftr.List(context.TODO(), repository.FrequencyTableFilter{})
+
+	assert.Nil(t, summaries)
+	assert.Equal(t, int64(0), next)
+	assert.Equal(t, persistence.ErrUnexpected, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestList_OnRelationalWithFilters_ShouldReturnSummariesAndCursor(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		assert.FailNow(t, fmt.Sprintf("Unexpected error mocking a database connection: %v", err))
+	}
+	defer db.Close()
+	now := time.Now()
+	after := now.Add(-time.Hour)
+	before := now.Add(time.Hour)
+	rows := mock.NewRows([]string{"id", "name", "date_created", "last_updated", "count"}).
+		AddRow(11, "https://github.com/eroatta/freqtable", now, nil, 120).
+		AddRow(12, "https://github.com/eroatta/token", now, now, 80).
+		AddRow(13, "https://github.com/eroatta/src_reader", now, nil, 90)
+	mock.ExpectQuery("SELECT ft.id, ft.\"name\", ft.date_created, ft.last_updated, COUNT\\(fti.word\\) "+
+		"FROM frequency_table ft LEFT JOIN frequency_table_item fti ON fti.frequency_table_id = ft.id "+
+		"WHERE ft.id > \\$1 AND ft.\"name\" ILIKE \\$2 AND ft.date_created > \\$3 AND ft.date_created < \\$4 "+
+		"GROUP BY ft.id HAVING COUNT\\(fti.word\\) >= \\$5 ORDER BY ft.id LIMIT \\$6").
+		WithArgs(10, "%src\\_reader%", after, before, 50, 3).
+		WillReturnRows(rows)
+
+	ftr := persistence.NewPostgreSQL(db)
+	summaries, next, err := ftr.List(context.TODO(), repository.FrequencyTableFilter{
+		Name:          "src_reader",
+		CreatedAfter:  after,
+		CreatedBefore: before,
+		MinVocabulary: 50,
+		Cursor:        10,
+		Limit:         2,
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, int64(12), next)
+	assert.Equal(t, 2, len(summaries))
+	assert.Equal(t, int64(11), summaries[0].ID)
+	assert.Equal(t, "https://github.com/eroatta/freqtable", summaries[0].Name)
+	assert.True(t, summaries[0].LastUpdated.IsZero())
+	assert.Equal(t, 120, summaries[0].Vocabulary)
+	assert.Equal(t, now, summaries[1].LastUpdated)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestList_OnRelationalByParentID_ShouldFilterChildFrequencyTables(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		assert.FailNow(t, fmt.Sprintf("Unexpected error mocking a database connection: %v", err))
+	}
+	defer db.Close()
+	now := time.Now()
+	rows := mock.NewRows([]string{"id", "name", "date_created", "last_updated", "count"}).
+		AddRow(12, "https://github.com/eroatta/freqtable#entity", now, nil, 40)
+	mock.ExpectQuery("SELECT (.+) FROM frequency_table ft (.+) WHERE ft.id > \\$1 AND ft.parent_id = \\$2 GROUP BY ft.id ORDER BY ft.id").
+		WithArgs(0, 11).
+		WillReturnRows(rows)
+
+	ftr := persistence.NewPostgreSQL(db)
+	summaries, next, err := ftr.List(context.TODO(), repository.FrequencyTableFilter{ParentID: 11})
+
+	assert.NoError(t, err)
+	assert.Equal(t, int64(0), next)
+	assert.Equal(t, 1, len(summaries))
+	assert.Equal(t, "https://github.com/eroatta/freqtable#entity", summaries[0].Name)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestList_OnRelationalOnLastPage_ShouldReturnNoCursor(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		assert.FailNow(t, fmt.Sprintf("Unexpected error mocking a database connection: %v", err))
+	}
+	defer db.Close()
+	now := time.Now()
+	rows := mock.NewRows([]string{"id", "name", "date_created", "last_updated", "count"}).
+		AddRow(11, "https://github.com/eroatta/freqtable", now, nil, 120)
+	mock.ExpectQuery("SELECT (.+) FROM frequency_table ft (.+) ORDER BY ft.id LIMIT \\$2").
+		WithArgs(0, 3).
+		WillReturnRows(rows)
+
+	ftr := persistence.NewPostgreSQL(db)
+	summaries, next, err := ftr.List(context.TODO(), repository.FrequencyTableFilter{Limit: 2})
+
+	assert.NoError(t, err)
+	assert.Equal(t, int64(0), next)
+	assert.Equal(t, 1, len(summaries))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestSave_OnRelationalWhenDuplicatedName_ShouldReturnError(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		assert.FailNow(t, fmt.Sprintf("Unexpected error mocking a database connection: %v", err))
+	}
+	defer db.Close()
+
+	mock.ExpectBegin()
+	mock.ExpectPrepare("INSERT INTO frequency_table(.+) VALUES(.+) RETURNING id")
+	now := time.Now()
+	mock.ExpectQuery("INSERT INTO frequency_table(.+) VALUES(.+) RETURNING id").
+		WithArgs("testname", now, nil, nil, nil, nil, nil, nil, nil).
+		WillReturnError(&pq.Error{Code: "23505"})
+	mock.ExpectRollback()
+
+	ftr := persistence.NewPostgreSQL(db)
+
+	ft := entity.FrequencyTable{
+		Name:        "testname",
+		DateCreated: now,
+		Values: map[string]int{
+			"cars": 1,
+		},
+	}
+	id, err := ftr.Save(context.TODO(), ft)
+
+	assert.Equal(t, int64(0), id)
+	assert.Equal(t, persistence.ErrDuplicated, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestSaveWithChildren_OnRelationalWhenErrorInsertingChild_ShouldRollbackParent(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		assert.FailNow(t, fmt.Sprintf("Unexpected error mocking a database connection: %v", err))
+	}
+	defer db.Close()
+
+	mock.ExpectBegin()
+	mock.ExpectPrepare("INSERT INTO frequency_table(.+) VALUES(.+) RETURNING id")
+	now := time.Now()
+	mock.ExpectQuery("INSERT INTO frequency_table(.+) VALUES(.+) RETURNING id").
+		WithArgs("testname", now, nil, nil, nil, nil, nil, nil, nil).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(int64(7)))
+	mock.ExpectPrepare("INSERT INTO frequency_table_item(.+) VALUES(.+)")
+	mock.ExpectExec("INSERT INTO frequency_table_item(.+) VALUES(.+)").
+		WithArgs(7, "cars", 1).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectPrepare("INSERT INTO frequency_table(.+) VALUES(.+) RETURNING id")
+	mock.ExpectQuery("INSERT INTO frequency_table(.+) VALUES(.+) RETURNING id").
+		WithArgs("testname#api", now, nil, nil, nil, nil, 7, "api", nil).
+		WillReturnError(errors.New("Connection refused"))
+	mock.ExpectRollback()
+
+	ftr := persistence.NewPostgreSQL(db)
+	id, err := ftr.SaveWithChildren(context.TODO(),
+		entity.FrequencyTable{Name: "testname", DateCreated: now, Values: map[string]int{"cars": 1}},
+		[]entity.FrequencyTable{{Name: "testname#api", Path: "api", DateCreated: now, Values: map[string]int{"cars": 1}}})
+
+	assert.Equal(t, int64(0), id)
+	assert.Equal(t, persistence.ErrUnexpected, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestUpdate_OnRelationalWhenMissingMandatoryValues_ShouldReturnError(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		assert.FailNow(t, fmt.Sprintf("Unexpected error mocking a database connection: %v", err))
+	}
+	defer db.Close()
+
+	ftr := persistence.NewPostgreSQL(db)
+	err = ftr.Update(context.TODO(), entity.FrequencyTable{ID: 1})
+
+	assert.Equal(t, persistence.ErrMissingFields, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestUpdate_OnRelationalWhenNonExistingFrequencyTable_ShouldReturnError(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		assert.FailNow(t, fmt.Sprintf("Unexpected error mocking a database connection: %v", err))
+	}
+	defer db.Close()
+
+	now := time.Now()
+	mock.ExpectBegin()
+	mock.ExpectExec("UPDATE frequency_table SET last_updated=(.+), revision_hash=(.+), revision_date=(.+), options=(.+), report=(.+) WHERE id=(.+)").
+		WithArgs(now, nil, nil, nil, nil, 1234567890).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectRollback()
+
+	ftr := persistence.NewPostgreSQL(db)
+	err = ftr.Update(context.TODO(), entity.FrequencyTable{
+		ID:          1234567890,
+		LastUpdated: now,
+		Values:      map[string]int{"cars": 1},
+	})
+
+	assert.Equal(t, persistence.ErrNoResults, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestUpdate_OnRelationalWhenErrorInsertingItems_ShouldRollbackAndReturnError(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		assert.FailNow(t, fmt.Sprintf("Unexpected error mocking a database connection: %v", err))
+	}
+	defer db.Close()
+
+	now := time.Now()
+	mock.ExpectBegin()
+	mock.ExpectExec("UPDATE frequency_table SET last_updated=(.+), revision_hash=(.+), revision_date=(.+), options=(.+), report=(.+) WHERE id=(.+)").
+		WithArgs(now, nil, nil, nil, nil, 1234567890).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("DELETE FROM frequency_table_item WHERE frequency_table_id=(.+)").
+		WithArgs(1234567890).
+		WillReturnResult(sqlmock.NewResult(0, 2))
+	mock.ExpectPrepare("INSERT INTO frequency_table_item(.+) VALUES(.+)")
+	mock.ExpectExec("INSERT INTO frequency_table_item(.+) VALUES(.+)").
+		WithArgs(1234567890, "cars", 1).
+		WillReturnError(errors.New("sql: invalid value"))
+	mock.ExpectRollback()
+
+	ftr := persistence.NewPostgreSQL(db)
+	err = ftr.Update(context.TODO(), entity.FrequencyTable{
+		ID:          1234567890,
+		LastUpdated: now,
+		Values:      map[string]int{"cars": 1},
+	})
+
+	assert.Equal(t, persistence.ErrUnexpected, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestUpdate_OnRelationalWhenValidFrequencyTable_ShouldReplaceItems(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		assert.FailNow(t, fmt.Sprintf("Unexpected error mocking a database connection: %v", err))
+	}
+	defer db.Close()
+
+	now := time.Now()
+	mock.ExpectBegin()
+	mock.ExpectExec("UPDATE frequency_table SET last_updated=(.+), revision_hash=(.+), revision_date=(.+), options=(.+), report=(.+) WHERE id=(.+)").
+		WithArgs(now, nil, nil, nil, nil, 1234567890).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("DELETE FROM frequency_table_item WHERE frequency_table_id=(.+)").
+		WithArgs(1234567890).
+		WillReturnResult(sqlmock.NewResult(0, 2))
+	mock.ExpectPrepare("INSERT INTO frequency_table_item(.+) VALUES(.+)")
+	mock.ExpectExec("INSERT INTO frequency_table_item(.+) VALUES(.+)").
+		WithArgs(1234567890, "cars", 1).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	ftr := persistence.NewPostgreSQL(db)
+	err = ftr.Update(context.TODO(), entity.FrequencyTable{
+		ID:          1234567890,
+		LastUpdated: now,
+		Values:      map[string]int{"cars": 1},
+	})
+
+	assert.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestTop_OnRelationalWhenNonExistingFrequencyTable_ShouldReturnError(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		assert.FailNow(t, fmt.Sprintf("Unexpected error mocking a database connection: %v", err))
+	}
+	defer db.Close()
+	mock.ExpectQuery("SELECT COALESCE\\(SUM\\(fti.times\\), 0\\) FROM frequency_table ft (.+) WHERE ft.id=\\$1 GROUP BY ft.id").
+		WithArgs(1).
+		WillReturnRows(mock.NewRows([]string{"sum"}))
+
+	ftr := persistence.NewPostgreSQL(db)
+	words, total, err := ftr.Top(context.TODO(), 1, 10, 0)
+
+	assert.Nil(t, words)
+	assert.Equal(t, 0, total)
+	assert.Equal(t, persistence.ErrNoResults, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestTop_OnRelationalWhenSQLErrorOnItems_ShouldReturnError(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		assert.FailNow(t, fmt.Sprintf("Unexpected error mocking a database connection: %v", err))
+	}
+	defer db.Close()
+	mock.ExpectQuery("SELECT COALESCE\\(SUM\\(fti.times\\), 0\\) FROM frequency_table ft (.+)").
+		WithArgs(1).
+		WillReturnRows(mock.NewRows([]string{"sum"}).AddRow(24))
+	mock.ExpectQuery("SELECT word, times FROM frequency_table_item (.+)").
+		WithArgs(1, 0, 10).
+		WillReturnError(errors.New("Connection refused"))
+
+	ftr := persistence.NewPostgreSQL(db)
+	words, total, err := ftr.Top(context.TODO(), 1, 10, 0)
+
+	assert.Nil(t, words)
+	assert.Equal(t, 0, total)
+	assert.Equal(t, persistence.ErrUnexpected, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestTop_OnRelationalWhenExistingFrequencyTable_ShouldReturnMostFrequentWords(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		assert.FailNow(t, fmt.Sprintf("Unexpected error mocking a database connection: %v", err))
+	}
+	defer db.Close()
+	mock.ExpectQuery("SELECT COALESCE\\(SUM\\(fti.times\\), 0\\) FROM frequency_table ft (.+)").
+		WithArgs(1).
+		WillReturnRows(mock.NewRows([]string{"sum"}).AddRow(24))
+	mock.ExpectQuery("SELECT word, times FROM frequency_table_item WHERE frequency_table_id=\\$1 AND times >= \\$2 "+
+		"ORDER BY times DESC, word LIMIT \\$3").
+		WithArgs(1, 2, 3).
+		WillReturnRows(mock.NewRows([]string{"word", "times"}).
+			AddRow("ctx", 8).
+			AddRow("err", 8).
+			AddRow("cfg", 5))
+
+	ftr := persistence.NewPostgreSQL(db)
+	words, total, err := ftr.Top(context.TODO(), 1, 3, 2)
+
+	assert.NoError(t, err)
+	assert.Equal(t, 24, total)
+	assert.Equal(t, []entity.WordCount{
+		{Word: "ctx", Count: 8},
+		{Word: "err", Count: 8},
+		{Word: "cfg", Count: 5},
+	}, words)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestFindWord_OnRelationalWhenSQLError_ShouldReturnError(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		assert.FailNow(t, fmt.Sprintf("Unexpected error mocking a database connection: %v", err))
+	}
+	defer db.Close()
+	mock.ExpectQuery("SELECT (.+) FROM frequency_table_item fti JOIN frequency_table ft (.+) WHERE fti.word=\\$1").
+		WithArgs("ctx").
+		WillReturnError(errors.New("Connection refused"))
+
+	ftr := persistence.NewPostgreSQL(db)
+	usages, err := ftr.FindWord(context.TODO(), "ctx")
+
+	assert.Nil(t, usages)
+	assert.Equal(t, persistence.ErrUnexpected, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestFindWord_OnRelationalWhenExistingWord_ShouldReturnUsages(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		assert.FailNow(t, fmt.Sprintf("Unexpected error mocking a database connection: %v", err))
+	}
+	defer db.Close()
+	rows := mock.NewRows([]string{"id", "name", "times", "sum"}).
+		AddRow(3, "https://github.com/eroatta/src-reader", 6, 8).
+		AddRow(1, "https://github.com/eroatta/freqtable", 2, 10)
+	mock.ExpectQuery("SELECT ft.id, ft.\"name\", fti.times, " +
+		"\\(SELECT SUM\\(t.times\\) FROM frequency_table_item t WHERE t.frequency_table_id = ft.id\\) " +
+		"FROM frequency_table_item fti JOIN frequency_table ft ON ft.id = fti.frequency_table_id " +
+		"WHERE fti.word=\\$1 ORDER BY fti.times DESC, ft.id").
+		WithArgs("ctx").
+		WillReturnRows(rows)
+
+	ftr := persistence.NewPostgreSQL(db)
+	usages, err := ftr.FindWord(context.TODO(), "ctx")
+
+	assert.NoError(t, err)
+	assert.Equal(t, []entity.WordUsage{
+		{FrequencyTableID: 3, FrequencyTableName: "https://github.com/eroatta/src-reader", Count: 6, Total: 8},
+		{FrequencyTableID: 1, FrequencyTableName: "https://github.com/eroatta/freqtable", Count: 2, Total: 10},
+	}, usages)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
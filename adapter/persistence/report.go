@@ -0,0 +1,95 @@
+package persistence
+
+import (
+	"database/sql"
+	"encoding/json"
+	"time"
+
+	"github.com/eroatta/freqtable/entity"
+)
+
+// reportRecord is the JSON representation of an entity.ExtractionReport stored on the database.
+type reportRecord struct {
+	Files         int                 `json:"files"`
+	GoFiles       int                 `json:"go_files"`
+	ExcludedFiles int                 `json:"excluded_files"`
+	ParsedFiles   int                 `json:"parsed_files"`
+	SkippedFiles  []skippedFileRecord `json:"skipped_files"`
+	Bytes         int64               `json:"bytes"`
+	CloneDuration time.Duration       `json:"clone_duration"`
+	ReadDuration  time.Duration       `json:"read_duration"`
+	ParseDuration time.Duration       `json:"parse_duration"`
+	MineDuration  time.Duration       `json:"mine_duration"`
+	TotalDuration time.Duration       `json:"total_duration"`
+}
+
+type skippedFileRecord struct {
+	Name   string `json:"name"`
+	Reason string `json:"reason"`
+	Line   int    `json:"line,omitempty"`
+	Column int    `json:"column,omitempty"`
+}
+
+// marshalReport converts the given report into its JSON representation. A missing report
+// is converted into a NULL value.
+func marshalReport(report *entity.ExtractionReport) (sql.NullString, error) {
+	if report == nil {
+		return sql.NullString{}, nil
+	}
+
+	record := reportRecord{
+		Files:         report.Files,
+		GoFiles:       report.GoFiles,
+		ExcludedFiles: report.ExcludedFiles,
+		ParsedFiles:   report.ParsedFiles,
+		SkippedFiles:  make([]skippedFileRecord, 0, len(report.SkippedFiles)),
+		Bytes:         report.Bytes,
+		CloneDuration: report.CloneDuration,
+		ReadDuration:  report.ReadDuration,
+		ParseDuration: report.ParseDuration,
+		MineDuration:  report.MineDuration,
+		TotalDuration: report.TotalDuration,
+	}
+	for _, f := range report.SkippedFiles {
+		record.SkippedFiles = append(record.SkippedFiles, skippedFileRecord(f))
+	}
+
+	bytes, err := json.Marshal(record)
+	if err != nil {
+		return sql.NullString{}, err
+	}
+
+	return sql.NullString{String: string(bytes), Valid: true}, nil
+}
+
+// unmarshalReport converts the given JSON representation into a report. A NULL value
+// is converted into a missing report.
+func unmarshalReport(value sql.NullString) (*entity.ExtractionReport, error) {
+	if !value.Valid {
+		return nil, nil
+	}
+
+	var record reportRecord
+	if err := json.Unmarshal([]byte(value.String), &record); err != nil {
+		return nil, err
+	}
+
+	report := entity.ExtractionReport{
+		Files:         record.Files,
+		GoFiles:       record.GoFiles,
+		ExcludedFiles: record.ExcludedFiles,
+		ParsedFiles:   record.ParsedFiles,
+		SkippedFiles:  make([]entity.SkippedFile, 0, len(record.SkippedFiles)),
+		Bytes:         record.Bytes,
+		CloneDuration: record.CloneDuration,
+		ReadDuration:  record.ReadDuration,
+		ParseDuration: record.ParseDuration,
+		MineDuration:  record.MineDuration,
+		TotalDuration: record.TotalDuration,
+	}
+	for _, f := range record.SkippedFiles {
+		report.SkippedFiles = append(report.SkippedFiles, entity.SkippedFile(f))
+	}
+
+	return &report, nil
+}
@@ -3,9 +3,14 @@ package rest
 import (
 	"fmt"
 	"net/http"
+	"path"
+	"strconv"
 	"strings"
 	"time"
 
+	"github.com/eroatta/freqtable/adapter/wordcount"
+	"github.com/eroatta/freqtable/entity"
+	"github.com/eroatta/freqtable/repository"
 	"github.com/eroatta/freqtable/usecase"
 	"github.com/eroatta/token/conserv"
 	"github.com/gin-gonic/gin"
@@ -15,23 +20,85 @@ import (
 
 // requestValidator represents a validator capable of analyzing the values of the incoming
 // request bodies.
-var requestValidator = validator.New()
+var requestValidator = newRequestValidator()
 
-// NewServer creates a new gingonic Engine that handles HTTP requests.
-func NewServer(ftUsecase usecase.CreateFrequencyTableUsecase) *gin.Engine {
+// newRequestValidator creates a validator, including the custom validations.
+func newRequestValidator() *validator.Validate {
+	v := validator.New()
+	v.RegisterValidation("glob", validGlob)
+	v.RegisterValidation("module", validModule)
+	v.RegisterStructValidation(validPostFrequencyTableCommand, postFrequencyTableCommand{})
+	v.RegisterStructValidation(validPostSeriesCommand, postSeriesCommand{})
+
+	return v
+}
+
+// validGlob checks if the field holds a well-formed glob pattern.
+func validGlob(fl validator.FieldLevel) bool {
+	_, err := path.Match(fl.Field().String(), "")
+	return err == nil
+}
+
+// validModule checks if the field holds a Go module path and version.
+func validModule(fl validator.FieldLevel) bool {
+	return wordcount.IsModule(fl.Field().String())
+}
+
+// validPostFrequencyTableCommand checks that either a repository or a module is given, since a
+// module is identified by its version and can't take a ref.
+func validPostFrequencyTableCommand(sl validator.StructLevel) {
+	cmd := sl.Current().Interface().(postFrequencyTableCommand)
+	if cmd.Module == "" {
+		return
+	}
+
+	if cmd.Repository != "" {
+		sl.ReportError(cmd.Module, "Module", "module", "excluded_with", "")
+	}
+	if cmd.Ref != "" {
+		sl.ReportError(cmd.Ref, "Ref", "ref", "excluded_with", "")
+	}
+}
+
+// NewServer creates a new gingonic Engine that handles HTTP requests with the given use cases.
+func NewServer(usecases usecase.Usecases) *gin.Engine {
 	internal := server{
-		createFreqTableUseCase: ftUsecase,
+		createFreqTableUseCase: usecases.Create,
+		mergeFreqTableUseCase:  usecases.Merge,
+		getFreqTableUseCase:    usecases.Get,
+		updateFreqTableUseCase: usecases.Update,
+		jobUseCase:             usecases.Job,
+		queryFreqTableUseCase:  usecases.Query,
+		seriesUseCase:          usecases.Series,
 	}
 
 	r := gin.Default()
 	r.GET("/ping", pingHandler)
 	r.POST("/frequency-tables", internal.postFrequencyTable)
+	r.POST("/frequency-tables/merge", internal.postFrequencyTableMerge)
+	r.POST("/frequency-tables/batch", internal.postFrequencyTableBatch)
+	r.GET("/frequency-tables", internal.getFrequencyTables)
+	r.GET("/frequency-tables/:id", internal.getFrequencyTable)
+	r.GET("/frequency-tables/:id/top", internal.getTopWords)
+	r.PUT("/frequency-tables/:id/refresh", internal.putFrequencyTableRefresh)
+	r.GET("/words/:word", internal.getWord)
+	r.POST("/jobs", internal.postJob)
+	r.GET("/jobs/:id", internal.getJob)
+	r.POST("/series", internal.postSeries)
+	r.GET("/series/:id", internal.getSeries)
+	r.GET("/series/:id/diff", internal.getSeriesDiff)
 
 	return r
 }
 
 type server struct {
 	createFreqTableUseCase usecase.CreateFrequencyTableUsecase
+	mergeFreqTableUseCase  usecase.MergeFrequencyTableUsecase
+	getFreqTableUseCase    usecase.GetFrequencyTableUsecase
+	updateFreqTableUseCase usecase.UpdateFrequencyTableUsecase
+	jobUseCase             usecase.ExtractionJobUsecase
+	queryFreqTableUseCase  usecase.QueryFrequencyTableUsecase
+	seriesUseCase          usecase.SeriesUsecase
 }
 
 func pingHandler(c *gin.Context) {
@@ -41,14 +108,138 @@ func pingHandler(c *gin.Context) {
 }
 
 type postFrequencyTableCommand struct {
-	Repository string `json:"repository" validate:"url"`
+	Repository string                    `json:"repository" validate:"required_without=Module,omitempty,url"`
+	Module     string                    `json:"module" validate:"required_without=Repository,omitempty,max=300,module"`
+	Ref        string                    `json:"ref" validate:"max=200"`
+	Options    *extractionOptionsCommand `json:"options"`
+}
+
+// source provides the requested repository URL or module path@version.
+func (cmd postFrequencyTableCommand) source() string {
+	if cmd.Module != "" {
+		return cmd.Module
+	}
+
+	return cmd.Repository
+}
+
+type extractionOptionsCommand struct {
+	Include       []string `json:"include" validate:"max=100,dive,required,max=200,glob"`
+	Exclude       []string `json:"exclude" validate:"max=100,dive,required,max=200,glob"`
+	SkipGenerated *bool    `json:"skip_generated"`
+	TestFiles     string   `json:"test_files" validate:"omitempty,oneof=include exclude only"`
+	Granularity   string   `json:"granularity" validate:"omitempty,oneof=repository module package"`
+}
+
+type postFrequencyTableMergeCommand struct {
+	Name            string  `json:"name" validate:"required,max=200"`
+	FrequencyTables []int64 `json:"frequency_tables" validate:"min=2,unique"`
+}
+
+type postFrequencyTableBatchCommand struct {
+	Repositories []string                  `json:"repositories" validate:"min=1,max=500,dive,url"`
+	Options      *extractionOptionsCommand `json:"options"`
+}
+
+type getFrequencyTableQuery struct {
+	Sort   string `form:"sort" validate:"omitempty,oneof=count word"`
+	Offset int    `form:"offset" validate:"min=0"`
+	Limit  int    `form:"limit" validate:"min=1,max=1000"`
+}
+
+type getFrequencyTablesQuery struct {
+	Name          string    `form:"name" validate:"max=200"`
+	CreatedAfter  time.Time `form:"created_after" time_format:"2006-01-02T15:04:05Z07:00"`
+	CreatedBefore time.Time `form:"created_before" time_format:"2006-01-02T15:04:05Z07:00"`
+	MinVocabulary int       `form:"min_vocabulary" validate:"min=0"`
+	ParentID      int64     `form:"parent_id" validate:"min=0"`
+	Cursor        int64     `form:"cursor" validate:"min=0"`
+	Limit         int       `form:"limit" validate:"min=0,max=500"`
 }
 
 type freqTableResponse struct {
-	ID          int64  `json:"id"`
-	Name        string `json:"name"`
-	DateCreated string `json:"date_created"`
-	LastUpdated string `json:"last_updated,omitempty"`
+	ID          int64             `json:"id"`
+	Name        string            `json:"name"`
+	Source      string            `json:"source,omitempty"`
+	ParentID    int64             `json:"parent_id,omitempty"`
+	Path        string            `json:"path,omitempty"`
+	DateCreated string            `json:"date_created"`
+	LastUpdated string            `json:"last_updated,omitempty"`
+	Revision    *revisionResponse `json:"revision,omitempty"`
+	Options     *optionsResponse  `json:"options,omitempty"`
+	Report      *reportResponse   `json:"report,omitempty"`
+}
+
+type revisionResponse struct {
+	Hash string `json:"hash"`
+	Date string `json:"date"`
+}
+
+type optionsResponse struct {
+	Ref           string   `json:"ref,omitempty"`
+	Include       []string `json:"include"`
+	Exclude       []string `json:"exclude"`
+	SkipGenerated bool     `json:"skip_generated"`
+	TestFiles     string   `json:"test_files"`
+	Granularity   string   `json:"granularity,omitempty"`
+}
+
+type reportResponse struct {
+	Files         int                   `json:"files"`
+	GoFiles       int                   `json:"go_files"`
+	ExcludedFiles int                   `json:"excluded_files"`
+	ParsedFiles   int                   `json:"parsed_files"`
+	SkippedFiles  []skippedFileResponse `json:"skipped_files"`
+	Bytes         int64                 `json:"bytes"`
+	DurationsMs   durationsResponse     `json:"durations_ms"`
+}
+
+type skippedFileResponse struct {
+	Name   string `json:"name"`
+	Reason string `json:"reason"`
+	Line   int    `json:"line,omitempty"`
+	Column int    `json:"column,omitempty"`
+}
+
+type durationsResponse struct {
+	Clone int64 `json:"clone"`
+	Read  int64 `json:"read"`
+	Parse int64 `json:"parse"`
+	Mine  int64 `json:"mine"`
+	Total int64 `json:"total"`
+}
+
+type freqTableSummaryResponse struct {
+	freqTableResponse
+	Vocabulary int `json:"vocabulary"`
+}
+
+type freqTableListResponse struct {
+	FrequencyTables []freqTableSummaryResponse `json:"frequency_tables"`
+	NextCursor      int64                      `json:"next_cursor,omitempty"`
+}
+
+type freqTableDetailResponse struct {
+	freqTableResponse
+	Vocabulary int                 `json:"vocabulary"`
+	Offset     int                 `json:"offset"`
+	Limit      int                 `json:"limit"`
+	Words      []wordCountResponse `json:"words"`
+}
+
+type wordCountResponse struct {
+	Word  string `json:"word"`
+	Count int    `json:"count"`
+}
+
+type batchResponse struct {
+	Results []batchResultResponse `json:"results"`
+}
+
+type batchResultResponse struct {
+	Repository     string             `json:"repository"`
+	FrequencyTable *freqTableResponse `json:"frequency_table,omitempty"`
+	Error          string             `json:"error,omitempty"`
 }
 
 type errorResponse struct {
@@ -57,6 +248,9 @@ type errorResponse struct {
 	Details []string `json:"details"`
 }
 
+// postFrequencyTable extracts the frequency table while the request waits, so the response holds
+// the created table. It's kept synchronous on purpose: POST /jobs takes the same body and answers
+// 202 Accepted right away, for extractions too long to wait for.
 func (s server) postFrequencyTable(ctx *gin.Context) {
 	var cmd postFrequencyTableCommand
 
@@ -72,20 +266,337 @@ func (s server) postFrequencyTable(ctx *gin.Context) {
 		return
 	}
 
-	ft, err := s.createFreqTableUseCase.Create(ctx, cmd.Repository)
+	// the gin context is never cancelled, so the request context is used to stop the extraction
+	// when the client disconnects
+	ft, err := s.createFreqTableUseCase.Create(ctx.Request.Context(), cmd.source(), newExtractionOptions(cmd.Ref, cmd.Options))
+	switch err {
+	case nil:
+		// continue
+	case repository.ErrDuplicated:
+		log.WithError(err).Debug(fmt.Sprintf("frequency table for %s already exists", cmd.source()))
+		setConflictResponse(ctx, err)
+		return
+	case repository.ErrUnknownRevision:
+		log.WithError(err).Debug(fmt.Sprintf("unknown ref or version for %s", cmd.source()))
+		setUnprocessableEntityResponse(ctx, err)
+		return
+	case repository.ErrLocalRepository:
+		log.WithError(err).Info(fmt.Sprintf("%s is on the local disk", cmd.source()))
+		setUnprocessableEntityResponse(ctx, err)
+		return
+	case repository.ErrRepositoryTooLarge:
+		log.WithError(err).Info(fmt.Sprintf("%s exceeds the size limits", cmd.source()))
+		setUnprocessableEntityResponse(ctx, err)
+		return
+	default:
+		log.WithError(err).Error("unexpected error")
+		setInternalErrorResponse(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, newFreqTableResponse(ft))
+}
+
+func (s server) postFrequencyTableMerge(ctx *gin.Context) {
+	var cmd postFrequencyTableMergeCommand
+
+	if err := ctx.ShouldBindJSON(&cmd); err != nil {
+		log.WithError(err).Debug("failed to bind JSON body")
+		setBadRequestOnBindingResponse(ctx, err)
+		return
+	}
+
+	if err := requestValidator.Struct(cmd); err != nil {
+		log.WithError(err).Debug("failed while validating the command")
+		setBadRequestOnValidationResponse(ctx, err)
+		return
+	}
+
+	ft, err := s.mergeFreqTableUseCase.Merge(ctx.Request.Context(), cmd.Name, cmd.FrequencyTables)
+	switch err {
+	case nil:
+		// continue
+	case repository.ErrNoResults:
+		log.WithError(err).Debug("missing frequency table")
+		setNotFoundResponse(ctx, err)
+		return
+	case repository.ErrDuplicated:
+		log.WithError(err).Debug(fmt.Sprintf("frequency table %s already exists", cmd.Name))
+		setConflictResponse(ctx, err)
+		return
+	default:
+		log.WithError(err).Error("unexpected error")
+		setInternalErrorResponse(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, newFreqTableResponse(ft))
+}
+
+func (s server) postFrequencyTableBatch(ctx *gin.Context) {
+	var cmd postFrequencyTableBatchCommand
+
+	if err := ctx.ShouldBindJSON(&cmd); err != nil {
+		log.WithError(err).Debug("failed to bind JSON body")
+		setBadRequestOnBindingResponse(ctx, err)
+		return
+	}
+
+	if err := requestValidator.Struct(cmd); err != nil {
+		log.WithError(err).Debug("failed while validating the command")
+		setBadRequestOnValidationResponse(ctx, err)
+		return
+	}
+
+	results := s.createFreqTableUseCase.CreateMultiple(ctx.Request.Context(), cmd.Repositories, newExtractionOptions("", cmd.Options))
+
+	response := batchResponse{
+		Results: make([]batchResultResponse, 0, len(results)),
+	}
+	for _, result := range results {
+		item := batchResultResponse{
+			Repository: result.URL,
+		}
+		if result.Error != nil {
+			log.WithError(result.Error).Error(fmt.Sprintf("error creating frequency table for %s", result.URL))
+			item.Error = result.Error.Error()
+		} else {
+			ftResponse := newFreqTableResponse(result.FrequencyTable)
+			item.FrequencyTable = &ftResponse
+		}
+		response.Results = append(response.Results, item)
+	}
+	ctx.JSON(http.StatusOK, response)
+}
+
+func (s server) getFrequencyTables(ctx *gin.Context) {
+	var query getFrequencyTablesQuery
+	if err := ctx.ShouldBindQuery(&query); err != nil {
+		log.WithError(err).Debug("failed to bind query parameters")
+		setBadRequestOnBindingResponse(ctx, err)
+		return
+	}
+
+	if err := requestValidator.Struct(query); err != nil {
+		log.WithError(err).Debug("failed while validating the query parameters")
+		setBadRequestOnValidationResponse(ctx, err)
+		return
+	}
+
+	summaries, next, err := s.getFreqTableUseCase.List(ctx, repository.FrequencyTableFilter{
+		Name:          query.Name,
+		CreatedAfter:  query.CreatedAfter,
+		CreatedBefore: query.CreatedBefore,
+		MinVocabulary: query.MinVocabulary,
+		ParentID:      query.ParentID,
+		Cursor:        query.Cursor,
+		Limit:         query.Limit,
+	})
 	if err != nil {
 		log.WithError(err).Error("unexpected error")
 		setInternalErrorResponse(ctx, err)
 		return
 	}
 
+	response := freqTableListResponse{
+		FrequencyTables: make([]freqTableSummaryResponse, 0, len(summaries)),
+		NextCursor:      next,
+	}
+	for _, summary := range summaries {
+		response.FrequencyTables = append(response.FrequencyTables, freqTableSummaryResponse{
+			freqTableResponse: newFreqTableResponse(entity.FrequencyTable{
+				ID:          summary.ID,
+				Name:        summary.Name,
+				DateCreated: summary.DateCreated,
+				LastUpdated: summary.LastUpdated,
+			}),
+			Vocabulary: summary.Vocabulary,
+		})
+	}
+	ctx.JSON(http.StatusOK, response)
+}
+
+func (s server) getFrequencyTable(ctx *gin.Context) {
+	id, err := strconv.ParseInt(ctx.Param("id"), 10, 64)
+	if err != nil {
+		log.WithError(err).Debug("failed to parse the frequency table ID")
+		setBadRequestOnParamResponse(ctx, "id", ctx.Param("id"))
+		return
+	}
+
+	query := getFrequencyTableQuery{
+		Sort:  string(usecase.OrderByCount),
+		Limit: 100,
+	}
+	if err := ctx.ShouldBindQuery(&query); err != nil {
+		log.WithError(err).Debug("failed to bind query parameters")
+		setBadRequestOnBindingResponse(ctx, err)
+		return
+	}
+
+	if err := requestValidator.Struct(query); err != nil {
+		log.WithError(err).Debug("failed while validating the query parameters")
+		setBadRequestOnValidationResponse(ctx, err)
+		return
+	}
+
+	ft, words, err := s.getFreqTableUseCase.Get(ctx, id, usecase.WordsQuery{
+		Order:  usecase.WordOrder(query.Sort),
+		Offset: query.Offset,
+		Limit:  query.Limit,
+	})
+	switch err {
+	case nil:
+		// continue
+	case repository.ErrNoResults:
+		log.WithError(err).Debug(fmt.Sprintf("missing frequency table %d", id))
+		setNotFoundResponse(ctx, err)
+		return
+	default:
+		log.WithError(err).Error("unexpected error")
+		setInternalErrorResponse(ctx, err)
+		return
+	}
+
+	response := freqTableDetailResponse{
+		freqTableResponse: newFreqTableResponse(ft),
+		Vocabulary:        len(ft.Values),
+		Offset:            query.Offset,
+		Limit:             query.Limit,
+		Words:             make([]wordCountResponse, 0, len(words)),
+	}
+	for _, wc := range words {
+		response.Words = append(response.Words, wordCountResponse{Word: wc.Word, Count: wc.Count})
+	}
+	ctx.JSON(http.StatusOK, response)
+}
+
+func (s server) putFrequencyTableRefresh(ctx *gin.Context) {
+	id, err := strconv.ParseInt(ctx.Param("id"), 10, 64)
+	if err != nil {
+		log.WithError(err).Debug("failed to parse the frequency table ID")
+		setBadRequestOnParamResponse(ctx, "id", ctx.Param("id"))
+		return
+	}
+
+	ft, err := s.updateFreqTableUseCase.Refresh(ctx.Request.Context(), id)
+	switch err {
+	case nil:
+		// continue
+	case repository.ErrNoResults:
+		log.WithError(err).Debug(fmt.Sprintf("missing frequency table %d", id))
+		setNotFoundResponse(ctx, err)
+		return
+	case repository.ErrUnknownRevision:
+		log.WithError(err).Debug(fmt.Sprintf("the ref of frequency table %d no longer exists", id))
+		setUnprocessableEntityResponse(ctx, err)
+		return
+	case repository.ErrLocalRepository:
+		log.WithError(err).Info(fmt.Sprintf("frequency table %d was extracted from the local disk", id))
+		setUnprocessableEntityResponse(ctx, err)
+		return
+	case repository.ErrNotExtracted:
+		log.WithError(err).Debug(fmt.Sprintf("frequency table %d has no source to extract", id))
+		setUnprocessableEntityResponse(ctx, err)
+		return
+	case repository.ErrRepositoryTooLarge:
+		log.WithError(err).Info(fmt.Sprintf("frequency table %d exceeds the size limits", id))
+		setUnprocessableEntityResponse(ctx, err)
+		return
+	default:
+		log.WithError(err).Error("unexpected error")
+		setInternalErrorResponse(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, newFreqTableResponse(ft))
+}
+
+func newFreqTableResponse(ft entity.FrequencyTable) freqTableResponse {
 	response := freqTableResponse{
 		ID:          ft.ID,
 		Name:        ft.Name,
+		Source:      ft.Source,
+		ParentID:    ft.ParentID,
+		Path:        ft.Path,
 		DateCreated: ft.DateCreated.Format(time.RFC3339),
-		LastUpdated: ft.LastUpdated.Format(time.RFC3339),
 	}
-	ctx.JSON(http.StatusCreated, response)
+	if !ft.LastUpdated.IsZero() {
+		response.LastUpdated = ft.LastUpdated.Format(time.RFC3339)
+	}
+	if ft.Revision != nil {
+		response.Revision = &revisionResponse{
+			Hash: ft.Revision.Hash,
+			Date: ft.Revision.Date.Format(time.RFC3339),
+		}
+	}
+	if ft.Options != nil {
+		response.Options = newOptionsResponse(*ft.Options)
+	}
+	if ft.Report != nil {
+		response.Report = newReportResponse(*ft.Report)
+	}
+
+	return response
+}
+
+func newOptionsResponse(options entity.ExtractionOptions) *optionsResponse {
+	response := optionsResponse{
+		Ref:           options.Ref,
+		Include:       options.Include,
+		Exclude:       options.Exclude,
+		SkipGenerated: options.SkipGenerated != nil && *options.SkipGenerated,
+		TestFiles:     string(options.TestFiles),
+		Granularity:   string(options.Granularity),
+	}
+	if response.Include == nil {
+		response.Include = []string{}
+	}
+	if response.Exclude == nil {
+		response.Exclude = []string{}
+	}
+
+	return &response
+}
+
+func newReportResponse(report entity.ExtractionReport) *reportResponse {
+	response := reportResponse{
+		Files:         report.Files,
+		GoFiles:       report.GoFiles,
+		ExcludedFiles: report.ExcludedFiles,
+		ParsedFiles:   report.ParsedFiles,
+		SkippedFiles:  make([]skippedFileResponse, 0, len(report.SkippedFiles)),
+		Bytes:         report.Bytes,
+		DurationsMs: durationsResponse{
+			Clone: report.CloneDuration.Milliseconds(),
+			Read:  report.ReadDuration.Milliseconds(),
+			Parse: report.ParseDuration.Milliseconds(),
+			Mine:  report.MineDuration.Milliseconds(),
+			Total: report.TotalDuration.Milliseconds(),
+		},
+	}
+	for _, f := range report.SkippedFiles {
+		response.SkippedFiles = append(response.SkippedFiles, skippedFileResponse(f))
+	}
+
+	return &response
+}
+
+// newExtractionOptions converts the requested ref and options, leaving unset every field that
+// wasn't given, so the defaults are applied.
+func newExtractionOptions(ref string, cmd *extractionOptionsCommand) entity.ExtractionOptions {
+	if cmd == nil {
+		return entity.ExtractionOptions{Ref: ref}
+	}
+
+	return entity.ExtractionOptions{
+		Ref:           ref,
+		Include:       cmd.Include,
+		Exclude:       cmd.Exclude,
+		SkipGenerated: cmd.SkipGenerated,
+		TestFiles:     entity.TestFilesMode(cmd.TestFiles),
+		Granularity:   entity.Granularity(cmd.Granularity),
+	}
 }
 
 func newBadRequestResponse() errorResponse {
@@ -120,6 +631,44 @@ func setBadRequestOnValidationResponse(ctx *gin.Context, err error) {
 	ctx.JSON(http.StatusBadRequest, errResponse)
 }
 
+func setBadRequestOnParamResponse(ctx *gin.Context, name string, value string) {
+	errResponse := newBadRequestResponse()
+	errResponse.Details = append(errResponse.Details,
+		fmt.Sprintf("invalid field '%s' with value %s", name, value))
+
+	ctx.JSON(http.StatusBadRequest, errResponse)
+}
+
+func setNotFoundResponse(ctx *gin.Context, err error) {
+	errResponse := errorResponse{
+		Name:    "not_found",
+		Message: "resource not found",
+		Details: []string{err.Error()},
+	}
+
+	ctx.JSON(http.StatusNotFound, errResponse)
+}
+
+func setConflictResponse(ctx *gin.Context, err error) {
+	errResponse := errorResponse{
+		Name:    "conflict",
+		Message: "resource already exists",
+		Details: []string{err.Error()},
+	}
+
+	ctx.JSON(http.StatusConflict, errResponse)
+}
+
+func setUnprocessableEntityResponse(ctx *gin.Context, err error) {
+	errResponse := errorResponse{
+		Name:    "unprocessable_entity",
+		Message: "the request can't be processed",
+		Details: []string{err.Error()},
+	}
+
+	ctx.JSON(http.StatusUnprocessableEntity, errResponse)
+}
+
 func setInternalErrorResponse(ctx *gin.Context, err error) {
 	errResponse := errorResponse{
 		Name:    "internal_error",
@@ -14,11 +14,13 @@ import (
 
 	"github.com/eroatta/freqtable/adapter/rest"
 	"github.com/eroatta/freqtable/entity"
+	"github.com/eroatta/freqtable/repository"
+	"github.com/eroatta/freqtable/usecase"
 	"github.com/stretchr/testify/assert"
 )
 
 func TestPOST_OnFrequencyTableCreationHandler_WithoutBody_ShouldReturnHTTP400(t *testing.T) {
-	router := rest.NewServer(nil)
+	router := rest.NewServer(usecase.Usecases{})
 
 	w := httptest.NewRecorder()
 	req, _ := http.NewRequest("POST", "/frequency-tables", nil)
@@ -35,7 +37,7 @@ func TestPOST_OnFrequencyTableCreationHandler_WithoutBody_ShouldReturnHTTP400(t
 }
 
 func TestPOST_OnFrequencyTableCreationHandler_WithEmptyBody_ShouldReturnHTTP400(t *testing.T) {
-	router := rest.NewServer(nil)
+	router := rest.NewServer(usecase.Usecases{})
 
 	w := httptest.NewRecorder()
 	body := `{}`
@@ -53,7 +55,7 @@ func TestPOST_OnFrequencyTableCreationHandler_WithEmptyBody_ShouldReturnHTTP400(
 }
 
 func TestPOST_OnFrequencyTableCreationHandler_WithWrongDataType_ShouldReturnHTTP400(t *testing.T) {
-	router := rest.NewServer(nil)
+	router := rest.NewServer(usecase.Usecases{})
 
 	w := httptest.NewRecorder()
 	body := `{
@@ -73,7 +75,7 @@ func TestPOST_OnFrequencyTableCreationHandler_WithWrongDataType_ShouldReturnHTTP
 }
 
 func TestPOST_OnFrequencyTableCreationHandler_WithInvalidRepository_ShouldReturnHTTP400(t *testing.T) {
-	router := rest.NewServer(nil)
+	router := rest.NewServer(usecase.Usecases{})
 
 	w := httptest.NewRecorder()
 	body := `{
@@ -92,10 +94,74 @@ func TestPOST_OnFrequencyTableCreationHandler_WithInvalidRepository_ShouldReturn
 	assert.Equal(t, "invalid field 'repository' with value ./github.com/eroatta/freqtable", response["details"].([]interface{})[0].(string))
 }
 
+func TestPOST_OnFrequencyTableCreationHandler_WithInvalidModule_ShouldReturnHTTP400(t *testing.T) {
+	tests := []struct {
+		name   string
+		body   string
+		detail string
+	}{
+		{"missing version", `{"module": "golang.org/x/tools"}`,
+			"invalid field 'module' with value golang.org/x/tools"},
+		{"with repository", `{"module": "golang.org/x/tools@v0.1.0", "repository": "https://go.googlesource.com/tools"}`,
+			"invalid field 'module' with value golang.org/x/tools@v0.1.0"},
+		{"with ref", `{"module": "golang.org/x/tools@v0.1.0", "ref": "master"}`,
+			"invalid field 'ref' with value master"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router := rest.NewServer(usecase.Usecases{})
+
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest("POST", "/frequency-tables", strings.NewReader(tt.body))
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, http.StatusBadRequest, w.Code)
+			var response map[string]interface{}
+			if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+				assert.FailNow(t, fmt.Sprintf("unexpected unmarshalling err: %v", err))
+			}
+			assert.Equal(t, "validation_error", response["name"])
+			assert.Equal(t, tt.detail, response["details"].([]interface{})[0].(string))
+		})
+	}
+}
+
+func TestPOST_OnFrequencyTableCreationHandler_WithModule_ShouldReturnHTTP201(t *testing.T) {
+	var url string
+	router := rest.NewServer(usecase.Usecases{
+		Create: mockUsecase{
+			ft: entity.FrequencyTable{
+				ID:          int64(123112312),
+				Name:        "golang.org/x/tools@v0.1.0",
+				DateCreated: time.Now(),
+			},
+			url: &url,
+		},
+	})
+
+	w := httptest.NewRecorder()
+	body := `{
+		"module": "golang.org/x/tools@v0.1.0"
+	}`
+	req, _ := http.NewRequest("POST", "/frequency-tables", strings.NewReader(body))
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusCreated, w.Code)
+	assert.Equal(t, "golang.org/x/tools@v0.1.0", url)
+	var response map[string]interface{}
+	if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+		assert.FailNow(t, fmt.Sprintf("unexpected unmarshalling err: %v", err))
+	}
+	assert.Equal(t, "golang.org/x/tools@v0.1.0", response["name"])
+}
+
 func TestPOST_OnFrequencyTableCreationHandler_WithInternalError_ShouldReturnHTTP500(t *testing.T) {
-	router := rest.NewServer(mockUsecase{
-		ft:  entity.FrequencyTable{},
-		err: errors.New("error cloning repository http://github.com/eroatta/freqtable"),
+	router := rest.NewServer(usecase.Usecases{
+		Create: mockUsecase{
+			ft:  entity.FrequencyTable{},
+			err: errors.New("error cloning repository http://github.com/eroatta/freqtable"),
+		},
 	})
 
 	w := httptest.NewRecorder()
@@ -115,6 +181,51 @@ func TestPOST_OnFrequencyTableCreationHandler_WithInternalError_ShouldReturnHTTP
 	assert.Equal(t, "error cloning repository http://github.com/eroatta/freqtable", response["details"].([]interface{})[0].(string))
 }
 
+func TestPOST_OnFrequencyTableCreationHandler_WithCancelledRequest_ShouldPropagateCancellation(t *testing.T) {
+	var ctxErr error
+	router := rest.NewServer(usecase.Usecases{
+		Create: mockUsecase{
+			ctxErr: &ctxErr,
+			err:    context.Canceled,
+		},
+	})
+
+	w := httptest.NewRecorder()
+	body := `{
+		"repository": "http://github.com/eroatta/freqtable"
+	}`
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	req, _ := http.NewRequestWithContext(ctx, "POST", "/frequency-tables", strings.NewReader(body))
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, context.Canceled, ctxErr)
+}
+
+func TestPOST_OnFrequencyTableCreationHandler_WithExistingRepository_ShouldReturnHTTP409(t *testing.T) {
+	router := rest.NewServer(usecase.Usecases{
+		Create: mockUsecase{
+			err: repository.ErrDuplicated,
+		},
+	})
+
+	w := httptest.NewRecorder()
+	body := `{
+		"repository": "http://github.com/eroatta/freqtable"
+	}`
+	req, _ := http.NewRequest("POST", "/frequency-tables", strings.NewReader(body))
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusConflict, w.Code)
+	var response map[string]interface{}
+	if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+		assert.FailNow(t, fmt.Sprintf("unexpected unmarshalling err: %v", err))
+	}
+	assert.Equal(t, "conflict", response["name"])
+	assert.Equal(t, "resource already exists", response["message"])
+	assert.Equal(t, repository.ErrDuplicated.Error(), response["details"].([]interface{})[0].(string))
+}
+
 func TestPOST_OnFrequencyTableCreationHandler_WithSuccess_ShouldReturnHTTP201(t *testing.T) {
 	now := time.Now()
 	ft := entity.FrequencyTable{
@@ -124,9 +235,11 @@ func TestPOST_OnFrequencyTableCreationHandler_WithSuccess_ShouldReturnHTTP201(t
 		LastUpdated: now,
 	}
 
-	router := rest.NewServer(mockUsecase{
-		ft:  ft,
-		err: nil,
+	router := rest.NewServer(usecase.Usecases{
+		Create: mockUsecase{
+			ft:  ft,
+			err: nil,
+		},
 	})
 
 	w := httptest.NewRecorder()
@@ -148,11 +261,903 @@ func TestPOST_OnFrequencyTableCreationHandler_WithSuccess_ShouldReturnHTTP201(t
 	assert.Equal(t, now.Format(time.RFC3339), response["last_updated"])
 }
 
+func TestPOST_OnFrequencyTableCreationHandler_WithExtractionReport_ShouldReturnReport(t *testing.T) {
+	ft := entity.FrequencyTable{
+		ID:          int64(123112312),
+		Name:        "http://github.com/eroatta/freqtable",
+		DateCreated: time.Now(),
+		Report: &entity.ExtractionReport{
+			Files:         4,
+			GoFiles:       3,
+			ExcludedFiles: 0,
+			ParsedFiles:   2,
+			SkippedFiles: []entity.SkippedFile{
+				{Name: "broken.go", Reason: "expected 'package', found 'EOF'", Line: 1, Column: 1},
+			},
+			Bytes:         2048,
+			CloneDuration: 1500 * time.Millisecond,
+			ReadDuration:  20 * time.Millisecond,
+			ParseDuration: 30 * time.Millisecond,
+			MineDuration:  40 * time.Millisecond,
+			TotalDuration: 1600 * time.Millisecond,
+		},
+	}
+
+	router := rest.NewServer(usecase.Usecases{
+		Create: mockUsecase{
+			ft: ft,
+		},
+	})
+
+	w := httptest.NewRecorder()
+	body := `{
+		"repository": "http://github.com/eroatta/freqtable"
+	}`
+	req, _ := http.NewRequest("POST", "/frequency-tables", strings.NewReader(body))
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusCreated, w.Code)
+	var response map[string]interface{}
+	if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+		assert.FailNow(t, fmt.Sprintf("unexpected unmarshalling err: %v", err))
+	}
+	assert.Equal(t, map[string]interface{}{
+		"files":          float64(4),
+		"go_files":       float64(3),
+		"excluded_files": float64(0),
+		"parsed_files":   float64(2),
+		"skipped_files": []interface{}{
+			map[string]interface{}{
+				"name":   "broken.go",
+				"reason": "expected 'package', found 'EOF'",
+				"line":   float64(1),
+				"column": float64(1),
+			},
+		},
+		"bytes": float64(2048),
+		"durations_ms": map[string]interface{}{
+			"clone": float64(1500),
+			"read":  float64(20),
+			"parse": float64(30),
+			"mine":  float64(40),
+			"total": float64(1600),
+		},
+	}, response["report"])
+}
+
+func TestPOST_OnFrequencyTableCreationHandler_WithInvalidOptions_ShouldReturnHTTP400(t *testing.T) {
+	tests := []struct {
+		name    string
+		options string
+		detail  string
+	}{
+		{"malformed pattern", `{"exclude": ["vendor", "[a-"]}`, "invalid field 'exclude[1]' with value [a-"},
+		{"empty pattern", `{"include": [""]}`, "invalid field 'include[0]' with value null or empty"},
+		{"unknown test files mode", `{"test_files": "some"}`, "invalid field 'test_files' with value some"},
+		{"unknown granularity", `{"granularity": "file"}`, "invalid field 'granularity' with value file"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router := rest.NewServer(usecase.Usecases{})
+
+			w := httptest.NewRecorder()
+			body := fmt.Sprintf(`{
+				"repository": "http://github.com/eroatta/freqtable",
+				"options": %s
+			}`, tt.options)
+			req, _ := http.NewRequest("POST", "/frequency-tables", strings.NewReader(body))
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, http.StatusBadRequest, w.Code)
+			var response map[string]interface{}
+			if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+				assert.FailNow(t, fmt.Sprintf("unexpected unmarshalling err: %v", err))
+			}
+			assert.Equal(t, "validation_error", response["name"])
+			assert.Equal(t, tt.detail, response["details"].([]interface{})[0].(string))
+		})
+	}
+}
+
+func TestPOST_OnFrequencyTableCreationHandler_WithUnknownRef_ShouldReturnHTTP422(t *testing.T) {
+	router := rest.NewServer(usecase.Usecases{
+		Create: mockUsecase{
+			err: repository.ErrUnknownRevision,
+		},
+	})
+
+	w := httptest.NewRecorder()
+	body := `{
+		"repository": "http://github.com/eroatta/freqtable",
+		"ref": "v9.9.9"
+	}`
+	req, _ := http.NewRequest("POST", "/frequency-tables", strings.NewReader(body))
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+	var response map[string]interface{}
+	if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+		assert.FailNow(t, fmt.Sprintf("unexpected unmarshalling err: %v", err))
+	}
+	assert.Equal(t, "unprocessable_entity", response["name"])
+	assert.Equal(t, "the request can't be processed", response["message"])
+	assert.Equal(t, repository.ErrUnknownRevision.Error(), response["details"].([]interface{})[0].(string))
+}
+
+func TestPOST_OnFrequencyTableCreationHandler_WithTooLargeRepository_ShouldReturnHTTP422(t *testing.T) {
+	router := rest.NewServer(usecase.Usecases{
+		Create: mockUsecase{
+			err: repository.ErrRepositoryTooLarge,
+		},
+	})
+
+	w := httptest.NewRecorder()
+	body := `{
+		"repository": "http://github.com/kubernetes/kubernetes"
+	}`
+	req, _ := http.NewRequest("POST", "/frequency-tables", strings.NewReader(body))
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+	var response map[string]interface{}
+	if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+		assert.FailNow(t, fmt.Sprintf("unexpected unmarshalling err: %v", err))
+	}
+	assert.Equal(t, "unprocessable_entity", response["name"])
+	assert.Equal(t, repository.ErrRepositoryTooLarge.Error(), response["details"].([]interface{})[0].(string))
+}
+
+func TestPOST_OnFrequencyTableCreationHandler_WithLocalRepository_ShouldReturnHTTP422(t *testing.T) {
+	router := rest.NewServer(usecase.Usecases{
+		Create: mockUsecase{
+			err: repository.ErrLocalRepository,
+		},
+	})
+
+	w := httptest.NewRecorder()
+	body := `{
+		"repository": "file:///etc"
+	}`
+	req, _ := http.NewRequest("POST", "/frequency-tables", strings.NewReader(body))
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+	var response map[string]interface{}
+	if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+		assert.FailNow(t, fmt.Sprintf("unexpected unmarshalling err: %v", err))
+	}
+	assert.Equal(t, "unprocessable_entity", response["name"])
+	assert.Equal(t, repository.ErrLocalRepository.Error(), response["details"].([]interface{})[0].(string))
+}
+
+func TestPOST_OnFrequencyTableCreationHandler_WithRef_ShouldPassRefAndReturnRevision(t *testing.T) {
+	date := time.Date(2020, time.March, 1, 10, 0, 0, 0, time.UTC)
+	var options entity.ExtractionOptions
+	router := rest.NewServer(usecase.Usecases{
+		Create: mockUsecase{
+			ft: entity.FrequencyTable{
+				ID:          int64(123112312),
+				Name:        "http://github.com/eroatta/freqtable",
+				DateCreated: time.Now(),
+				Revision:    &entity.Revision{Hash: "0a1b2c3d4e5f60718293a4b5c6d7e8f901234567", Date: date},
+				Options:     &entity.ExtractionOptions{Ref: "v1.0.0", TestFiles: entity.TestFilesInclude},
+			},
+			options: &options,
+		},
+	})
+
+	w := httptest.NewRecorder()
+	body := `{
+		"repository": "http://github.com/eroatta/freqtable",
+		"ref": "v1.0.0"
+	}`
+	req, _ := http.NewRequest("POST", "/frequency-tables", strings.NewReader(body))
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusCreated, w.Code)
+	assert.Equal(t, entity.ExtractionOptions{Ref: "v1.0.0"}, options)
+
+	var response map[string]interface{}
+	if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+		assert.FailNow(t, fmt.Sprintf("unexpected unmarshalling err: %v", err))
+	}
+	assert.Equal(t, map[string]interface{}{
+		"hash": "0a1b2c3d4e5f60718293a4b5c6d7e8f901234567",
+		"date": "2020-03-01T10:00:00Z",
+	}, response["revision"])
+	assert.Equal(t, "v1.0.0", response["options"].(map[string]interface{})["ref"])
+}
+
+func TestPOST_OnFrequencyTableCreationHandler_WithOptions_ShouldPassAndReturnOptions(t *testing.T) {
+	skip := true
+	var options entity.ExtractionOptions
+	router := rest.NewServer(usecase.Usecases{
+		Create: mockUsecase{
+			ft: entity.FrequencyTable{
+				ID:          int64(123112312),
+				Name:        "http://github.com/eroatta/freqtable",
+				DateCreated: time.Now(),
+				Options: &entity.ExtractionOptions{
+					Exclude:       []string{"vendor", "*.pb.go"},
+					SkipGenerated: &skip,
+					TestFiles:     entity.TestFilesExclude,
+				},
+			},
+			options: &options,
+		},
+	})
+
+	w := httptest.NewRecorder()
+	body := `{
+		"repository": "http://github.com/eroatta/freqtable",
+		"options": {
+			"exclude": ["vendor", "*.pb.go"],
+			"skip_generated": true,
+			"test_files": "exclude"
+		}
+	}`
+	req, _ := http.NewRequest("POST", "/frequency-tables", strings.NewReader(body))
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusCreated, w.Code)
+	assert.Equal(t, entity.ExtractionOptions{
+		Exclude:       []string{"vendor", "*.pb.go"},
+		SkipGenerated: &skip,
+		TestFiles:     entity.TestFilesExclude,
+	}, options)
+
+	var response map[string]interface{}
+	if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+		assert.FailNow(t, fmt.Sprintf("unexpected unmarshalling err: %v", err))
+	}
+	assert.Equal(t, map[string]interface{}{
+		"include":        []interface{}{},
+		"exclude":        []interface{}{"vendor", "*.pb.go"},
+		"skip_generated": true,
+		"test_files":     "exclude",
+	}, response["options"])
+}
+
+func TestPOST_OnFrequencyTableMergeHandler_WithLessThanTwoFrequencyTables_ShouldReturnHTTP400(t *testing.T) {
+	router := rest.NewServer(usecase.Usecases{})
+
+	w := httptest.NewRecorder()
+	body := `{
+		"name": "global",
+		"frequency_tables": [1]
+	}`
+	req, _ := http.NewRequest("POST", "/frequency-tables/merge", strings.NewReader(body))
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	var response map[string]interface{}
+	if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+		assert.FailNow(t, fmt.Sprintf("unexpected unmarshalling err: %v", err))
+	}
+	assert.Equal(t, "validation_error", response["name"])
+	assert.Equal(t, "missing or invalid data", response["message"])
+	assert.Equal(t, "invalid field 'frequency_tables' with value [1]", response["details"].([]interface{})[0].(string))
+}
+
+func TestPOST_OnFrequencyTableMergeHandler_WithMissingName_ShouldReturnHTTP400(t *testing.T) {
+	router := rest.NewServer(usecase.Usecases{})
+
+	w := httptest.NewRecorder()
+	body := `{
+		"frequency_tables": [1, 2]
+	}`
+	req, _ := http.NewRequest("POST", "/frequency-tables/merge", strings.NewReader(body))
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	var response map[string]interface{}
+	if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+		assert.FailNow(t, fmt.Sprintf("unexpected unmarshalling err: %v", err))
+	}
+	assert.Equal(t, "validation_error", response["name"])
+	assert.Equal(t, "invalid field 'name' with value null or empty", response["details"].([]interface{})[0].(string))
+}
+
+func TestPOST_OnFrequencyTableMergeHandler_WithNonExistingFrequencyTable_ShouldReturnHTTP404(t *testing.T) {
+	router := rest.NewServer(usecase.Usecases{
+		Merge: mockMergeUsecase{
+			err: repository.ErrNoResults,
+		},
+	})
+
+	w := httptest.NewRecorder()
+	body := `{
+		"name": "global",
+		"frequency_tables": [1, 2]
+	}`
+	req, _ := http.NewRequest("POST", "/frequency-tables/merge", strings.NewReader(body))
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	var response map[string]interface{}
+	if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+		assert.FailNow(t, fmt.Sprintf("unexpected unmarshalling err: %v", err))
+	}
+	assert.Equal(t, "not_found", response["name"])
+	assert.Equal(t, "resource not found", response["message"])
+	assert.Equal(t, repository.ErrNoResults.Error(), response["details"].([]interface{})[0].(string))
+}
+
+func TestPOST_OnFrequencyTableMergeHandler_WithExistingName_ShouldReturnHTTP409(t *testing.T) {
+	router := rest.NewServer(usecase.Usecases{
+		Merge: mockMergeUsecase{
+			err: repository.ErrDuplicated,
+		},
+	})
+
+	w := httptest.NewRecorder()
+	body := `{
+		"name": "global",
+		"frequency_tables": [1, 2]
+	}`
+	req, _ := http.NewRequest("POST", "/frequency-tables/merge", strings.NewReader(body))
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusConflict, w.Code)
+	var response map[string]interface{}
+	if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+		assert.FailNow(t, fmt.Sprintf("unexpected unmarshalling err: %v", err))
+	}
+	assert.Equal(t, "conflict", response["name"])
+	assert.Equal(t, repository.ErrDuplicated.Error(), response["details"].([]interface{})[0].(string))
+}
+
+func TestPOST_OnFrequencyTableMergeHandler_WithInternalError_ShouldReturnHTTP500(t *testing.T) {
+	router := rest.NewServer(usecase.Usecases{
+		Merge: mockMergeUsecase{
+			err: errors.New("error while persisting"),
+		},
+	})
+
+	w := httptest.NewRecorder()
+	body := `{
+		"name": "global",
+		"frequency_tables": [1, 2]
+	}`
+	req, _ := http.NewRequest("POST", "/frequency-tables/merge", strings.NewReader(body))
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+	var response map[string]interface{}
+	if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+		assert.FailNow(t, fmt.Sprintf("unexpected unmarshalling err: %v", err))
+	}
+	assert.Equal(t, "internal_error", response["name"])
+	assert.Equal(t, "error while persisting", response["details"].([]interface{})[0].(string))
+}
+
+func TestPOST_OnFrequencyTableMergeHandler_WithSuccess_ShouldReturnHTTP201(t *testing.T) {
+	now := time.Now()
+	router := rest.NewServer(usecase.Usecases{
+		Merge: mockMergeUsecase{
+			ft: entity.FrequencyTable{
+				ID:          int64(1234),
+				Name:        "global",
+				DateCreated: now,
+			},
+		},
+	})
+
+	w := httptest.NewRecorder()
+	body := `{
+		"name": "global",
+		"frequency_tables": [1, 2]
+	}`
+	req, _ := http.NewRequest("POST", "/frequency-tables/merge", strings.NewReader(body))
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusCreated, w.Code)
+	var response map[string]interface{}
+	if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+		assert.FailNow(t, fmt.Sprintf("unexpected unmarshalling err: %v", err))
+	}
+	responseId, _ := strconv.Atoi(fmt.Sprintf("%.0f", response["id"]))
+	assert.Equal(t, 1234, responseId)
+	assert.Equal(t, "global", response["name"])
+	assert.Equal(t, now.Format(time.RFC3339), response["date_created"])
+}
+
+func TestPOST_OnFrequencyTableBatchHandler_WithoutRepositories_ShouldReturnHTTP400(t *testing.T) {
+	router := rest.NewServer(usecase.Usecases{})
+
+	w := httptest.NewRecorder()
+	body := `{
+		"repositories": []
+	}`
+	req, _ := http.NewRequest("POST", "/frequency-tables/batch", strings.NewReader(body))
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	var response map[string]interface{}
+	if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+		assert.FailNow(t, fmt.Sprintf("unexpected unmarshalling err: %v", err))
+	}
+	assert.Equal(t, "validation_error", response["name"])
+	assert.Equal(t, "invalid field 'repositories' with value []", response["details"].([]interface{})[0].(string))
+}
+
+func TestPOST_OnFrequencyTableBatchHandler_WithInvalidRepository_ShouldReturnHTTP400(t *testing.T) {
+	router := rest.NewServer(usecase.Usecases{})
+
+	w := httptest.NewRecorder()
+	body := `{
+		"repositories": ["http://github.com/eroatta/freqtable", "./github.com/eroatta/token"]
+	}`
+	req, _ := http.NewRequest("POST", "/frequency-tables/batch", strings.NewReader(body))
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	var response map[string]interface{}
+	if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+		assert.FailNow(t, fmt.Sprintf("unexpected unmarshalling err: %v", err))
+	}
+	assert.Equal(t, "validation_error", response["name"])
+	assert.Equal(t, "invalid field 'repositories[1]' with value ./github.com/eroatta/token", response["details"].([]interface{})[0].(string))
+}
+
+func TestPOST_OnFrequencyTableBatchHandler_WithPartialFailure_ShouldReturnHTTP200(t *testing.T) {
+	now := time.Now()
+	router := rest.NewServer(usecase.Usecases{
+		Create: mockUsecase{
+			results: []usecase.CreationResult{
+				{
+					URL: "http://github.com/eroatta/freqtable",
+					FrequencyTable: entity.FrequencyTable{
+						ID:          int64(1234),
+						Name:        "http://github.com/eroatta/freqtable",
+						DateCreated: now,
+					},
+				},
+				{
+					URL:   "http://github.com/eroatta/unknown",
+					Error: errors.New("error cloning repository http://github.com/eroatta/unknown"),
+				},
+			},
+		},
+	})
+
+	w := httptest.NewRecorder()
+	body := `{
+		"repositories": ["http://github.com/eroatta/freqtable", "http://github.com/eroatta/unknown"]
+	}`
+	req, _ := http.NewRequest("POST", "/frequency-tables/batch", strings.NewReader(body))
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	var response map[string]interface{}
+	if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+		assert.FailNow(t, fmt.Sprintf("unexpected unmarshalling err: %v", err))
+	}
+	results := response["results"].([]interface{})
+	assert.Equal(t, 2, len(results))
+
+	first := results[0].(map[string]interface{})
+	assert.Equal(t, "http://github.com/eroatta/freqtable", first["repository"])
+	assert.Nil(t, first["error"])
+	ft := first["frequency_table"].(map[string]interface{})
+	responseId, _ := strconv.Atoi(fmt.Sprintf("%.0f", ft["id"]))
+	assert.Equal(t, 1234, responseId)
+
+	second := results[1].(map[string]interface{})
+	assert.Equal(t, "http://github.com/eroatta/unknown", second["repository"])
+	assert.Equal(t, "error cloning repository http://github.com/eroatta/unknown", second["error"])
+	assert.Nil(t, second["frequency_table"])
+}
+
+func TestGET_OnFrequencyTableHandler_WithInvalidID_ShouldReturnHTTP400(t *testing.T) {
+	router := rest.NewServer(usecase.Usecases{})
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/frequency-tables/abc", nil)
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	var response map[string]interface{}
+	if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+		assert.FailNow(t, fmt.Sprintf("unexpected unmarshalling err: %v", err))
+	}
+	assert.Equal(t, "validation_error", response["name"])
+	assert.Equal(t, "invalid field 'id' with value abc", response["details"].([]interface{})[0].(string))
+}
+
+func TestGET_OnFrequencyTableHandler_WithInvalidSort_ShouldReturnHTTP400(t *testing.T) {
+	router := rest.NewServer(usecase.Usecases{})
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/frequency-tables/1?sort=length", nil)
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	var response map[string]interface{}
+	if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+		assert.FailNow(t, fmt.Sprintf("unexpected unmarshalling err: %v", err))
+	}
+	assert.Equal(t, "validation_error", response["name"])
+	assert.Equal(t, "invalid field 'sort' with value length", response["details"].([]interface{})[0].(string))
+}
+
+func TestGET_OnFrequencyTableHandler_WithNonExistingFrequencyTable_ShouldReturnHTTP404(t *testing.T) {
+	router := rest.NewServer(usecase.Usecases{
+		Get: &mockGetUsecase{
+			err: repository.ErrNoResults,
+		},
+	})
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/frequency-tables/1", nil)
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	var response map[string]interface{}
+	if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+		assert.FailNow(t, fmt.Sprintf("unexpected unmarshalling err: %v", err))
+	}
+	assert.Equal(t, "not_found", response["name"])
+}
+
+func TestGET_OnFrequencyTableHandler_WithInternalError_ShouldReturnHTTP500(t *testing.T) {
+	router := rest.NewServer(usecase.Usecases{
+		Get: &mockGetUsecase{
+			err: errors.New("connection refused"),
+		},
+	})
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/frequency-tables/1", nil)
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+	var response map[string]interface{}
+	if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+		assert.FailNow(t, fmt.Sprintf("unexpected unmarshalling err: %v", err))
+	}
+	assert.Equal(t, "internal_error", response["name"])
+	assert.Equal(t, "connection refused", response["details"].([]interface{})[0].(string))
+}
+
+func TestGET_OnFrequencyTableHandler_WithSuccess_ShouldReturnHTTP200(t *testing.T) {
+	now := time.Now()
+	get := &mockGetUsecase{
+		ft: entity.FrequencyTable{
+			ID:          int64(1234),
+			Name:        "http://github.com/eroatta/freqtable",
+			DateCreated: now,
+			Values: map[string]int{
+				"table":     5,
+				"frequency": 2,
+				"word":      1,
+			},
+		},
+		words: []entity.WordCount{
+			{Word: "frequency", Count: 2},
+			{Word: "table", Count: 5},
+		},
+	}
+	router := rest.NewServer(usecase.Usecases{
+		Get: get,
+	})
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/frequency-tables/1234?sort=word&offset=0&limit=2", nil)
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, usecase.WordsQuery{Order: usecase.OrderByWord, Offset: 0, Limit: 2}, get.query)
+
+	var response map[string]interface{}
+	if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+		assert.FailNow(t, fmt.Sprintf("unexpected unmarshalling err: %v", err))
+	}
+	responseId, _ := strconv.Atoi(fmt.Sprintf("%.0f", response["id"]))
+	assert.Equal(t, 1234, responseId)
+	assert.Equal(t, "http://github.com/eroatta/freqtable", response["name"])
+	assert.Equal(t, now.Format(time.RFC3339), response["date_created"])
+	assert.Nil(t, response["last_updated"])
+	assert.Nil(t, response["report"])
+	assert.Nil(t, response["parent_id"])
+	assert.Equal(t, float64(3), response["vocabulary"])
+	assert.Equal(t, float64(0), response["offset"])
+	assert.Equal(t, float64(2), response["limit"])
+	assert.Equal(t, []interface{}{
+		map[string]interface{}{"word": "frequency", "count": float64(2)},
+		map[string]interface{}{"word": "table", "count": float64(5)},
+	}, response["words"])
+}
+
+func TestGET_OnFrequencyTablesHandler_WithInvalidDate_ShouldReturnHTTP400(t *testing.T) {
+	router := rest.NewServer(usecase.Usecases{})
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/frequency-tables?created_after=yesterday", nil)
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	var response map[string]interface{}
+	if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+		assert.FailNow(t, fmt.Sprintf("unexpected unmarshalling err: %v", err))
+	}
+	assert.Equal(t, "validation_error", response["name"])
+}
+
+func TestGET_OnFrequencyTablesHandler_WithNegativeMinVocabulary_ShouldReturnHTTP400(t *testing.T) {
+	router := rest.NewServer(usecase.Usecases{})
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/frequency-tables?min_vocabulary=-1", nil)
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	var response map[string]interface{}
+	if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+		assert.FailNow(t, fmt.Sprintf("unexpected unmarshalling err: %v", err))
+	}
+	assert.Equal(t, "validation_error", response["name"])
+	assert.Equal(t, "invalid field 'min_vocabulary' with value -1", response["details"].([]interface{})[0].(string))
+}
+
+func TestGET_OnFrequencyTablesHandler_WithInternalError_ShouldReturnHTTP500(t *testing.T) {
+	router := rest.NewServer(usecase.Usecases{
+		Get: &mockGetUsecase{
+			err: errors.New("connection refused"),
+		},
+	})
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/frequency-tables", nil)
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+}
+
+func TestGET_OnFrequencyTablesHandler_WithSuccess_ShouldReturnHTTP200(t *testing.T) {
+	now := time.Now().UTC()
+	get := &mockGetUsecase{
+		summaries: []entity.FrequencyTableSummary{
+			{ID: 1, Name: "http://github.com/eroatta/freqtable", DateCreated: now, Vocabulary: 120},
+			{ID: 5, Name: "http://github.com/eroatta/token", DateCreated: now, LastUpdated: now, Vocabulary: 80},
+		},
+		next: 5,
+	}
+	router := rest.NewServer(usecase.Usecases{
+		Get: get,
+	})
+
+	w := httptest.NewRecorder()
+	url := fmt.Sprintf("/frequency-tables?name=eroatta&created_after=%s&min_vocabulary=50&limit=2",
+		now.Add(-time.Hour).Format(time.RFC3339))
+	req, _ := http.NewRequest("GET", url, nil)
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "eroatta", get.filter.Name)
+	assert.Equal(t, now.Add(-time.Hour).Format(time.RFC3339), get.filter.CreatedAfter.Format(time.RFC3339))
+	assert.True(t, get.filter.CreatedBefore.IsZero())
+	assert.Equal(t, 50, get.filter.MinVocabulary)
+	assert.Equal(t, 2, get.filter.Limit)
+
+	var response map[string]interface{}
+	if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+		assert.FailNow(t, fmt.Sprintf("unexpected unmarshalling err: %v", err))
+	}
+	assert.Equal(t, float64(5), response["next_cursor"])
+	tables := response["frequency_tables"].([]interface{})
+	assert.Equal(t, 2, len(tables))
+	first := tables[0].(map[string]interface{})
+	assert.Equal(t, float64(1), first["id"])
+	assert.Equal(t, "http://github.com/eroatta/freqtable", first["name"])
+	assert.Equal(t, float64(120), first["vocabulary"])
+	assert.Nil(t, first["last_updated"])
+	second := tables[1].(map[string]interface{})
+	assert.Equal(t, now.Format(time.RFC3339), second["last_updated"])
+}
+
+func TestGET_OnFrequencyTablesHandler_WithParentID_ShouldListChildFrequencyTables(t *testing.T) {
+	get := &mockGetUsecase{
+		summaries: []entity.FrequencyTableSummary{
+			{ID: 2, Name: "http://github.com/eroatta/freqtable#entity", DateCreated: time.Now(), Vocabulary: 40},
+		},
+	}
+	router := rest.NewServer(usecase.Usecases{
+		Get: get,
+	})
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/frequency-tables?parent_id=1", nil)
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, int64(1), get.filter.ParentID)
+}
+
+func TestPUT_OnFrequencyTableRefreshHandler_WithInvalidID_ShouldReturnHTTP400(t *testing.T) {
+	router := rest.NewServer(usecase.Usecases{})
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("PUT", "/frequency-tables/abc/refresh", nil)
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestPUT_OnFrequencyTableRefreshHandler_WithNonExistingFrequencyTable_ShouldReturnHTTP404(t *testing.T) {
+	router := rest.NewServer(usecase.Usecases{
+		Update: mockUpdateUsecase{
+			err: repository.ErrNoResults,
+		},
+	})
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("PUT", "/frequency-tables/1/refresh", nil)
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestPUT_OnFrequencyTableRefreshHandler_WithInternalError_ShouldReturnHTTP500(t *testing.T) {
+	router := rest.NewServer(usecase.Usecases{
+		Update: mockUpdateUsecase{
+			err: errors.New("error cloning repository http://github.com/eroatta/freqtable"),
+		},
+	})
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("PUT", "/frequency-tables/1/refresh", nil)
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+	var response map[string]interface{}
+	if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+		assert.FailNow(t, fmt.Sprintf("unexpected unmarshalling err: %v", err))
+	}
+	assert.Equal(t, "error cloning repository http://github.com/eroatta/freqtable", response["details"].([]interface{})[0].(string))
+}
+
+func TestPUT_OnFrequencyTableRefreshHandler_WithUnknownRef_ShouldReturnHTTP422(t *testing.T) {
+	router := rest.NewServer(usecase.Usecases{
+		Update: mockUpdateUsecase{
+			err: repository.ErrUnknownRevision,
+		},
+	})
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("PUT", "/frequency-tables/1/refresh", nil)
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+}
+
+func TestPUT_OnFrequencyTableRefreshHandler_WithTooLargeRepository_ShouldReturnHTTP422(t *testing.T) {
+	router := rest.NewServer(usecase.Usecases{
+		Update: mockUpdateUsecase{
+			err: repository.ErrRepositoryTooLarge,
+		},
+	})
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("PUT", "/frequency-tables/1/refresh", nil)
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+}
+
+func TestPUT_OnFrequencyTableRefreshHandler_WithMergedFrequencyTable_ShouldReturnHTTP422(t *testing.T) {
+	router := rest.NewServer(usecase.Usecases{
+		Update: mockUpdateUsecase{
+			err: repository.ErrNotExtracted,
+		},
+	})
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("PUT", "/frequency-tables/1/refresh", nil)
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+}
+
+func TestPUT_OnFrequencyTableRefreshHandler_WithSuccess_ShouldReturnHTTP200(t *testing.T) {
+	now := time.Now()
+	router := rest.NewServer(usecase.Usecases{
+		Update: mockUpdateUsecase{
+			ft: entity.FrequencyTable{
+				ID:          int64(1),
+				Name:        "http://github.com/eroatta/freqtable@main",
+				Source:      "http://github.com/eroatta/freqtable",
+				DateCreated: now.Add(-time.Hour),
+				LastUpdated: now,
+			},
+		},
+	})
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("PUT", "/frequency-tables/1/refresh", nil)
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	var response map[string]interface{}
+	if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+		assert.FailNow(t, fmt.Sprintf("unexpected unmarshalling err: %v", err))
+	}
+	assert.Equal(t, float64(1), response["id"])
+	assert.Equal(t, "http://github.com/eroatta/freqtable", response["source"])
+	assert.Equal(t, now.Add(-time.Hour).Format(time.RFC3339), response["date_created"])
+	assert.Equal(t, now.Format(time.RFC3339), response["last_updated"])
+}
+
 type mockUsecase struct {
+	ft      entity.FrequencyTable
+	results []usecase.CreationResult
+	ctxErr  *error
+	url     *string
+	options *entity.ExtractionOptions
+	err     error
+}
+
+func (m mockUsecase) Create(ctx context.Context, url string, options entity.ExtractionOptions) (entity.FrequencyTable, error) {
+	if m.ctxErr != nil {
+		*m.ctxErr = ctx.Err()
+	}
+	if m.url != nil {
+		*m.url = url
+	}
+	if m.options != nil {
+		*m.options = options
+	}
+
+	return m.ft, m.err
+}
+
+func (m mockUsecase) CreateMultiple(ctx context.Context, urls []string, options entity.ExtractionOptions) []usecase.CreationResult {
+	if m.options != nil {
+		*m.options = options
+	}
+
+	return m.results
+}
+
+type mockMergeUsecase struct {
+	ft  entity.FrequencyTable
+	err error
+}
+
+func (m mockMergeUsecase) Merge(ctx context.Context, name string, ids []int64) (entity.FrequencyTable, error) {
+	return m.ft, m.err
+}
+
+type mockGetUsecase struct {
+	ft        entity.FrequencyTable
+	words     []entity.WordCount
+	query     usecase.WordsQuery
+	summaries []entity.FrequencyTableSummary
+	next      int64
+	filter    repository.FrequencyTableFilter
+	err       error
+}
+
+func (m *mockGetUsecase) Get(ctx context.Context, id int64, query usecase.WordsQuery) (entity.FrequencyTable, []entity.WordCount, error) {
+	m.query = query
+	return m.ft, m.words, m.err
+}
+
+func (m *mockGetUsecase) Find(ctx context.Context, id int64) (entity.FrequencyTable, error) {
+	return m.ft, m.err
+}
+
+func (m *mockGetUsecase) List(ctx context.Context, filter repository.FrequencyTableFilter) ([]entity.FrequencyTableSummary, int64, error) {
+	m.filter = filter
+	return m.summaries, m.next, m.err
+}
+
+type mockUpdateUsecase struct {
 	ft  entity.FrequencyTable
 	err error
 }
 
-func (m mockUsecase) Create(ctx context.Context, url string) (entity.FrequencyTable, error) {
+func (m mockUpdateUsecase) Refresh(ctx context.Context, id int64) (entity.FrequencyTable, error) {
 	return m.ft, m.err
 }
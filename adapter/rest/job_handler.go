@@ -0,0 +1,110 @@
+package rest
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/eroatta/freqtable/entity"
+	"github.com/eroatta/freqtable/repository"
+	"github.com/eroatta/freqtable/usecase"
+	"github.com/gin-gonic/gin"
+	log "github.com/sirupsen/logrus"
+)
+
+type jobResponse struct {
+	ID               int64  `json:"id"`
+	Repository       string `json:"repository"`
+	Status           string `json:"status"`
+	FrequencyTableID int64  `json:"frequency_table_id,omitempty"`
+	SeriesID         int64  `json:"series_id,omitempty"`
+	Error            string `json:"error,omitempty"`
+	DateCreated      string `json:"date_created"`
+	LastUpdated      string `json:"last_updated,omitempty"`
+}
+
+func (s server) postJob(ctx *gin.Context) {
+	var cmd postFrequencyTableCommand
+
+	if err := ctx.ShouldBindJSON(&cmd); err != nil {
+		log.WithError(err).Debug("failed to bind JSON body")
+		setBadRequestOnBindingResponse(ctx, err)
+		return
+	}
+
+	if err := requestValidator.Struct(cmd); err != nil {
+		log.WithError(err).Debug("failed while validating the command")
+		setBadRequestOnValidationResponse(ctx, err)
+		return
+	}
+
+	job, err := s.jobUseCase.Submit(ctx, cmd.source(), newExtractionOptions(cmd.Ref, cmd.Options))
+	switch err {
+	case nil:
+		// continue
+	case usecase.ErrJobQueueFull:
+		log.WithError(err).Warn("job queue is full")
+		setServiceUnavailableResponse(ctx, err)
+		return
+	default:
+		log.WithError(err).Error("unexpected error")
+		setInternalErrorResponse(ctx, err)
+		return
+	}
+
+	ctx.Header("Location", fmt.Sprintf("/jobs/%d", job.ID))
+	ctx.JSON(http.StatusAccepted, newJobResponse(job))
+}
+
+func (s server) getJob(ctx *gin.Context) {
+	id, err := strconv.ParseInt(ctx.Param("id"), 10, 64)
+	if err != nil {
+		log.WithError(err).Debug("failed to parse the job ID")
+		setBadRequestOnParamResponse(ctx, "id", ctx.Param("id"))
+		return
+	}
+
+	job, err := s.jobUseCase.Get(ctx, id)
+	switch err {
+	case nil:
+		// continue
+	case repository.ErrNoResults:
+		log.WithError(err).Debug(fmt.Sprintf("missing job %d", id))
+		setNotFoundResponse(ctx, err)
+		return
+	default:
+		log.WithError(err).Error("unexpected error")
+		setInternalErrorResponse(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, newJobResponse(job))
+}
+
+func newJobResponse(job entity.Job) jobResponse {
+	response := jobResponse{
+		ID:               job.ID,
+		Repository:       job.URL,
+		Status:           string(job.Status),
+		FrequencyTableID: job.FrequencyTableID,
+		SeriesID:         job.SeriesID,
+		Error:            job.Error,
+		DateCreated:      job.DateCreated.Format(time.RFC3339),
+	}
+	if !job.LastUpdated.IsZero() {
+		response.LastUpdated = job.LastUpdated.Format(time.RFC3339)
+	}
+
+	return response
+}
+
+func setServiceUnavailableResponse(ctx *gin.Context, err error) {
+	errResponse := errorResponse{
+		Name:    "service_unavailable",
+		Message: "service temporarily unavailable",
+		Details: []string{err.Error()},
+	}
+
+	ctx.JSON(http.StatusServiceUnavailable, errResponse)
+}
@@ -0,0 +1,216 @@
+package rest_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/eroatta/freqtable/adapter/rest"
+	"github.com/eroatta/freqtable/entity"
+	"github.com/eroatta/freqtable/repository"
+	"github.com/eroatta/freqtable/usecase"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPOST_OnJobHandler_WithInvalidRepository_ShouldReturnHTTP400(t *testing.T) {
+	router := rest.NewServer(usecase.Usecases{})
+
+	w := httptest.NewRecorder()
+	body := `{
+		"repository": "./github.com/eroatta/freqtable"
+	}`
+	req, _ := http.NewRequest("POST", "/jobs", strings.NewReader(body))
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	var response map[string]interface{}
+	if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+		assert.FailNow(t, fmt.Sprintf("unexpected unmarshalling err: %v", err))
+	}
+	assert.Equal(t, "validation_error", response["name"])
+	assert.Equal(t, "invalid field 'repository' with value ./github.com/eroatta/freqtable", response["details"].([]interface{})[0].(string))
+}
+
+func TestPOST_OnJobHandler_WithFullQueue_ShouldReturnHTTP503(t *testing.T) {
+	router := rest.NewServer(usecase.Usecases{
+		Job: mockJobUsecase{
+			err: usecase.ErrJobQueueFull,
+		},
+	})
+
+	w := httptest.NewRecorder()
+	body := `{
+		"repository": "http://github.com/eroatta/freqtable"
+	}`
+	req, _ := http.NewRequest("POST", "/jobs", strings.NewReader(body))
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+	var response map[string]interface{}
+	if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+		assert.FailNow(t, fmt.Sprintf("unexpected unmarshalling err: %v", err))
+	}
+	assert.Equal(t, "service_unavailable", response["name"])
+	assert.Equal(t, usecase.ErrJobQueueFull.Error(), response["details"].([]interface{})[0].(string))
+}
+
+func TestPOST_OnJobHandler_WithInternalError_ShouldReturnHTTP500(t *testing.T) {
+	router := rest.NewServer(usecase.Usecases{
+		Job: mockJobUsecase{
+			err: errors.New("error while persisting"),
+		},
+	})
+
+	w := httptest.NewRecorder()
+	body := `{
+		"repository": "http://github.com/eroatta/freqtable"
+	}`
+	req, _ := http.NewRequest("POST", "/jobs", strings.NewReader(body))
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+}
+
+func TestPOST_OnJobHandler_WithSuccess_ShouldReturnHTTP202(t *testing.T) {
+	now := time.Now()
+	router := rest.NewServer(usecase.Usecases{
+		Job: mockJobUsecase{
+			job: entity.Job{
+				ID:          int64(42),
+				URL:         "http://github.com/eroatta/freqtable",
+				Status:      entity.JobQueued,
+				DateCreated: now,
+			},
+		},
+	})
+
+	w := httptest.NewRecorder()
+	body := `{
+		"repository": "http://github.com/eroatta/freqtable"
+	}`
+	req, _ := http.NewRequest("POST", "/jobs", strings.NewReader(body))
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusAccepted, w.Code)
+	assert.Equal(t, "/jobs/42", w.Header().Get("Location"))
+	var response map[string]interface{}
+	if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+		assert.FailNow(t, fmt.Sprintf("unexpected unmarshalling err: %v", err))
+	}
+	assert.Equal(t, float64(42), response["id"])
+	assert.Equal(t, "http://github.com/eroatta/freqtable", response["repository"])
+	assert.Equal(t, "queued", response["status"])
+	assert.Equal(t, now.Format(time.RFC3339), response["date_created"])
+	assert.Nil(t, response["frequency_table_id"])
+	assert.Nil(t, response["error"])
+}
+
+func TestGET_OnJobHandler_WithInvalidID_ShouldReturnHTTP400(t *testing.T) {
+	router := rest.NewServer(usecase.Usecases{})
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/jobs/abc", nil)
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestGET_OnJobHandler_WithNonExistingJob_ShouldReturnHTTP404(t *testing.T) {
+	router := rest.NewServer(usecase.Usecases{
+		Job: mockJobUsecase{
+			err: repository.ErrNoResults,
+		},
+	})
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/jobs/42", nil)
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestGET_OnJobHandler_WithFailedJob_ShouldReturnHTTP200(t *testing.T) {
+	now := time.Now()
+	router := rest.NewServer(usecase.Usecases{
+		Job: mockJobUsecase{
+			job: entity.Job{
+				ID:          int64(42),
+				URL:         "http://github.com/eroatta/freqtable",
+				Status:      entity.JobFailed,
+				Error:       "error cloning repository",
+				DateCreated: now,
+				LastUpdated: now,
+			},
+		},
+	})
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/jobs/42", nil)
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	var response map[string]interface{}
+	if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+		assert.FailNow(t, fmt.Sprintf("unexpected unmarshalling err: %v", err))
+	}
+	assert.Equal(t, "failed", response["status"])
+	assert.Equal(t, "error cloning repository", response["error"])
+	assert.Equal(t, now.Format(time.RFC3339), response["last_updated"])
+}
+
+func TestGET_OnJobHandler_WithSucceededJob_ShouldReturnHTTP200(t *testing.T) {
+	now := time.Now()
+	router := rest.NewServer(usecase.Usecases{
+		Job: mockJobUsecase{
+			job: entity.Job{
+				ID:               int64(42),
+				URL:              "http://github.com/eroatta/freqtable",
+				Status:           entity.JobSucceeded,
+				FrequencyTableID: int64(1234),
+				DateCreated:      now,
+				LastUpdated:      now,
+			},
+		},
+	})
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/jobs/42", nil)
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	var response map[string]interface{}
+	if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+		assert.FailNow(t, fmt.Sprintf("unexpected unmarshalling err: %v", err))
+	}
+	assert.Equal(t, "succeeded", response["status"])
+	assert.Equal(t, float64(1234), response["frequency_table_id"])
+	assert.Nil(t, response["error"])
+}
+
+type mockJobUsecase struct {
+	job       entity.Job
+	selection *entity.RevisionSelection
+	err       error
+}
+
+func (m mockJobUsecase) Submit(ctx context.Context, url string, options entity.ExtractionOptions) (entity.Job, error) {
+	return m.job, m.err
+}
+
+func (m mockJobUsecase) SubmitSeries(ctx context.Context, url string, selection entity.RevisionSelection, options entity.ExtractionOptions) (entity.Job, error) {
+	if m.selection != nil {
+		*m.selection = selection
+	}
+
+	return m.job, m.err
+}
+
+func (m mockJobUsecase) Get(ctx context.Context, id int64) (entity.Job, error) {
+	return m.job, m.err
+}
@@ -0,0 +1,122 @@
+package rest
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/eroatta/freqtable/repository"
+	"github.com/gin-gonic/gin"
+	log "github.com/sirupsen/logrus"
+)
+
+type getTopWordsQuery struct {
+	N   int `form:"n" validate:"min=1,max=1000"`
+	Min int `form:"min" validate:"min=0"`
+}
+
+type topWordsResponse struct {
+	ID    int64                `json:"id"`
+	N     int                  `json:"n"`
+	Min   int                  `json:"min"`
+	Words []rankedWordResponse `json:"words"`
+}
+
+type rankedWordResponse struct {
+	Rank      int     `json:"rank"`
+	Word      string  `json:"word"`
+	Count     int     `json:"count"`
+	Frequency float64 `json:"frequency"`
+}
+
+type wordUsagesResponse struct {
+	Word            string              `json:"word"`
+	FrequencyTables []wordUsageResponse `json:"frequency_tables"`
+}
+
+type wordUsageResponse struct {
+	ID        int64   `json:"id"`
+	Name      string  `json:"name"`
+	Count     int     `json:"count"`
+	Frequency float64 `json:"frequency"`
+}
+
+func (s server) getTopWords(ctx *gin.Context) {
+	id, err := strconv.ParseInt(ctx.Param("id"), 10, 64)
+	if err != nil {
+		log.WithError(err).Debug("failed to parse the frequency table ID")
+		setBadRequestOnParamResponse(ctx, "id", ctx.Param("id"))
+		return
+	}
+
+	query := getTopWordsQuery{
+		N: 100,
+	}
+	if err := ctx.ShouldBindQuery(&query); err != nil {
+		log.WithError(err).Debug("failed to bind query parameters")
+		setBadRequestOnBindingResponse(ctx, err)
+		return
+	}
+
+	if err := requestValidator.Struct(query); err != nil {
+		log.WithError(err).Debug("failed while validating the query parameters")
+		setBadRequestOnValidationResponse(ctx, err)
+		return
+	}
+
+	words, err := s.queryFreqTableUseCase.Top(ctx, id, query.N, query.Min)
+	switch err {
+	case nil:
+		// continue
+	case repository.ErrNoResults:
+		log.WithError(err).Debug(fmt.Sprintf("missing frequency table %d", id))
+		setNotFoundResponse(ctx, err)
+		return
+	default:
+		log.WithError(err).Error("unexpected error")
+		setInternalErrorResponse(ctx, err)
+		return
+	}
+
+	response := topWordsResponse{
+		ID:    id,
+		N:     query.N,
+		Min:   query.Min,
+		Words: make([]rankedWordResponse, 0, len(words)),
+	}
+	for _, rw := range words {
+		response.Words = append(response.Words, rankedWordResponse{
+			Rank:      rw.Rank,
+			Word:      rw.Word,
+			Count:     rw.Count,
+			Frequency: rw.Frequency,
+		})
+	}
+	ctx.JSON(http.StatusOK, response)
+}
+
+func (s server) getWord(ctx *gin.Context) {
+	// words are stored lower-cased, as the miner counts them
+	word := strings.ToLower(ctx.Param("word"))
+	usages, err := s.queryFreqTableUseCase.Lookup(ctx, word)
+	if err != nil {
+		log.WithError(err).Error("unexpected error")
+		setInternalErrorResponse(ctx, err)
+		return
+	}
+
+	response := wordUsagesResponse{
+		Word:            word,
+		FrequencyTables: make([]wordUsageResponse, 0, len(usages)),
+	}
+	for _, usage := range usages {
+		response.FrequencyTables = append(response.FrequencyTables, wordUsageResponse{
+			ID:        usage.FrequencyTableID,
+			Name:      usage.FrequencyTableName,
+			Count:     usage.Count,
+			Frequency: usage.Frequency,
+		})
+	}
+	ctx.JSON(http.StatusOK, response)
+}
@@ -0,0 +1,216 @@
+package rest_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/eroatta/freqtable/adapter/rest"
+	"github.com/eroatta/freqtable/entity"
+	"github.com/eroatta/freqtable/repository"
+	"github.com/eroatta/freqtable/usecase"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGET_OnTopWordsHandler_WithInvalidID_ShouldReturnHTTP400(t *testing.T) {
+	router := rest.NewServer(usecase.Usecases{})
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/frequency-tables/abc/top", nil)
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestGET_OnTopWordsHandler_WithInvalidN_ShouldReturnHTTP400(t *testing.T) {
+	router := rest.NewServer(usecase.Usecases{})
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/frequency-tables/1/top?n=5000", nil)
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	var response map[string]interface{}
+	if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+		assert.FailNow(t, fmt.Sprintf("unexpected unmarshalling err: %v", err))
+	}
+	assert.Equal(t, "validation_error", response["name"])
+	assert.Equal(t, "invalid field 'n' with value 5000", response["details"].([]interface{})[0].(string))
+}
+
+func TestGET_OnTopWordsHandler_WithNonExistingFrequencyTable_ShouldReturnHTTP404(t *testing.T) {
+	router := rest.NewServer(usecase.Usecases{
+		Query: &mockQueryUsecase{
+			err: repository.ErrNoResults,
+		},
+	})
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/frequency-tables/1/top", nil)
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestGET_OnTopWordsHandler_WithInternalError_ShouldReturnHTTP500(t *testing.T) {
+	router := rest.NewServer(usecase.Usecases{
+		Query: &mockQueryUsecase{
+			err: errors.New("error while querying"),
+		},
+	})
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/frequency-tables/1/top", nil)
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+}
+
+func TestGET_OnTopWordsHandler_WithSuccess_ShouldReturnHTTP200(t *testing.T) {
+	uc := &mockQueryUsecase{
+		words: []entity.RankedWord{
+			{Rank: 1, Word: "ctx", Count: 8, Frequency: 0.2},
+			{Rank: 2, Word: "err", Count: 6, Frequency: 0.15},
+		},
+	}
+	router := rest.NewServer(usecase.Usecases{
+		Query: uc,
+	})
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/frequency-tables/1/top?n=2&min=5", nil)
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, 2, uc.n)
+	assert.Equal(t, 5, uc.min)
+	assert.JSONEq(t, `{
+		"id": 1,
+		"n": 2,
+		"min": 5,
+		"words": [
+			{"rank": 1, "word": "ctx", "count": 8, "frequency": 0.2},
+			{"rank": 2, "word": "err", "count": 6, "frequency": 0.15}
+		]
+	}`, w.Body.String())
+}
+
+func TestGET_OnTopWordsHandler_WithoutParameters_ShouldUseDefaults(t *testing.T) {
+	uc := &mockQueryUsecase{
+		words: []entity.RankedWord{},
+	}
+	router := rest.NewServer(usecase.Usecases{
+		Query: uc,
+	})
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/frequency-tables/1/top", nil)
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, 100, uc.n)
+	assert.Equal(t, 0, uc.min)
+}
+
+func TestGET_OnWordHandler_WithInternalError_ShouldReturnHTTP500(t *testing.T) {
+	router := rest.NewServer(usecase.Usecases{
+		Query: &mockQueryUsecase{
+			err: errors.New("error while querying"),
+		},
+	})
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/words/ctx", nil)
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+}
+
+func TestGET_OnWordHandler_WithUnknownWord_ShouldReturnHTTP200(t *testing.T) {
+	router := rest.NewServer(usecase.Usecases{
+		Query: &mockQueryUsecase{
+			usages: []entity.WordUsage{},
+		},
+	})
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/words/qwerty", nil)
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"word": "qwerty", "frequency_tables": []}`, w.Body.String())
+}
+
+func TestGET_OnWordHandler_WithSuccess_ShouldReturnHTTP200(t *testing.T) {
+	uc := &mockQueryUsecase{
+		usages: []entity.WordUsage{
+			{FrequencyTableID: 3, FrequencyTableName: "https://github.com/eroatta/src-reader", Count: 6, Total: 8, Frequency: 0.75},
+			{FrequencyTableID: 1, FrequencyTableName: "https://github.com/eroatta/freqtable", Count: 2, Total: 10, Frequency: 0.2},
+		},
+	}
+	router := rest.NewServer(usecase.Usecases{
+		Query: uc,
+	})
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/words/ctx", nil)
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "ctx", uc.word)
+	assert.JSONEq(t, `{
+		"word": "ctx",
+		"frequency_tables": [
+			{"id": 3, "name": "https://github.com/eroatta/src-reader", "count": 6, "frequency": 0.75},
+			{"id": 1, "name": "https://github.com/eroatta/freqtable", "count": 2, "frequency": 0.2}
+		]
+	}`, w.Body.String())
+}
+
+func TestGET_OnWordHandler_WithMixedCaseWord_ShouldLookUpLowerCaseWord(t *testing.T) {
+	uc := &mockQueryUsecase{
+		usages: []entity.WordUsage{
+			{FrequencyTableID: 1, FrequencyTableName: "https://github.com/eroatta/freqtable", Count: 2, Total: 10, Frequency: 0.2},
+		},
+	}
+	router := rest.NewServer(usecase.Usecases{
+		Query: uc,
+	})
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/words/FreqTable", nil)
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "freqtable", uc.word)
+	assert.JSONEq(t, `{
+		"word": "freqtable",
+		"frequency_tables": [
+			{"id": 1, "name": "https://github.com/eroatta/freqtable", "count": 2, "frequency": 0.2}
+		]
+	}`, w.Body.String())
+}
+
+type mockQueryUsecase struct {
+	words  []entity.RankedWord
+	usages []entity.WordUsage
+	n      int
+	min    int
+	word   string
+	err    error
+}
+
+func (m *mockQueryUsecase) Top(ctx context.Context, id int64, n int, min int) ([]entity.RankedWord, error) {
+	m.n = n
+	m.min = min
+	return m.words, m.err
+}
+
+func (m *mockQueryUsecase) Lookup(ctx context.Context, word string) ([]entity.WordUsage, error) {
+	m.word = word
+	return m.usages, m.err
+}
@@ -0,0 +1,233 @@
+package rest
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/eroatta/freqtable/entity"
+	"github.com/eroatta/freqtable/repository"
+	"github.com/eroatta/freqtable/usecase"
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator"
+	log "github.com/sirupsen/logrus"
+)
+
+type postSeriesCommand struct {
+	Repository string                    `json:"repository" validate:"required,url"`
+	Tags       bool                      `json:"tags"`
+	Every      int                       `json:"every" validate:"min=0"`
+	From       string                    `json:"from" validate:"max=200"`
+	To         string                    `json:"to" validate:"max=200"`
+	Options    *extractionOptionsCommand `json:"options"`
+}
+
+type getSeriesDiffQuery struct {
+	Limit int `form:"limit" validate:"min=0,max=1000"`
+}
+
+type seriesResponse struct {
+	ID          int64                 `json:"id"`
+	Repository  string                `json:"repository"`
+	Tags        bool                  `json:"tags,omitempty"`
+	Every       int                   `json:"every,omitempty"`
+	From        string                `json:"from,omitempty"`
+	To          string                `json:"to,omitempty"`
+	Options     *optionsResponse      `json:"options"`
+	DateCreated string                `json:"date_created"`
+	Entries     []seriesEntryResponse `json:"entries"`
+}
+
+type seriesEntryResponse struct {
+	FrequencyTableID int64            `json:"frequency_table_id"`
+	Ref              string           `json:"ref"`
+	Revision         revisionResponse `json:"revision"`
+}
+
+type seriesDiffResponse struct {
+	Changes []vocabularyChangeResponse `json:"changes"`
+}
+
+type vocabularyChangeResponse struct {
+	From           seriesEntryResponse  `json:"from"`
+	To             seriesEntryResponse  `json:"to"`
+	FromVocabulary int                  `json:"from_vocabulary"`
+	ToVocabulary   int                  `json:"to_vocabulary"`
+	Added          []wordCountResponse  `json:"added"`
+	Removed        []wordCountResponse  `json:"removed"`
+	Changed        []wordChangeResponse `json:"changed"`
+}
+
+type wordChangeResponse struct {
+	Word   string `json:"word"`
+	Before int    `json:"before"`
+	After  int    `json:"after"`
+}
+
+// validPostSeriesCommand checks that the revisions are selected either by tags or every N commits.
+func validPostSeriesCommand(sl validator.StructLevel) {
+	cmd := sl.Current().Interface().(postSeriesCommand)
+	if cmd.Tags && cmd.Every > 0 {
+		sl.ReportError(cmd.Every, "Every", "every", "excluded_with", "")
+	}
+	if !cmd.Tags && cmd.Every == 0 {
+		sl.ReportError(cmd.Every, "Every", "every", "required_without", "")
+	}
+}
+
+func (s server) postSeries(ctx *gin.Context) {
+	var cmd postSeriesCommand
+
+	if err := ctx.ShouldBindJSON(&cmd); err != nil {
+		log.WithError(err).Debug("failed to bind JSON body")
+		setBadRequestOnBindingResponse(ctx, err)
+		return
+	}
+
+	if err := requestValidator.Struct(cmd); err != nil {
+		log.WithError(err).Debug("failed while validating the command")
+		setBadRequestOnValidationResponse(ctx, err)
+		return
+	}
+
+	selection := entity.RevisionSelection{
+		Tags:  cmd.Tags,
+		Every: cmd.Every,
+		From:  cmd.From,
+		To:    cmd.To,
+	}
+	job, err := s.jobUseCase.SubmitSeries(ctx, cmd.Repository, selection, newExtractionOptions("", cmd.Options))
+	switch err {
+	case nil:
+		// continue
+	case usecase.ErrJobQueueFull:
+		log.WithError(err).Warn("job queue is full")
+		setServiceUnavailableResponse(ctx, err)
+		return
+	default:
+		log.WithError(err).Error("unexpected error")
+		setInternalErrorResponse(ctx, err)
+		return
+	}
+
+	ctx.Header("Location", fmt.Sprintf("/jobs/%d", job.ID))
+	ctx.JSON(http.StatusAccepted, newJobResponse(job))
+}
+
+func (s server) getSeries(ctx *gin.Context) {
+	id, err := strconv.ParseInt(ctx.Param("id"), 10, 64)
+	if err != nil {
+		log.WithError(err).Debug("failed to parse the series ID")
+		setBadRequestOnParamResponse(ctx, "id", ctx.Param("id"))
+		return
+	}
+
+	series, err := s.seriesUseCase.Get(ctx, id)
+	switch err {
+	case nil:
+		// continue
+	case repository.ErrNoResults:
+		log.WithError(err).Debug(fmt.Sprintf("missing series %d", id))
+		setNotFoundResponse(ctx, err)
+		return
+	default:
+		log.WithError(err).Error("unexpected error")
+		setInternalErrorResponse(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, newSeriesResponse(series))
+}
+
+func (s server) getSeriesDiff(ctx *gin.Context) {
+	id, err := strconv.ParseInt(ctx.Param("id"), 10, 64)
+	if err != nil {
+		log.WithError(err).Debug("failed to parse the series ID")
+		setBadRequestOnParamResponse(ctx, "id", ctx.Param("id"))
+		return
+	}
+
+	var query getSeriesDiffQuery
+	if err := ctx.ShouldBindQuery(&query); err != nil {
+		log.WithError(err).Debug("failed to bind query parameters")
+		setBadRequestOnBindingResponse(ctx, err)
+		return
+	}
+
+	if err := requestValidator.Struct(query); err != nil {
+		log.WithError(err).Debug("failed while validating the query parameters")
+		setBadRequestOnValidationResponse(ctx, err)
+		return
+	}
+
+	changes, err := s.seriesUseCase.Diff(ctx, id, query.Limit)
+	switch err {
+	case nil:
+		// continue
+	case repository.ErrNoResults:
+		log.WithError(err).Debug(fmt.Sprintf("missing series %d or any of its frequency tables", id))
+		setNotFoundResponse(ctx, err)
+		return
+	default:
+		log.WithError(err).Error("unexpected error")
+		setInternalErrorResponse(ctx, err)
+		return
+	}
+
+	response := seriesDiffResponse{
+		Changes: make([]vocabularyChangeResponse, 0, len(changes)),
+	}
+	for _, change := range changes {
+		item := vocabularyChangeResponse{
+			From:           newSeriesEntryResponse(change.From),
+			To:             newSeriesEntryResponse(change.To),
+			FromVocabulary: change.FromVocabulary,
+			ToVocabulary:   change.ToVocabulary,
+			Added:          make([]wordCountResponse, 0, len(change.Added)),
+			Removed:        make([]wordCountResponse, 0, len(change.Removed)),
+			Changed:        make([]wordChangeResponse, 0, len(change.Changed)),
+		}
+		for _, wc := range change.Added {
+			item.Added = append(item.Added, wordCountResponse{Word: wc.Word, Count: wc.Count})
+		}
+		for _, wc := range change.Removed {
+			item.Removed = append(item.Removed, wordCountResponse{Word: wc.Word, Count: wc.Count})
+		}
+		for _, wc := range change.Changed {
+			item.Changed = append(item.Changed, wordChangeResponse(wc))
+		}
+		response.Changes = append(response.Changes, item)
+	}
+	ctx.JSON(http.StatusOK, response)
+}
+
+func newSeriesResponse(series entity.Series) seriesResponse {
+	response := seriesResponse{
+		ID:          series.ID,
+		Repository:  series.Name,
+		Tags:        series.Selection.Tags,
+		Every:       series.Selection.Every,
+		From:        series.Selection.From,
+		To:          series.Selection.To,
+		Options:     newOptionsResponse(series.Options),
+		DateCreated: series.DateCreated.Format(time.RFC3339),
+		Entries:     make([]seriesEntryResponse, 0, len(series.Entries)),
+	}
+	for _, entry := range series.Entries {
+		response.Entries = append(response.Entries, newSeriesEntryResponse(entry))
+	}
+
+	return response
+}
+
+func newSeriesEntryResponse(entry entity.SeriesEntry) seriesEntryResponse {
+	return seriesEntryResponse{
+		FrequencyTableID: entry.FrequencyTableID,
+		Ref:              entry.Ref,
+		Revision: revisionResponse{
+			Hash: entry.Revision.Hash,
+			Date: entry.Revision.Date.Format(time.RFC3339),
+		},
+	}
+}
@@ -0,0 +1,217 @@
+package rest_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/eroatta/freqtable/adapter/rest"
+	"github.com/eroatta/freqtable/entity"
+	"github.com/eroatta/freqtable/repository"
+	"github.com/eroatta/freqtable/usecase"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPOST_OnSeriesHandler_WithInvalidSelection_ShouldReturnHTTP400(t *testing.T) {
+	tests := []struct {
+		name string
+		body string
+	}{
+		{"missing selection", `{"repository": "https://github.com/eroatta/freqtable"}`},
+		{"tags and every", `{"repository": "https://github.com/eroatta/freqtable", "tags": true, "every": 10}`},
+		{"negative every", `{"repository": "https://github.com/eroatta/freqtable", "every": -1}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router := rest.NewServer(usecase.Usecases{})
+
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest("POST", "/series", strings.NewReader(tt.body))
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, http.StatusBadRequest, w.Code)
+			var response map[string]interface{}
+			if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+				assert.FailNow(t, fmt.Sprintf("unexpected unmarshalling err: %v", err))
+			}
+			assert.Equal(t, "validation_error", response["name"])
+			assert.Contains(t, response["details"].([]interface{})[0].(string), "invalid field 'every'")
+		})
+	}
+}
+
+func TestPOST_OnSeriesHandler_WithFullQueue_ShouldReturnHTTP503(t *testing.T) {
+	router := rest.NewServer(usecase.Usecases{
+		Job: mockJobUsecase{err: usecase.ErrJobQueueFull},
+	})
+
+	w := httptest.NewRecorder()
+	body := `{"repository": "https://github.com/eroatta/freqtable", "tags": true}`
+	req, _ := http.NewRequest("POST", "/series", strings.NewReader(body))
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+}
+
+func TestPOST_OnSeriesHandler_WithSuccess_ShouldReturnHTTP202(t *testing.T) {
+	now := time.Now()
+	var selection entity.RevisionSelection
+	router := rest.NewServer(usecase.Usecases{
+		Job: mockJobUsecase{
+			job: entity.Job{
+				ID:          42,
+				URL:         "https://github.com/eroatta/freqtable",
+				Selection:   &entity.RevisionSelection{Every: 10, From: "v0.1.0"},
+				Status:      entity.JobQueued,
+				DateCreated: now,
+			},
+			selection: &selection,
+		},
+	})
+
+	w := httptest.NewRecorder()
+	body := `{"repository": "https://github.com/eroatta/freqtable", "every": 10, "from": "v0.1.0"}`
+	req, _ := http.NewRequest("POST", "/series", strings.NewReader(body))
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusAccepted, w.Code)
+	assert.Equal(t, "/jobs/42", w.Header().Get("Location"))
+	assert.Equal(t, entity.RevisionSelection{Every: 10, From: "v0.1.0"}, selection)
+	var response map[string]interface{}
+	if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+		assert.FailNow(t, fmt.Sprintf("unexpected unmarshalling err: %v", err))
+	}
+	assert.Equal(t, float64(42), response["id"])
+	assert.Equal(t, "queued", response["status"])
+	assert.Nil(t, response["series_id"])
+}
+
+func TestGET_OnSeriesHandler_WithSuccess_ShouldReturnHTTP200(t *testing.T) {
+	now := time.Now()
+	router := rest.NewServer(usecase.Usecases{
+		Series: mockSeriesUsecase{
+			series: entity.Series{
+				ID:          42,
+				Name:        "https://github.com/eroatta/freqtable",
+				Selection:   entity.RevisionSelection{Every: 10, From: "v0.1.0"},
+				DateCreated: now,
+				Entries: []entity.SeriesEntry{
+					{FrequencyTableID: 7, Ref: "v0.1.0", Revision: entity.Revision{Hash: "a1", Date: now}},
+				},
+			},
+		},
+	})
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/series/42", nil)
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	var response map[string]interface{}
+	if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+		assert.FailNow(t, fmt.Sprintf("unexpected unmarshalling err: %v", err))
+	}
+	assert.Equal(t, float64(42), response["id"])
+	assert.Equal(t, float64(10), response["every"])
+	assert.Nil(t, response["tags"])
+	entries := response["entries"].([]interface{})
+	assert.Equal(t, 1, len(entries))
+	entry := entries[0].(map[string]interface{})
+	assert.Equal(t, float64(7), entry["frequency_table_id"])
+	assert.Equal(t, "v0.1.0", entry["ref"])
+	assert.Equal(t, "a1", entry["revision"].(map[string]interface{})["hash"])
+}
+
+func TestGET_OnSeriesHandler_WithNonExistingSeries_ShouldReturnHTTP404(t *testing.T) {
+	for _, path := range []string{"/series/42", "/series/42/diff"} {
+		router := rest.NewServer(usecase.Usecases{
+			Series: mockSeriesUsecase{err: repository.ErrNoResults},
+		})
+
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", path, nil)
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	}
+}
+
+func TestGET_OnSeriesDiffHandler_WithInvalidLimit_ShouldReturnHTTP400(t *testing.T) {
+	router := rest.NewServer(usecase.Usecases{})
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/series/42/diff?limit=-1", nil)
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestGET_OnSeriesDiffHandler_WithSuccess_ShouldReturnHTTP200(t *testing.T) {
+	now := time.Now()
+	var limit int
+	router := rest.NewServer(usecase.Usecases{
+		Series: mockSeriesUsecase{
+			changes: []entity.VocabularyChange{
+				{
+					From:           entity.SeriesEntry{FrequencyTableID: 7, Ref: "v0.1.0", Revision: entity.Revision{Hash: "a1", Date: now}},
+					To:             entity.SeriesEntry{FrequencyTableID: 8, Ref: "v0.2.0", Revision: entity.Revision{Hash: "b2", Date: now}},
+					FromVocabulary: 3,
+					ToVocabulary:   4,
+					Added:          []entity.WordCount{{Word: "series", Count: 2}},
+					Removed:        []entity.WordCount{},
+					Changed:        []entity.WordChange{{Word: "table", Before: 2, After: 8}},
+				},
+			},
+			limit: &limit,
+		},
+	})
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/series/42/diff?limit=5", nil)
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, 5, limit)
+	var response map[string]interface{}
+	if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+		assert.FailNow(t, fmt.Sprintf("unexpected unmarshalling err: %v", err))
+	}
+	changes := response["changes"].([]interface{})
+	assert.Equal(t, 1, len(changes))
+	change := changes[0].(map[string]interface{})
+	assert.Equal(t, "v0.1.0", change["from"].(map[string]interface{})["ref"])
+	assert.Equal(t, "v0.2.0", change["to"].(map[string]interface{})["ref"])
+	assert.Equal(t, float64(4), change["to_vocabulary"])
+	assert.Equal(t, []interface{}{map[string]interface{}{"word": "series", "count": float64(2)}}, change["added"])
+	assert.Equal(t, []interface{}{}, change["removed"])
+	assert.Equal(t, []interface{}{map[string]interface{}{"word": "table", "before": float64(2), "after": float64(8)}}, change["changed"])
+}
+
+type mockSeriesUsecase struct {
+	series  entity.Series
+	changes []entity.VocabularyChange
+	limit   *int
+	err     error
+}
+
+func (m mockSeriesUsecase) Create(ctx context.Context, url string, selection entity.RevisionSelection, options entity.ExtractionOptions) (entity.Series, error) {
+	return m.series, m.err
+}
+
+func (m mockSeriesUsecase) Get(ctx context.Context, id int64) (entity.Series, error) {
+	return m.series, m.err
+}
+
+func (m mockSeriesUsecase) Diff(ctx context.Context, id int64, limit int) ([]entity.VocabularyChange, error) {
+	if m.limit != nil {
+		*m.limit = limit
+	}
+
+	return m.changes, m.err
+}
@@ -1,48 +1,169 @@
 package wordcount
 
-import "strings"
+import (
+	"context"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
 
-// clone retrieves the source code from a given URL. It access the repository, clones it,
-// filters non-go files and returns a channel of code.File elements.
-func clone(url string, cloner Cloner) (*Repository, <-chan File, error) {
-	repo, err := cloner.Clone(url)
+	"github.com/eroatta/freqtable/entity"
+)
+
+// scpLikeURL matches the scp-like syntax for Git repositories over SSH, such as
+// git@github.com:eroatta/freqtable.git, which isn't a local path despite lacking a scheme.
+var scpLikeURL = regexp.MustCompile(`^(?:[^@/]+@)?[^@/:]{2,}:`)
+
+// moduleVersion matches a Go module path and version, such as golang.org/x/tools@v0.1.0, where
+// the first path element is a domain name and the others hold the characters allowed on module paths.
+var moduleVersion = regexp.MustCompile(`^[a-z0-9-]+(\.[a-z0-9-]+)+(/[A-Za-z0-9._~-]+)*@(latest|v[0-9][A-Za-z0-9.+-]*)$`)
+
+// archiveExtensions holds the extensions of the supported archives.
+var archiveExtensions = []string{".tar.gz", ".tgz", ".zip"}
+
+// clone retrieves the source code from a given URL at the given ref. It access the repository, clones it,
+// filters non-go files and the files rejected by the filter, and returns a channel of code.File
+// elements, read by the given number of workers. It also returns a report with the number of
+// files, Go files and excluded files on the repository. The channel is closed when every file
+// was sent or the context is done. The returned Checkout must be closed once the files are consumed.
+// If more Go files than allowed by the limits are selected, ErrRepositoryTooLarge is returned, and
+// once a file exceeds the maximum file size, it's sent with that error, without reading it, and no
+// more files are read.
+// Each file is sent along with the module or package it belongs to, based on the given granularity.
+func clone(ctx context.Context, url string, ref string, cloner Cloner, filter fileFilter, granularity entity.Granularity, limits Limits, workers int) (Checkout, entity.ExtractionReport, <-chan File, error) {
+	checkout, err := cloner.Clone(ctx, url, ref)
 	if err != nil {
-		return nil, nil, err
+		return nil, entity.ExtractionReport{}, nil, err
 	}
 
-	files, err := cloner.Filenames()
+	files, err := checkout.Filenames(ctx)
 	if err != nil {
-		return nil, nil, err
+		checkout.Close()
+		return nil, entity.ExtractionReport{}, nil, err
+	}
+
+	report := entity.ExtractionReport{Files: len(files)}
+	names := make([]string, 0)
+	for _, f := range files {
+		if !isGoFile(f) {
+			continue
+		}
+
+		report.GoFiles++
+		if !filter.accepts(f) {
+			report.ExcludedFiles++
+			continue
+		}
+		names = append(names, f)
 	}
 
+	if limits.MaxGoFiles > 0 && len(names) > limits.MaxGoFiles {
+		checkout.Close()
+		return nil, entity.ExtractionReport{}, nil, ErrRepositoryTooLarge
+	}
+
+	grouper := newFileGrouper(granularity, files)
+
+	// stop is closed once a file exceeds the maximum file size
+	stop := make(chan struct{})
+	var stopOnce sync.Once
+
 	namesc := make(chan string)
 	go func() {
-		for _, f := range files {
-			if !strings.HasSuffix(f, ".go") {
-				continue
+		defer close(namesc)
+		for _, f := range names {
+			select {
+			case namesc <- f:
+			case <-stop:
+				return
+			case <-ctx.Done():
+				return
 			}
-
-			namesc <- f
 		}
-
-		close(namesc)
 	}()
 
 	filesc := make(chan File)
-	go func() {
-		for n := range namesc {
-			rawFile, err := cloner.File(n)
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for n := range namesc {
+				start := time.Now()
+				file := File{
+					Name:  n,
+					Group: grouper.group(n),
+				}
+				if limits.MaxFileSize > 0 {
+					// the size is checked before reading, so a large file is never loaded
+					file.Size, file.Error = checkout.FileSize(ctx, n)
+					if file.Error == nil && file.Size > limits.MaxFileSize {
+						file.Error = ErrRepositoryTooLarge
+						stopOnce.Do(func() { close(stop) })
+					}
+				}
+				if file.Error == nil {
+					file.Raw, file.Error = checkout.File(ctx, n)
+					file.Size = int64(len(file.Raw))
+				}
+				file.ReadTime = time.Since(start)
 
-			file := File{
-				Name:  n,
-				Raw:   rawFile,
-				Error: err,
+				select {
+				case filesc <- file:
+				case <-ctx.Done():
+					return
+				}
 			}
-			filesc <- file
-		}
+		}()
+	}
 
+	go func() {
+		wg.Wait()
 		close(filesc)
 	}()
 
-	return &repo, filesc, nil
+	return checkout, report, filesc, nil
+}
+
+// isLocal checks if the URL references a repository on disk, either by a file:// URL or a path.
+func isLocal(url string) bool {
+	if strings.HasPrefix(url, "file://") {
+		return true
+	}
+
+	return !strings.Contains(url, "://") && !scpLikeURL.MatchString(url)
+}
+
+// isArchive checks if the URL references a tar.gz or zip archive, based on its extension.
+func isArchive(url string) bool {
+	if i := strings.IndexAny(url, "?#"); i >= 0 {
+		url = url[:i]
+	}
+
+	url = strings.ToLower(url)
+	for _, ext := range archiveExtensions {
+		if strings.HasSuffix(url, ext) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// IsModule checks if the URL references a Go module by its path and version, such as
+// golang.org/x/tools@v0.1.0. As on the module paths checked by the go command, path elements
+// can't begin or end with a dot, so a path can't walk out of the module proxy.
+func IsModule(url string) bool {
+	if !moduleVersion.MatchString(url) {
+		return false
+	}
+
+	path := url[:strings.LastIndex(url, "@")]
+	for _, element := range strings.Split(path, "/") {
+		if strings.HasPrefix(element, ".") || strings.HasSuffix(element, ".") {
+			return false
+		}
+	}
+
+	return true
 }
@@ -1,9 +1,12 @@
 package wordcount
 
 import (
+	"context"
 	"errors"
+	"fmt"
 	"testing"
 
+	"github.com/eroatta/freqtable/entity"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -12,24 +15,27 @@ func TestClone_OnErrorWhileCloning_ShouldReturnError(t *testing.T) {
 		repoErr: errors.New("Error cloning remote repository git@github.com:test:repo"),
 	}
 
-	repo, filesc, err := clone("git@github.com:test:repo", cloner)
+	repo, _, filesc, err := clone(context.TODO(), "git@github.com:test:repo", "", cloner, fileFilter{}, "", Limits{}, 1)
 
 	assert.EqualError(t, err, "Error cloning remote repository git@github.com:test:repo")
 	assert.Nil(t, repo)
 	assert.Nil(t, filesc)
 }
 
-func TestClone_OnErrorWhileRetrievingFilenames_ShouldReturnError(t *testing.T) {
+func TestClone_OnErrorWhileRetrievingFilenames_ShouldReturnErrorAndCloseCheckout(t *testing.T) {
+	var closed bool
 	cloner := cloner{
 		repo:     Repository{Name: "github.com/test/repo"},
 		filesErr: errors.New("Error retriving list of file names for git@github.com:test:repo"),
+		closed:   &closed,
 	}
 
-	repo, filesc, err := clone("git@github.com:test:repo", cloner)
+	repo, _, filesc, err := clone(context.TODO(), "git@github.com:test:repo", "", cloner, fileFilter{}, "", Limits{}, 1)
 
 	assert.EqualError(t, err, "Error retriving list of file names for git@github.com:test:repo")
 	assert.Nil(t, repo)
 	assert.Nil(t, filesc)
+	assert.True(t, closed)
 }
 
 func TestClone_OnErrorWhileRetrievingFile_ShouldReturnFileContainingError(t *testing.T) {
@@ -39,7 +45,7 @@ func TestClone_OnErrorWhileRetrievingFile_ShouldReturnFileContainingError(t *tes
 		rawFilesErr: errors.New("Error retriving file main.go for git@github.com:test:repo"),
 	}
 
-	repo, filesc, err := clone("git@github.com:test:repo", cloner)
+	repo, _, filesc, err := clone(context.TODO(), "git@github.com:test:repo", "", cloner, fileFilter{}, "", Limits{}, 1)
 
 	assert.NotNil(t, repo)
 	assert.NotNil(t, filesc)
@@ -57,7 +63,7 @@ func TestClone_OnNonGolangRepository_ShouldReturnZeroFiles(t *testing.T) {
 		rawFiles: map[string][]byte{},
 	}
 
-	repo, filesc, err := clone("git@github.com:test:repo", cloner)
+	repo, _, filesc, err := clone(context.TODO(), "git@github.com:test:repo", "", cloner, fileFilter{}, "", Limits{}, 1)
 
 	assert.NotNil(t, repo)
 	assert.NotNil(t, filesc)
@@ -73,16 +79,19 @@ func TestClone_OnNonGolangRepository_ShouldReturnZeroFiles(t *testing.T) {
 func TestClone_OnGolangRepository_ShouldReturnAllGolangFiles(t *testing.T) {
 	cloner := cloner{
 		repo:  Repository{Name: "github.com/test/repo"},
-		files: []string{"main.go", "test.go"},
+		files: []string{"main.go", "test.go", "README.md"},
 		rawFiles: map[string][]byte{
 			"main.go": []byte("package main"),
 			"test.go": []byte("package test"),
 		},
 	}
 
-	repo, filesc, err := clone("git@github.com:test:repo", cloner)
+	repo, report, filesc, err := clone(context.TODO(), "git@github.com:test:repo", "", cloner, fileFilter{}, "", Limits{}, 1)
 
 	assert.NotNil(t, repo)
+	assert.Equal(t, 3, report.Files)
+	assert.Equal(t, 2, report.GoFiles)
+	assert.Equal(t, 0, report.ExcludedFiles)
 	assert.NotNil(t, filesc)
 	assert.Nil(t, err)
 
@@ -93,6 +102,7 @@ func TestClone_OnGolangRepository_ShouldReturnAllGolangFiles(t *testing.T) {
 
 	assert.Equal(t, 2, len(files))
 	assert.Equal(t, []byte("package main"), files["main.go"].Raw)
+	assert.Equal(t, int64(12), files["main.go"].Size)
 	assert.Equal(t, []byte("package test"), files["test.go"].Raw)
 }
 
@@ -103,17 +113,23 @@ type cloner struct {
 	filesErr    error
 	rawFiles    map[string][]byte
 	rawFilesErr error
+	sizes       map[string]int64
+	closed      *bool
 }
 
-func (c cloner) Clone(url string) (Repository, error) {
+func (c cloner) Clone(ctx context.Context, url string, ref string) (Checkout, error) {
 	if c.repoErr != nil {
-		return Repository{}, c.repoErr
+		return nil, c.repoErr
 	}
 
-	return c.repo, nil
+	return c, nil
 }
 
-func (c cloner) Filenames() ([]string, error) {
+func (c cloner) Repository() Repository {
+	return c.repo
+}
+
+func (c cloner) Filenames(ctx context.Context) ([]string, error) {
 	if c.filesErr != nil {
 		return []string{}, c.filesErr
 	}
@@ -121,10 +137,164 @@ func (c cloner) Filenames() ([]string, error) {
 	return c.files, nil
 }
 
-func (c cloner) File(name string) ([]byte, error) {
+func (c cloner) FileSize(ctx context.Context, name string) (int64, error) {
+	if size, ok := c.sizes[name]; ok {
+		return size, nil
+	}
+
+	return int64(len(c.rawFiles[name])), nil
+}
+
+func (c cloner) File(ctx context.Context, name string) ([]byte, error) {
 	if c.rawFilesErr != nil {
 		return []byte{}, c.rawFilesErr
 	}
 
 	return c.rawFiles[name], nil
 }
+
+func (c cloner) Close() error {
+	if c.closed != nil {
+		*c.closed = true
+	}
+
+	return nil
+}
+
+func TestClone_OnSeveralWorkers_ShouldReturnAllGolangFiles(t *testing.T) {
+	cloner := cloner{
+		repo:     Repository{Name: "github.com/test/repo"},
+		files:    []string{},
+		rawFiles: map[string][]byte{},
+	}
+	for i := 0; i < 20; i++ {
+		name := fmt.Sprintf("file%d.go", i)
+		cloner.files = append(cloner.files, name, fmt.Sprintf("file%d.md", i))
+		cloner.rawFiles[name] = []byte(fmt.Sprintf("package pkg%d", i))
+	}
+
+	_, _, filesc, err := clone(context.TODO(), "git@github.com:test:repo", "", cloner, fileFilter{}, "", Limits{}, 4)
+
+	assert.NoError(t, err)
+	files := make(map[string]File)
+	for file := range filesc {
+		files[file.Name] = file
+	}
+
+	assert.Equal(t, 20, len(files))
+	for i := 0; i < 20; i++ {
+		name := fmt.Sprintf("file%d.go", i)
+		assert.Equal(t, []byte(fmt.Sprintf("package pkg%d", i)), files[name].Raw)
+	}
+}
+
+func TestIsLocal_OnSeveralURLs_ShouldDetectLocalRepositories(t *testing.T) {
+	tests := []struct {
+		url  string
+		want bool
+	}{
+		{"https://github.com/eroatta/freqtable", false},
+		{"ssh://git@github.com/eroatta/freqtable.git", false},
+		{"git@github.com:eroatta/freqtable.git", false},
+		{"file:///home/ci/freqtable", true},
+		{"/home/ci/freqtable", true},
+		{"./freqtable", true},
+		{".", true},
+		{"C:/ci/freqtable", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.url, func(t *testing.T) {
+			assert.Equal(t, tt.want, isLocal(tt.url))
+		})
+	}
+}
+
+func TestIsArchive_OnSeveralURLs_ShouldDetectArchives(t *testing.T) {
+	tests := []struct {
+		url  string
+		want bool
+	}{
+		{"https://github.com/eroatta/freqtable", false},
+		{"https://github.com/eroatta/freqtable/archive/refs/tags/v1.0.0.tar.gz", true},
+		{"https://example.com/snapshots/freqtable.ZIP?token=secret", true},
+		{"/home/ci/freqtable.tgz", true},
+		{"file:///home/ci/freqtable", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.url, func(t *testing.T) {
+			assert.Equal(t, tt.want, isArchive(tt.url))
+		})
+	}
+}
+
+func TestIsModule_OnSeveralURLs_ShouldDetectModules(t *testing.T) {
+	tests := []struct {
+		url  string
+		want bool
+	}{
+		{"golang.org/x/tools@v0.1.0", true},
+		{"github.com/Azure/azure-sdk-for-go@v68.0.0+incompatible", true},
+		{"github.com/eroatta/freqtable@latest", true},
+		{"github.com/eroatta/freqtable", false},
+		{"https://github.com/eroatta/freqtable@v1.0.0", false},
+		{"git@github.com:eroatta/freqtable.git", false},
+		{"./freqtable@v1.0.0", false},
+		{"freqtable@v1.0.0", false},
+		{"golang.org/x/../../../etc@v1.0.0", false},
+		{"golang.org/x/./tools@v1.0.0", false},
+		{"golang.org/x//tools@v1.0.0", false},
+		{"golang.org/x/tools/@v1.0.0", false},
+		{"golang.org/x/.hidden@v1.0.0", false},
+		{"golang.org/x/tools@v1.0.0/../..", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.url, func(t *testing.T) {
+			assert.Equal(t, tt.want, IsModule(tt.url))
+		})
+	}
+}
+
+func TestClone_OnTooManyGoFiles_ShouldReturnErrorAndCloseCheckout(t *testing.T) {
+	var closed bool
+	cloner := cloner{
+		repo:   Repository{Name: "github.com/test/repo"},
+		files:  []string{"main.go", "util.go", "util_test.go", "README.md"},
+		closed: &closed,
+	}
+	filter := newFileFilter(entity.ExtractionOptions{TestFiles: entity.TestFilesExclude})
+
+	_, _, _, err := clone(context.TODO(), "git@github.com:test:repo", "", cloner, filter, "", Limits{MaxGoFiles: 2}, 1)
+	assert.NoError(t, err)
+
+	repo, _, filesc, err := clone(context.TODO(), "git@github.com:test:repo", "", cloner, fileFilter{}, "", Limits{MaxGoFiles: 2}, 1)
+
+	assert.Equal(t, ErrRepositoryTooLarge, err)
+	assert.Nil(t, repo)
+	assert.Nil(t, filesc)
+	assert.True(t, closed)
+}
+
+func TestClone_OnFileOverMaxFileSize_ShouldReturnFileContainingErrorAndStopReading(t *testing.T) {
+	cloner := cloner{
+		repo:     Repository{Name: "github.com/test/repo"},
+		files:    []string{"large.go", "main.go", "util.go"},
+		rawFiles: map[string][]byte{"main.go": []byte("package main")},
+		sizes:    map[string]int64{"large.go": 1 << 30},
+	}
+
+	_, _, filesc, err := clone(context.TODO(), "git@github.com:test:repo", "", cloner, fileFilter{}, "", Limits{MaxFileSize: 12}, 1)
+
+	assert.NoError(t, err)
+	var files []File
+	for file := range filesc {
+		files = append(files, file)
+	}
+	assert.True(t, len(files) < 3)
+	assert.Equal(t, "large.go", files[0].Name)
+	assert.Equal(t, ErrRepositoryTooLarge, files[0].Error)
+	assert.Equal(t, int64(1<<30), files[0].Size)
+	assert.Nil(t, files[0].Raw)
+}
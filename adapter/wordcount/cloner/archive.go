@@ -0,0 +1,260 @@
+package cloner
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path"
+	"strings"
+
+	"github.com/eroatta/freqtable/adapter/wordcount"
+	log "github.com/sirupsen/logrus"
+	"gopkg.in/src-d/go-billy.v4"
+	"gopkg.in/src-d/go-billy.v4/memfs"
+	"gopkg.in/src-d/go-billy.v4/util"
+)
+
+var (
+	// errUnknownArchive indicates that the content isn't a tar.gz or zip archive.
+	errUnknownArchive = errors.New("unsupported archive, use tar.gz or zip")
+
+	gzipMagic = []byte{0x1f, 0x8b}
+	zipMagic  = []byte("PK\x03\x04")
+)
+
+// NewArchive creates and initializes a new cloner for tar.gz and zip archives. An archive larger
+// than the given size in bytes is rejected. A size of zero disables the limit.
+func NewArchive(maxSize int64) wordcount.Cloner {
+	return archiveCloner{
+		client:  http.DefaultClient,
+		maxSize: maxSize,
+	}
+}
+
+type archiveCloner struct {
+	client  *http.Client
+	maxSize int64
+}
+
+// Clone downloads the archive from an HTTP URL, or reads it from a local path or file:// URL, and
+// extracts the files that can be mined on memory. If every file is under the same top-level directory, as on GitHub release
+// tarballs, that directory is removed from the file names. Since archives don't keep any history,
+// a given ref is reported as unknown. If the archive, or its extracted files, exceed the maximum
+// size, the download or the extraction is aborted with ErrRepositoryTooLarge.
+func (c archiveCloner) Clone(ctx context.Context, url string, ref string) (wordcount.Checkout, error) {
+	log.WithField("repository", url).Info("extracting archive")
+	if ref != "" {
+		return nil, wordcount.ErrUnknownRevision
+	}
+
+	content, err := c.fetch(ctx, url)
+	if err != nil {
+		return nil, err
+	}
+
+	fs := memfs.New()
+	w := newEntryWriter(fs, c.maxSize)
+	switch {
+	case bytes.HasPrefix(content, gzipMagic):
+		err = extractTarGz(ctx, w, content)
+	case bytes.HasPrefix(content, zipMagic):
+		err = extractZip(ctx, w, content, "")
+	default:
+		err = errUnknownArchive
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	root, err := commonRoot(fs)
+	if err != nil {
+		return nil, err
+	}
+
+	return &dirCheckout{name: url, fs: root}, nil
+}
+
+// fetch retrieves the archive contents.
+func (c archiveCloner) fetch(ctx context.Context, url string) ([]byte, error) {
+	if !strings.HasPrefix(url, "http://") && !strings.HasPrefix(url, "https://") {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		file, err := os.Open(strings.TrimPrefix(url, fileScheme))
+		if err != nil {
+			return nil, err
+		}
+		defer file.Close()
+
+		return readLimited(file, c.maxSize)
+	}
+
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := c.client.Do(req.WithContext(ctx))
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status %s while downloading %s", resp.Status, url)
+	}
+
+	return readLimited(resp.Body, c.maxSize)
+}
+
+// readLimited reads the whole content, failing with ErrRepositoryTooLarge once it exceeds the
+// maximum size. A size of zero disables the limit.
+func readLimited(r io.Reader, maxSize int64) ([]byte, error) {
+	if maxSize <= 0 {
+		return ioutil.ReadAll(r)
+	}
+
+	content, err := ioutil.ReadAll(io.LimitReader(r, maxSize+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(content)) > maxSize {
+		return nil, wordcount.ErrRepositoryTooLarge
+	}
+
+	return content, nil
+}
+
+// extractTarGz writes the regular files of a tar.gz archive through the given writer.
+func extractTarGz(ctx context.Context, w *entryWriter, content []byte) error {
+	gz, err := gzip.NewReader(bytes.NewReader(content))
+	if err != nil {
+		return err
+	}
+	defer gz.Close()
+
+	tr := tar.NewReader(gz)
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		header, err := tr.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		if !header.FileInfo().Mode().IsRegular() {
+			continue
+		}
+
+		if err := w.write(header.Name, tr); err != nil {
+			return err
+		}
+	}
+}
+
+// extractZip writes the files under the given prefix through the given writer, removing the prefix
+// from their names. The entries outside the prefix are ignored.
+func extractZip(ctx context.Context, w *entryWriter, content []byte, prefix string) error {
+	zr, err := zip.NewReader(bytes.NewReader(content), int64(len(content)))
+	if err != nil {
+		return err
+	}
+
+	for _, f := range zr.File {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		if !f.Mode().IsRegular() || !strings.HasPrefix(f.Name, prefix) {
+			continue
+		}
+
+		rc, err := f.Open()
+		if err != nil {
+			return err
+		}
+		err = w.write(strings.TrimPrefix(f.Name, prefix), rc)
+		rc.Close()
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// entryWriter writes the entries of an archive that can be mined, the Go files and the go.mod
+// files, on a filesystem. The size of the decompressed entries is counted against the maximum
+// size, so a small archive can't expand into more memory than a large one.
+type entryWriter struct {
+	fs      billy.Filesystem
+	maxSize int64
+	size    int64
+}
+
+// newEntryWriter creates a writer on the given filesystem. A size of zero disables the limit.
+func newEntryWriter(fs billy.Filesystem, maxSize int64) *entryWriter {
+	return &entryWriter{
+		fs:      fs,
+		maxSize: maxSize,
+	}
+}
+
+// write writes an archive entry on the filesystem, unless it can't be mined. Its name is cleaned,
+// so entries pointing outside the archive root are kept under it. Once the decompressed entries
+// exceed the maximum size, ErrRepositoryTooLarge is returned without reading any further.
+func (w *entryWriter) write(name string, r io.Reader) error {
+	name = strings.TrimPrefix(path.Clean("/"+name), "/")
+	if name == "" || !isMinable(name) {
+		return nil
+	}
+
+	if w.maxSize > 0 {
+		r = io.LimitReader(r, w.maxSize-w.size+1)
+	}
+	data, err := ioutil.ReadAll(r)
+	if err != nil {
+		return err
+	}
+
+	w.size += int64(len(data))
+	if w.maxSize > 0 && w.size > w.maxSize {
+		return wordcount.ErrRepositoryTooLarge
+	}
+
+	return util.WriteFile(w.fs, name, data, os.FileMode(0644))
+}
+
+// isMinable checks if the file is a Go file, or a go.mod file, which delimits the modules.
+func isMinable(name string) bool {
+	return strings.HasSuffix(name, ".go") || path.Base(name) == "go.mod"
+}
+
+// commonRoot returns the filesystem rooted at the only top-level directory, if every entry is
+// under it, or the given filesystem otherwise.
+func commonRoot(fs billy.Filesystem) (billy.Filesystem, error) {
+	entries, err := fs.ReadDir(rootDir)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(entries) != 1 || !entries[0].IsDir() {
+		return fs, nil
+	}
+
+	return fs.Chroot(entries[0].Name())
+}
@@ -0,0 +1,170 @@
+package cloner
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/eroatta/freqtable/adapter/wordcount"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClone_OnArchiveClonerWithRef_ShouldReturnError(t *testing.T) {
+	checkout, err := NewArchive(0).Clone(context.TODO(), "https://example.com/freqtable.tar.gz", "v1.0.0")
+
+	assert.Nil(t, checkout)
+	assert.Equal(t, wordcount.ErrUnknownRevision, err)
+}
+
+func TestClone_OnArchiveClonerWithFailedDownload_ShouldReturnError(t *testing.T) {
+	server := httptest.NewServer(http.NotFoundHandler())
+	defer server.Close()
+
+	checkout, err := NewArchive(0).Clone(context.TODO(), server.URL+"/freqtable.tar.gz", "")
+
+	assert.Nil(t, checkout)
+	assert.EqualError(t, err, fmt.Sprintf("unexpected status 404 Not Found while downloading %s/freqtable.tar.gz", server.URL))
+}
+
+func TestClone_OnArchiveClonerWithUnknownFormat_ShouldReturnError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("package main"))
+	}))
+	defer server.Close()
+
+	checkout, err := NewArchive(0).Clone(context.TODO(), server.URL+"/freqtable.zip", "")
+
+	assert.Nil(t, checkout)
+	assert.Equal(t, errUnknownArchive, err)
+}
+
+func TestClone_OnArchiveClonerWithDownloadedArchive_ShouldReturnArchivedFiles(t *testing.T) {
+	files := map[string]string{
+		"freqtable-1.0.0/main.go":            "package main",
+		"freqtable-1.0.0/pkg/util/util.go":   "package util",
+		"../../freqtable-1.0.0/evil.go":      "package evil",
+		"freqtable-1.0.0/testdata/README.md": "# testdata",
+		"freqtable-1.0.0/go.mod":             "module github.com/eroatta/freqtable",
+	}
+	tests := []struct {
+		name    string
+		archive []byte
+	}{
+		{"tar.gz", newTarGz(t, files)},
+		{"zip", newZip(t, files)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Write(tt.archive)
+			}))
+			defer server.Close()
+			url := server.URL + "/freqtable." + tt.name
+
+			checkout, err := NewArchive(0).Clone(context.TODO(), url, "")
+
+			assert.NoError(t, err)
+			assert.Equal(t, wordcount.Repository{Name: url, URL: url}, checkout.Repository())
+			names, err := checkout.Filenames(context.TODO())
+			assert.NoError(t, err)
+			assert.ElementsMatch(t, []string{"main.go", "pkg/util/util.go", "evil.go", "go.mod"}, names)
+			content, err := checkout.File(context.TODO(), "pkg/util/util.go")
+			assert.NoError(t, err)
+			assert.Equal(t, "package util", string(content))
+		})
+	}
+}
+
+func TestClone_OnArchiveClonerWithArchiveExpandingOverMaxSize_ShouldReturnError(t *testing.T) {
+	// repeated content is highly compressed, so the archive is far smaller than its files
+	files := map[string]string{
+		"main.go":  "package main",
+		"large.go": "package main\n" + strings.Repeat("// comment\n", 64*1024),
+	}
+	tests := []struct {
+		name    string
+		archive []byte
+	}{
+		{"tar.gz", newTarGz(t, files)},
+		{"zip", newZip(t, files)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.True(t, len(tt.archive) < 64*1024)
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Write(tt.archive)
+			}))
+			defer server.Close()
+
+			checkout, err := NewArchive(64*1024).Clone(context.TODO(), server.URL+"/freqtable."+tt.name, "")
+
+			assert.Nil(t, checkout)
+			assert.Equal(t, wordcount.ErrRepositoryTooLarge, err)
+		})
+	}
+}
+
+func TestClone_OnArchiveClonerWithLocalArchive_ShouldReturnArchivedFiles(t *testing.T) {
+	dir := tempDir(t)
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "freqtable.tar.gz")
+	archive := newTarGz(t, map[string]string{"main.go": "package main", "cmd/tool/main.go": "package main"})
+	if err := ioutil.WriteFile(path, archive, 0644); err != nil {
+		assert.FailNow(t, fmt.Sprintf("unexpected error writing file: %v", err))
+	}
+
+	for _, url := range []string{path, "file://" + path} {
+		checkout, err := NewArchive(0).Clone(context.TODO(), url, "")
+
+		assert.NoError(t, err)
+		names, err := checkout.Filenames(context.TODO())
+		assert.NoError(t, err)
+		assert.ElementsMatch(t, []string{"main.go", "cmd/tool/main.go"}, names)
+	}
+}
+
+// newTarGz creates a tar.gz archive containing the given files.
+func newTarGz(t *testing.T, files map[string]string) []byte {
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gz)
+	for name, content := range files {
+		header := &tar.Header{Name: name, Mode: 0644, Size: int64(len(content)), Typeflag: tar.TypeReg}
+		if err := tw.WriteHeader(header); err != nil {
+			assert.FailNow(t, fmt.Sprintf("unexpected error writing header: %v", err))
+		}
+		tw.Write([]byte(content))
+	}
+	tw.Close()
+	gz.Close()
+
+	return buf.Bytes()
+}
+
+// newZip creates a zip archive containing the given files.
+func newZip(t *testing.T, files map[string]string) []byte {
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	for name, content := range files {
+		w, err := zw.Create(name)
+		if err != nil {
+			assert.FailNow(t, fmt.Sprintf("unexpected error creating entry: %v", err))
+		}
+		w.Write([]byte(content))
+	}
+	zw.Close()
+
+	return buf.Bytes()
+}
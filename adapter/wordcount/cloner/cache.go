@@ -0,0 +1,499 @@
+package cloner
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/eroatta/freqtable/adapter/wordcount"
+	log "github.com/sirupsen/logrus"
+	"gopkg.in/src-d/go-billy.v4/osfs"
+	"gopkg.in/src-d/go-git.v4"
+	"gopkg.in/src-d/go-git.v4/config"
+	"gopkg.in/src-d/go-git.v4/plumbing"
+	"gopkg.in/src-d/go-git.v4/plumbing/cache"
+	"gopkg.in/src-d/go-git.v4/plumbing/transport"
+	"gopkg.in/src-d/go-git.v4/storage/filesystem"
+)
+
+// fetchRefSpecs are the references updated when a cached repository is reused. Branches and tags
+// are forced, since they can be rewritten on the remote.
+var fetchRefSpecs = []config.RefSpec{
+	"+refs/heads/*:refs/remotes/origin/*",
+	"+refs/tags/*:refs/tags/*",
+}
+
+// errLocked is returned when a lock file is held by another process.
+var errLocked = errors.New("locked by another process")
+
+// NewCached creates and initializes a new cloner which keeps a bare clone of each repository on
+// the given directory, so that later extractions only fetch the new commits. Once the cache exceeds
+// the given cache size in bytes, the least recently used repositories are removed, and a repository
+// whose clone exceeds the given maximum size is removed right away. A size of zero disables the
+// eviction or the limit. Like the cloner created by New, it authenticates with the credentials of
+// each host.
+func NewCached(credentials HostCredentials, dir string, cacheSize int64, maxSize int64) wordcount.Cloner {
+	return &cachedCloner{
+		dir:         dir,
+		cacheSize:   cacheSize,
+		maxSize:     maxSize,
+		credentials: credentials,
+		entries:     make(map[string]*cacheEntry),
+	}
+}
+
+type cachedCloner struct {
+	dir         string
+	cacheSize   int64
+	maxSize     int64
+	credentials HostCredentials
+
+	// mu guards the entries, and prevents a repository from being evicted while it's being acquired.
+	mu      sync.Mutex
+	entries map[string]*cacheEntry
+}
+
+// cacheEntry locks a cached repository, which is in use while it has users, either holding or
+// waiting for its locks. Clones and fetches of the repository are serialized by the update lock,
+// while the files lock is shared by the checkouts reading from it, and is only held exclusively to
+// remove the repository. Since fetched objects are only added to the repository, checkouts keep
+// reading while a fetch runs. Both locks are held on files too, so that processes sharing the cache
+// directory don't corrupt each other.
+type cacheEntry struct {
+	update sync.Mutex
+	files  sync.RWMutex
+	users  int
+}
+
+// cachedCheckout holds the files of a commit read from a cached repository, which can't be removed
+// until the checkout is closed.
+type cachedCheckout struct {
+	*commitCheckout
+	release func()
+	once    sync.Once
+}
+
+// Clone clones the repository into the cache, or fetches its new commits if it's already cached,
+// and reads the files of the given branch, tag or commit hash from it. If no ref is given, the
+// default branch is used. Repositories are cached by URL, holding every revision, and a commit
+// hash already on the cache is read without fetching. Concurrent extractions of the same
+// repository only wait for each other while fetching it and resolving their revisions. Since the
+// whole history is kept, the maximum size applies to the clone on disk, and ErrRepositoryTooLarge
+// is returned as soon as the transfer exceeds it.
+func (c *cachedCloner) Clone(ctx context.Context, url string, ref string) (wordcount.Checkout, error) {
+	log.WithField("repository", url).WithField("ref", ref).Info("cloning repository into the cache")
+	repository, release, err := c.open(ctx, url, ref)
+	if err != nil {
+		return nil, err
+	}
+
+	commit, err := resolve(repository, ref)
+	if err == nil && commit == nil {
+		err = fmt.Errorf("repository %s has no commits", url)
+	}
+	if err != nil {
+		release()
+		return nil, err
+	}
+
+	return &cachedCheckout{
+		commitCheckout: &commitCheckout{name: url, commit: commit},
+		release:        release,
+	}, nil
+}
+
+// open updates the cached repository, unless the given ref is already on it. It returns the
+// repository along with the function releasing it, which can't be removed until then.
+func (c *cachedCloner) open(ctx context.Context, url string, ref string) (*git.Repository, func(), error) {
+	auth, err := c.credentials.auth(url)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if err := os.MkdirAll(c.dir, 0755); err != nil {
+		return nil, nil, err
+	}
+
+	key := cacheKey(url)
+	entry := c.acquire(key)
+	unlock, err := c.lockUpdate(entry, key)
+	if err != nil {
+		c.leave(key)
+		return nil, nil, err
+	}
+	defer unlock()
+
+	path := filepath.Join(c.dir, key)
+	repository, err := update(ctx, path, url, ref, auth, c.maxSize, func() error {
+		return c.remove(entry, key)
+	})
+	if err != nil {
+		c.leave(key)
+		return nil, nil, err
+	}
+
+	release, err := c.lockFiles(entry, key)
+	if err != nil {
+		c.leave(key)
+		return nil, nil, err
+	}
+
+	now := time.Now()
+	if err := os.Chtimes(path, now, now); err != nil {
+		log.WithError(err).WithField("repository", url).Warn("unable to update the last use of the cached repository")
+	}
+	c.evict()
+
+	return repository, release, nil
+}
+
+// cacheKey provides the name of the directory holding the cached repository for the given URL.
+// Its locks are held on files named after it.
+func cacheKey(url string) string {
+	sum := sha256.Sum256([]byte(url))
+	return hex.EncodeToString(sum[:])
+}
+
+// acquire registers a new user of the cached repository for the given key, so that it isn't
+// evicted until the user leaves.
+func (c *cachedCloner) acquire(key string) *cacheEntry {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry, ok := c.entries[key]
+	if !ok {
+		entry = &cacheEntry{}
+		c.entries[key] = entry
+	}
+	entry.users++
+
+	return entry
+}
+
+// leave unregisters a user of the cached repository for the given key.
+func (c *cachedCloner) leave(key string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry := c.entries[key]
+	entry.users--
+	if entry.users == 0 {
+		delete(c.entries, key)
+	}
+}
+
+// lockUpdate locks the cached repository for cloning or fetching it, returning the function
+// releasing it.
+func (c *cachedCloner) lockUpdate(entry *cacheEntry, key string) (func(), error) {
+	entry.update.Lock()
+	file, err := lockFile(filepath.Join(c.dir, key+".lock"), true, true)
+	if err != nil {
+		entry.update.Unlock()
+		return nil, err
+	}
+
+	return func() {
+		file.Close()
+		entry.update.Unlock()
+	}, nil
+}
+
+// lockFiles locks the cached repository for reading from it, returning the function releasing it
+// and its user.
+func (c *cachedCloner) lockFiles(entry *cacheEntry, key string) (func(), error) {
+	entry.files.RLock()
+	file, err := lockFile(filepath.Join(c.dir, key+".files"), false, true)
+	if err != nil {
+		entry.files.RUnlock()
+		return nil, err
+	}
+
+	return func() {
+		file.Close()
+		entry.files.RUnlock()
+		c.leave(key)
+	}, nil
+}
+
+// remove removes the cached repository once the checkouts reading from it are closed.
+func (c *cachedCloner) remove(entry *cacheEntry, key string) error {
+	entry.files.Lock()
+	defer entry.files.Unlock()
+	file, err := lockFile(filepath.Join(c.dir, key+".files"), true, true)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	return os.RemoveAll(filepath.Join(c.dir, key))
+}
+
+// update opens the cached repository on the given path and fetches its new commits, unless the
+// given ref is a commit hash already on it. If the repository isn't cached yet, or it can't be
+// opened, it's cloned from scratch. Once the clone on disk exceeds the maximum size, the transfer
+// is aborted and ErrRepositoryTooLarge is returned. Broken, partial or too large repositories are
+// removed through the given function.
+func update(ctx context.Context, path string, url string, ref string, auth transport.AuthMethod,
+	maxSize int64, remove func() error) (*git.Repository, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	storage := newLimitedDiskStorage(path, maxSize, cancel)
+	repository, err := git.Open(storage, nil)
+	switch err {
+	case nil:
+		if isCached(repository, ref) {
+			return repository, nil
+		}
+
+		err = repository.FetchContext(ctx, &git.FetchOptions{
+			RefSpecs: fetchRefSpecs,
+			Auth:     auth,
+			Force:    true,
+		})
+		if storage.exceeded {
+			remove()
+			return nil, wordcount.ErrRepositoryTooLarge
+		}
+		if err != nil && err != git.NoErrAlreadyUpToDate {
+			return nil, err
+		}
+
+		return repository, updateHead(repository)
+	case git.ErrRepositoryNotExists:
+		// clone it
+	default:
+		log.WithError(err).WithField("repository", url).Warn("discarding the broken cached repository")
+		if err := remove(); err != nil {
+			return nil, err
+		}
+	}
+
+	storage = newLimitedDiskStorage(path, maxSize, cancel)
+	repository, err = git.CloneContext(ctx, storage, nil, &git.CloneOptions{
+		URL:  url,
+		Auth: auth,
+	})
+	if storage.exceeded {
+		err = wordcount.ErrRepositoryTooLarge
+	}
+	if err != nil {
+		// a partial clone can't be reused
+		remove()
+		return nil, err
+	}
+
+	return repository, nil
+}
+
+// isCached checks if the given ref is the full hash of a commit already on the repository. Since
+// a commit can't change, it's read without fetching.
+func isCached(repository *git.Repository, ref string) bool {
+	if len(ref) != 40 || plumbing.NewHash(ref).String() != ref {
+		return false
+	}
+
+	_, err := repository.CommitObject(plumbing.NewHash(ref))
+	return err == nil
+}
+
+// updateHead moves the branch on HEAD, which was created by the clone, to the fetched commit of
+// its remote branch.
+func updateHead(repository *git.Repository) error {
+	head, err := repository.Reference(plumbing.HEAD, false)
+	if err != nil {
+		return err
+	}
+	if head.Type() != plumbing.SymbolicReference {
+		return nil
+	}
+
+	branch := head.Target()
+	remote, err := repository.Reference(plumbing.NewRemoteReferenceName(git.DefaultRemoteName, branch.Short()), true)
+	if err == plumbing.ErrReferenceNotFound {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	return repository.Storer.SetReference(plumbing.NewHashReference(branch, remote.Hash()))
+}
+
+// limitedDiskStorage stores the objects of a repository on disk, failing once the size of the
+// repository, including the objects it already held, exceeds the maximum size. Since the fetched
+// objects are written as a packfile, the packfile is counted as it's written. Once the maximum size
+// is exceeded, the transfer is cancelled, so the remote stops sending the packfile.
+type limitedDiskStorage struct {
+	*filesystem.Storage
+	maxSize  int64
+	size     int64
+	exceeded bool
+	cancel   context.CancelFunc
+}
+
+// newLimitedDiskStorage opens the storage of the bare repository on the given path, cancelling the
+// transfer through the given function once the maximum size is exceeded. A size of zero disables
+// the limit.
+func newLimitedDiskStorage(path string, maxSize int64, cancel context.CancelFunc) *limitedDiskStorage {
+	return &limitedDiskStorage{
+		Storage: filesystem.NewStorage(osfs.New(path), cache.NewObjectLRUDefault()),
+		maxSize: maxSize,
+		size:    dirSize(path),
+		cancel:  cancel,
+	}
+}
+
+// SetEncodedObject stores an object, unless it exceeds the maximum size.
+func (s *limitedDiskStorage) SetEncodedObject(obj plumbing.EncodedObject) (plumbing.Hash, error) {
+	if err := s.add(obj.Size()); err != nil {
+		return plumbing.ZeroHash, err
+	}
+
+	return s.Storage.SetEncodedObject(obj)
+}
+
+// PackfileWriter provides a writer for a fetched packfile, which stops writing once the maximum
+// size is exceeded.
+func (s *limitedDiskStorage) PackfileWriter() (io.WriteCloser, error) {
+	w, err := s.Storage.PackfileWriter()
+	if err != nil {
+		return nil, err
+	}
+
+	return &limitedWriter{WriteCloser: w, storage: s}, nil
+}
+
+// add counts the given bytes. Once the maximum size is exceeded, it cancels the transfer and
+// returns ErrRepositoryTooLarge.
+func (s *limitedDiskStorage) add(size int64) error {
+	s.size += size
+	if s.maxSize > 0 && s.size > s.maxSize {
+		s.exceeded = true
+		s.cancel()
+		return wordcount.ErrRepositoryTooLarge
+	}
+
+	return nil
+}
+
+// limitedWriter counts the bytes written on a packfile against the size of its storage.
+type limitedWriter struct {
+	io.WriteCloser
+	storage *limitedDiskStorage
+}
+
+// Write writes the given bytes, unless they exceed the maximum size. Exceeding bytes are discarded
+// instead of failing, since the transport only kills the remote command when reading from it fails,
+// which happens right after the transfer is cancelled.
+func (w *limitedWriter) Write(p []byte) (int, error) {
+	if w.storage.exceeded || w.storage.add(int64(len(p))) != nil {
+		return len(p), nil
+	}
+
+	return w.WriteCloser.Write(p)
+}
+
+// evict removes the least recently used repositories until the cache fits its maximum size.
+// Repositories in use are kept. The cache is walked without locking the entries, so acquiring a
+// repository doesn't wait for it, and they're only locked to check and remove each repository.
+func (c *cachedCloner) evict() {
+	if c.cacheSize <= 0 {
+		return
+	}
+
+	files, err := ioutil.ReadDir(c.dir)
+	if err != nil {
+		log.WithError(err).Warn("unable to read the cache")
+		return
+	}
+
+	// oldest first
+	sort.Slice(files, func(i, j int) bool { return files[i].ModTime().Before(files[j].ModTime()) })
+
+	sizes := make([]int64, len(files))
+	var total int64
+	for i, file := range files {
+		sizes[i] = dirSize(filepath.Join(c.dir, file.Name()))
+		total += sizes[i]
+	}
+
+	for i, file := range files {
+		if total <= c.cacheSize {
+			break
+		}
+		if !file.IsDir() {
+			continue
+		}
+
+		evicted, err := c.evictUnused(file.Name())
+		if err != nil {
+			log.WithError(err).WithField("key", file.Name()).Debug("unable to evict cached repository")
+			continue
+		}
+		if !evicted {
+			continue
+		}
+		log.WithField("key", file.Name()).WithField("size", sizes[i]).Info("evicted cached repository")
+		total -= sizes[i]
+	}
+}
+
+// evictUnused removes the cached repository for the given key unless it's in use, reporting
+// whether it was removed.
+func (c *cachedCloner) evictUnused(key string) (bool, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if _, inUse := c.entries[key]; inUse {
+		return false, nil
+	}
+
+	return true, c.evictRepository(key)
+}
+
+// evictRepository removes the cached repository for the given key, unless another process is using
+// it. Its lock files are kept, since another process could be waiting on them.
+func (c *cachedCloner) evictRepository(key string) error {
+	update, err := lockFile(filepath.Join(c.dir, key+".lock"), true, false)
+	if err != nil {
+		return err
+	}
+	defer update.Close()
+
+	files, err := lockFile(filepath.Join(c.dir, key+".files"), true, false)
+	if err != nil {
+		return err
+	}
+	defer files.Close()
+
+	return os.RemoveAll(filepath.Join(c.dir, key))
+}
+
+// dirSize sums the size of the files on a directory and its subdirectories.
+func dirSize(path string) int64 {
+	var size int64
+	filepath.Walk(path, func(_ string, info os.FileInfo, err error) error {
+		if err == nil && !info.IsDir() {
+			size += info.Size()
+		}
+		return nil
+	})
+
+	return size
+}
+
+// Close releases the cached repository, which is kept on the cache.
+func (c *cachedCheckout) Close() error {
+	c.once.Do(c.release)
+	return nil
+}
@@ -0,0 +1,274 @@
+package cloner
+
+import (
+	"context"
+	"crypto/rand"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/eroatta/freqtable/adapter/wordcount"
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/src-d/go-billy.v4/memfs"
+	"gopkg.in/src-d/go-billy.v4/osfs"
+	git "gopkg.in/src-d/go-git.v4"
+	"gopkg.in/src-d/go-git.v4/plumbing/cache"
+	"gopkg.in/src-d/go-git.v4/storage/filesystem"
+)
+
+func TestClone_OnCachedClonerTwice_ShouldFetchNewCommits(t *testing.T) {
+	dir := tempDir(t)
+	defer os.RemoveAll(dir)
+	repository := newServedRepository(t, filepath.Join(dir, "served", "private.git"))
+	first := commitFiles(t, repository, map[string]string{"main.go": "package main"})
+
+	var requests int
+	handler := newGitHandler(osfs.New(filepath.Join(dir, "served")), "ci", "ghp_secret")
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		handler.ServeHTTP(w, r)
+	}))
+	defer server.Close()
+	serverURL, _ := url.Parse(server.URL)
+	repositoryURL := server.URL + "/private.git"
+	clnr := NewCached(HostCredentials{serverURL.Host: {Username: "ci", Token: "ghp_secret", AllowInsecure: true}}, filepath.Join(dir, "cache"), 0, 0)
+
+	checkout, err := clnr.Clone(context.TODO(), repositoryURL, "")
+	assert.NoError(t, err)
+	assert.Equal(t, first[0].String(), checkout.Repository().Hash)
+	assert.NoError(t, checkout.Close())
+
+	second := commitFiles(t, repository, map[string]string{"util.go": "package main"})
+	requests = 0
+	checkout, err = clnr.Clone(context.TODO(), repositoryURL, "")
+
+	assert.NoError(t, err)
+	assert.Equal(t, 2, requests)
+	assert.Equal(t, second[0].String(), checkout.Repository().Hash)
+	names, err := checkout.Filenames(context.TODO())
+	assert.NoError(t, err)
+	assert.ElementsMatch(t, []string{"main.go", "util.go"}, names)
+	assert.NoError(t, checkout.Close())
+
+	// a cached commit isn't fetched
+	requests = 0
+	checkout, err = clnr.Clone(context.TODO(), repositoryURL, first[0].String())
+	assert.NoError(t, err)
+	assert.Equal(t, 0, requests)
+	assert.Equal(t, first[0].String(), checkout.Repository().Hash)
+	assert.NoError(t, checkout.Close())
+
+	cached, _ := filepath.Glob(filepath.Join(dir, "cache", "*", "HEAD"))
+	assert.Equal(t, 1, len(cached))
+}
+
+func TestClone_OnCachedClonerWithUnknownRef_ShouldReturnErrorAndReleaseRepository(t *testing.T) {
+	dir := tempDir(t)
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "repository")
+	commitFiles(t, newServedRepository(t, path), map[string]string{"main.go": "package main"})
+	clnr := NewCached(nil, filepath.Join(dir, "cache"), 0, 0)
+
+	checkout, err := clnr.Clone(context.TODO(), path, "v9.9.9")
+	assert.Nil(t, checkout)
+	assert.Equal(t, wordcount.ErrUnknownRevision, err)
+
+	checkout, err = clnr.Clone(context.TODO(), path, "")
+	assert.NoError(t, err)
+	assert.NoError(t, checkout.Close())
+}
+
+func TestClone_OnCachedClonerConcurrently_ShouldShareRepository(t *testing.T) {
+	dir := tempDir(t)
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "repository")
+	hashes := commitFiles(t, newServedRepository(t, path), map[string]string{"main.go": "package main"})
+	clnr := NewCached(nil, filepath.Join(dir, "cache"), 0, 0)
+
+	var wg sync.WaitGroup
+	results := make([]string, 5)
+	for i := range results {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			checkout, err := clnr.Clone(context.TODO(), path, "")
+			if err != nil {
+				results[i] = err.Error()
+				return
+			}
+			defer checkout.Close()
+
+			content, err := checkout.File(context.TODO(), "main.go")
+			if err != nil {
+				results[i] = err.Error()
+				return
+			}
+			results[i] = checkout.Repository().Hash + " " + string(content)
+		}(i)
+	}
+	wg.Wait()
+
+	for _, result := range results {
+		assert.Equal(t, hashes[0].String()+" package main", result)
+	}
+}
+
+func TestClone_OnCachedClonerWithOpenCheckout_ShouldCloneAnotherRef(t *testing.T) {
+	dir := tempDir(t)
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "repository")
+	served := newServedRepository(t, path)
+	first := commitFiles(t, served, map[string]string{"main.go": "package main"})
+	second := commitFiles(t, served, map[string]string{"util.go": "package main"})
+	clnr := NewCached(nil, filepath.Join(dir, "cache"), 0, 0)
+
+	checkout, err := clnr.Clone(context.TODO(), path, first[0].String())
+	assert.NoError(t, err)
+	defer checkout.Close()
+
+	// the open checkout doesn't block the extraction of the same repository
+	another, err := clnr.Clone(context.TODO(), path, "")
+	assert.NoError(t, err)
+	assert.Equal(t, second[0].String(), another.Repository().Hash)
+	assert.NoError(t, another.Close())
+
+	names, err := checkout.Filenames(context.TODO())
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"main.go"}, names)
+}
+
+func TestClone_OnCachedClonerOverCacheSizeWithRepositoryInUseByAnotherProcess_ShouldKeepIt(t *testing.T) {
+	dir := tempDir(t)
+	defer os.RemoveAll(dir)
+	first := filepath.Join(dir, "first")
+	commitFiles(t, newServedRepository(t, first), map[string]string{"first.go": "package first"})
+	second := filepath.Join(dir, "second")
+	commitFiles(t, newServedRepository(t, second), map[string]string{"second.go": "package second"})
+	clnr := NewCached(nil, filepath.Join(dir, "cache"), 1, 0)
+
+	checkout, err := clnr.Clone(context.TODO(), first, "")
+	assert.NoError(t, err)
+	assert.NoError(t, checkout.Close())
+
+	// another process reading the repository holds its lock file
+	lock, err := lockFile(filepath.Join(dir, "cache", cacheKey(first)+".files"), false, true)
+	assert.NoError(t, err)
+	defer lock.Close()
+	_, err = lockFile(filepath.Join(dir, "cache", cacheKey(first)+".files"), true, false)
+	assert.Equal(t, errLocked, err)
+
+	checkout, err = clnr.Clone(context.TODO(), second, "")
+	assert.NoError(t, err)
+	assert.NoError(t, checkout.Close())
+
+	_, err = os.Stat(filepath.Join(dir, "cache", cacheKey(first)))
+	assert.NoError(t, err)
+}
+
+func TestClone_OnCachedClonerOverCacheSize_ShouldEvictLeastRecentlyUsed(t *testing.T) {
+	dir := tempDir(t)
+	defer os.RemoveAll(dir)
+	first := filepath.Join(dir, "first")
+	commitFiles(t, newServedRepository(t, first), map[string]string{"first.go": "package first"})
+	second := filepath.Join(dir, "second")
+	commitFiles(t, newServedRepository(t, second), map[string]string{"second.go": "package second"})
+	clnr := NewCached(nil, filepath.Join(dir, "cache"), 1, 0)
+
+	checkout, err := clnr.Clone(context.TODO(), first, "")
+	assert.NoError(t, err)
+	assert.NoError(t, checkout.Close())
+	_, err = os.Stat(filepath.Join(dir, "cache", cacheKey(first)))
+	assert.NoError(t, err)
+
+	checkout, err = clnr.Clone(context.TODO(), second, "")
+	assert.NoError(t, err)
+
+	// the repository in use is kept, even if it exceeds the size
+	_, err = os.Stat(filepath.Join(dir, "cache", cacheKey(first)))
+	assert.True(t, os.IsNotExist(err))
+	_, err = os.Stat(filepath.Join(dir, "cache", cacheKey(second)))
+	assert.NoError(t, err)
+	names, err := checkout.Filenames(context.TODO())
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"second.go"}, names)
+	assert.NoError(t, checkout.Close())
+}
+
+func TestClone_OnCachedClonerOverMaxSize_ShouldAbortTransferAndRemoveRepository(t *testing.T) {
+	dir := tempDir(t)
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "repository")
+	served := newServedRepository(t, path)
+	commitFiles(t, served, map[string]string{"main.go": "package main"})
+	clnr := NewCached(nil, filepath.Join(dir, "cache"), 0, 64*1024)
+
+	checkout, err := clnr.Clone(context.TODO(), path, "")
+	assert.NoError(t, err)
+	assert.NoError(t, checkout.Close())
+
+	// random content can't be compressed, so the fetched packfile exceeds the maximum size
+	large := make([]byte, 128*1024)
+	rand.Read(large)
+	commitFiles(t, served, map[string]string{"large.go": string(large)})
+
+	checkout, err = clnr.Clone(context.TODO(), path, "")
+	assert.Nil(t, checkout)
+	assert.Equal(t, wordcount.ErrRepositoryTooLarge, err)
+	_, err = os.Stat(filepath.Join(dir, "cache", cacheKey(path)))
+	assert.True(t, os.IsNotExist(err))
+
+	checkout, err = clnr.Clone(context.TODO(), path, "")
+	assert.Nil(t, checkout)
+	assert.Equal(t, wordcount.ErrRepositoryTooLarge, err)
+}
+
+func TestFile_OnCachedClonerWithSeveralWorkers_ShouldReadEveryFile(t *testing.T) {
+	dir := tempDir(t)
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "repository")
+	files := make(map[string]string)
+	for i := 0; i < 50; i++ {
+		files[fmt.Sprintf("pkg%d/file.go", i)] = fmt.Sprintf("package pkg%d", i)
+	}
+	commitFiles(t, newServedRepository(t, path), files)
+	clnr := NewCached(nil, filepath.Join(dir, "cache"), 0, 0)
+
+	checkout, err := clnr.Clone(context.TODO(), path, "")
+	assert.NoError(t, err)
+	defer checkout.Close()
+
+	var wg sync.WaitGroup
+	contents := make(map[string]string)
+	var mu sync.Mutex
+	for name := range files {
+		wg.Add(1)
+		go func(name string) {
+			defer wg.Done()
+			content, err := checkout.File(context.TODO(), name)
+			assert.NoError(t, err)
+
+			mu.Lock()
+			contents[name] = string(content)
+			mu.Unlock()
+		}(name)
+	}
+	wg.Wait()
+
+	assert.Equal(t, files, contents)
+}
+
+// newServedRepository creates a bare repository on the given path, along with a worktree on memory
+// to commit files to it.
+func newServedRepository(t *testing.T, path string) *git.Repository {
+	repository, err := git.Init(filesystem.NewStorage(osfs.New(path), cache.NewObjectLRUDefault()), memfs.New())
+	if err != nil {
+		assert.FailNow(t, fmt.Sprintf("unexpected error creating repository: %v", err))
+	}
+
+	return repository
+}
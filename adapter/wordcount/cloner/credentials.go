@@ -0,0 +1,130 @@
+package cloner
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+
+	"gopkg.in/src-d/go-git.v4/plumbing/transport"
+	"gopkg.in/src-d/go-git.v4/plumbing/transport/http"
+	"gopkg.in/src-d/go-git.v4/plumbing/transport/ssh"
+)
+
+// defaultUsername is the user name sent along with a token, or used to connect through SSH, if
+// none is configured. Token based authentication ignores it on most Git hosting services.
+const defaultUsername = "git"
+
+// Credentials holds the secrets used to access the private repositories of a host. HTTP(S)
+// repositories use either the username and password or the token, sent as the password of a
+// basic authentication. Since plain http:// URLs would send them in the clear, they're refused
+// unless AllowInsecure is set. SSH repositories use the private key file, with its optional
+// passphrase, and check the host keys against the known hosts file, or the user's known hosts by
+// default.
+type Credentials struct {
+	Username      string `json:"username"`
+	Password      string `json:"password"`
+	Token         string `json:"token"`
+	AllowInsecure bool   `json:"allow_insecure"`
+	SSHKeyFile    string `json:"ssh_key_file"`
+	SSHPassphrase string `json:"ssh_passphrase"`
+	KnownHosts    string `json:"known_hosts"`
+}
+
+// String hides the secrets, so the credentials can't leak into the logs.
+func (c Credentials) String() string {
+	return fmt.Sprintf("Credentials{Username: %s, SSHKeyFile: %s, KnownHosts: %s}", c.Username, c.SSHKeyFile, c.KnownHosts)
+}
+
+// GoString hides the secrets when formatted with %#v.
+func (c Credentials) GoString() string {
+	return c.String()
+}
+
+// HostCredentials maps a host name, such as github.com, to its credentials. A host name with a
+// port, such as git.example.com:8443, only applies to that port.
+type HostCredentials map[string]Credentials
+
+// LoadCredentials reads the credentials for each host from a JSON file, such as a mounted secret:
+//
+//	{
+//	  "github.com": {"token": "..."},
+//	  "git.example.com": {"ssh_key_file": "/secrets/id_rsa", "known_hosts": "/secrets/known_hosts"}
+//	}
+func LoadCredentials(path string) (HostCredentials, error) {
+	content, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var credentials HostCredentials
+	if err := json.Unmarshal(content, &credentials); err != nil {
+		return nil, fmt.Errorf("invalid credentials file %s: %v", path, err)
+	}
+
+	return credentials, nil
+}
+
+// auth creates the authentication method for the given repository URL, based on its protocol and
+// the credentials of its host and port, or its host. A nil method is returned if there are no
+// matching credentials. The username and password or token of a plain http:// URL return an error
+// instead, unless the host allows insecure connections.
+func (hc HostCredentials) auth(url string) (transport.AuthMethod, error) {
+	ep, err := transport.NewEndpoint(url)
+	if err != nil {
+		return nil, err
+	}
+
+	credentials, ok := hc[fmt.Sprintf("%s:%d", ep.Host, ep.Port)]
+	if !ok {
+		credentials, ok = hc[ep.Host]
+	}
+	if !ok {
+		return nil, nil
+	}
+
+	username := credentials.Username
+	if username == "" {
+		username = defaultUsername
+	}
+
+	switch ep.Protocol {
+	case "http", "https":
+		if credentials.Token == "" && credentials.Password == "" {
+			return nil, nil
+		}
+		if ep.Protocol == "http" && !credentials.AllowInsecure {
+			return nil, fmt.Errorf("refusing to send the credentials for %s over plain HTTP, use HTTPS or set allow_insecure", ep.Host)
+		}
+
+		switch {
+		case credentials.Token != "":
+			return &http.BasicAuth{Username: username, Password: credentials.Token}, nil
+		case credentials.Password != "":
+			return &http.BasicAuth{Username: username, Password: credentials.Password}, nil
+		}
+
+	case "ssh":
+		if credentials.SSHKeyFile == "" {
+			return nil, nil
+		}
+
+		if ep.User != "" {
+			username = ep.User
+		}
+		keys, err := ssh.NewPublicKeysFromFile(username, credentials.SSHKeyFile, credentials.SSHPassphrase)
+		if err != nil {
+			return nil, fmt.Errorf("unable to read the SSH key for %s: %v", ep.Host, err)
+		}
+
+		if credentials.KnownHosts != "" {
+			keys.HostKeyCallback, err = ssh.NewKnownHostsCallback(credentials.KnownHosts)
+			if err != nil {
+				return nil, fmt.Errorf("unable to read the known hosts for %s: %v", ep.Host, err)
+			}
+		}
+
+		return keys, nil
+	}
+
+	return nil, nil
+}
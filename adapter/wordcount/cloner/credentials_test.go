@@ -0,0 +1,262 @@
+package cloner
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"io/ioutil"
+	nethttp "net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/src-d/go-billy.v4"
+	"gopkg.in/src-d/go-billy.v4/osfs"
+	"gopkg.in/src-d/go-git.v4/plumbing/format/pktline"
+	"gopkg.in/src-d/go-git.v4/plumbing/protocol/packp"
+	"gopkg.in/src-d/go-git.v4/plumbing/protocol/packp/capability"
+	"gopkg.in/src-d/go-git.v4/plumbing/transport"
+	"gopkg.in/src-d/go-git.v4/plumbing/transport/http"
+	"gopkg.in/src-d/go-git.v4/plumbing/transport/server"
+	"gopkg.in/src-d/go-git.v4/plumbing/transport/ssh"
+)
+
+func TestLoadCredentials_OnCredentialsFile_ShouldReturnCredentialsByHost(t *testing.T) {
+	dir := tempDir(t)
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "credentials.json")
+	content := `{
+		"github.com": {"token": "ghp_secret"},
+		"git.example.com": {"username": "ci", "password": "p4ssw0rd", "allow_insecure": true, "ssh_key_file": "/secrets/id_rsa"}
+	}`
+	if err := ioutil.WriteFile(path, []byte(content), 0600); err != nil {
+		assert.FailNow(t, fmt.Sprintf("unexpected error writing file: %v", err))
+	}
+
+	credentials, err := LoadCredentials(path)
+
+	assert.NoError(t, err)
+	assert.Equal(t, HostCredentials{
+		"github.com":      {Token: "ghp_secret"},
+		"git.example.com": {Username: "ci", Password: "p4ssw0rd", AllowInsecure: true, SSHKeyFile: "/secrets/id_rsa"},
+	}, credentials)
+}
+
+func TestLoadCredentials_OnInvalidCredentialsFile_ShouldReturnError(t *testing.T) {
+	dir := tempDir(t)
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "credentials.json")
+	if err := ioutil.WriteFile(path, []byte(`["github.com"]`), 0600); err != nil {
+		assert.FailNow(t, fmt.Sprintf("unexpected error writing file: %v", err))
+	}
+
+	credentials, err := LoadCredentials(path)
+
+	assert.Nil(t, credentials)
+	assert.Error(t, err)
+}
+
+func TestString_OnCredentials_ShouldHideSecrets(t *testing.T) {
+	credentials := Credentials{Username: "ci", Password: "p4ssw0rd", Token: "ghp_secret", SSHPassphrase: "s3cr3t"}
+
+	for _, format := range []string{"%v", "%+v", "%#v", "%s"} {
+		output := fmt.Sprintf(format, HostCredentials{"github.com": credentials})
+
+		assert.Contains(t, output, "ci")
+		assert.NotContains(t, output, "p4ssw0rd")
+		assert.NotContains(t, output, "ghp_secret")
+		assert.NotContains(t, output, "s3cr3t")
+	}
+}
+
+func TestAuth_OnHTTPRepositories_ShouldReturnBasicAuth(t *testing.T) {
+	credentials := HostCredentials{
+		"github.com":      {Token: "ghp_secret"},
+		"git.example.com": {Username: "ci", Password: "p4ssw0rd"},
+		"ssh.example.com": {SSHKeyFile: "/secrets/id_rsa"},
+	}
+
+	tests := []struct {
+		url  string
+		want transport.AuthMethod
+	}{
+		{"https://github.com/eroatta/private", &http.BasicAuth{Username: "git", Password: "ghp_secret"}},
+		{"https://git.example.com/team/private.git", &http.BasicAuth{Username: "ci", Password: "p4ssw0rd"}},
+		{"https://ssh.example.com/team/private.git", nil},
+		{"https://gitlab.com/eroatta/public", nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.url, func(t *testing.T) {
+			auth, err := credentials.auth(tt.url)
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, auth)
+		})
+	}
+}
+
+func TestAuth_OnPlainHTTPRepositories_ShouldRefuseCredentialsUnlessInsecureAllowed(t *testing.T) {
+	credentials := HostCredentials{
+		"github.com":      {Token: "ghp_secret"},
+		"git.example.com": {Username: "ci", Password: "p4ssw0rd", AllowInsecure: true},
+	}
+
+	auth, err := credentials.auth("http://github.com/eroatta/private")
+	assert.Nil(t, auth)
+	assert.EqualError(t, err, "refusing to send the credentials for github.com over plain HTTP, use HTTPS or set allow_insecure")
+
+	auth, err = credentials.auth("http://git.example.com/team/private.git")
+	assert.NoError(t, err)
+	assert.Equal(t, &http.BasicAuth{Username: "ci", Password: "p4ssw0rd"}, auth)
+
+	auth, err = credentials.auth("http://gitlab.com/eroatta/public")
+	assert.NoError(t, err)
+	assert.Nil(t, auth)
+}
+
+func TestAuth_OnSSHRepositories_ShouldReturnPublicKeys(t *testing.T) {
+	dir := tempDir(t)
+	defer os.RemoveAll(dir)
+	keyFile := filepath.Join(dir, "id_rsa")
+	writeRSAKey(t, keyFile)
+	knownHosts := filepath.Join(dir, "known_hosts")
+	if err := ioutil.WriteFile(knownHosts, []byte{}, 0600); err != nil {
+		assert.FailNow(t, fmt.Sprintf("unexpected error writing file: %v", err))
+	}
+
+	credentials := HostCredentials{
+		"github.com":      {SSHKeyFile: keyFile, KnownHosts: knownHosts},
+		"git.example.com": {Username: "ci", SSHKeyFile: keyFile},
+		"bad.example.com": {SSHKeyFile: filepath.Join(dir, "missing")},
+	}
+
+	auth, err := credentials.auth("git@github.com:eroatta/private.git")
+	assert.NoError(t, err)
+	assert.Equal(t, "git", auth.(*ssh.PublicKeys).User)
+	assert.NotNil(t, auth.(*ssh.PublicKeys).HostKeyCallback)
+
+	auth, err = credentials.auth("ssh://git.example.com/team/private.git")
+	assert.NoError(t, err)
+	assert.Equal(t, "ci", auth.(*ssh.PublicKeys).User)
+
+	auth, err = credentials.auth("ssh://bad.example.com/team/private.git")
+	assert.Nil(t, auth)
+	assert.Error(t, err)
+}
+
+func TestClone_OnGoGitClonerWithPrivateRepository_ShouldAuthenticate(t *testing.T) {
+	dir := tempDir(t)
+	defer os.RemoveAll(dir)
+	repository := newServedRepository(t, filepath.Join(dir, "private.git"))
+	hashes := commitFiles(t, repository, map[string]string{"main.go": "package main"})
+
+	server := httptest.NewServer(newGitHandler(osfs.New(dir), "ci", "ghp_secret"))
+	defer server.Close()
+	serverURL, _ := url.Parse(server.URL)
+	repositoryURL := server.URL + "/private.git"
+
+	checkout, err := New(nil, 0).Clone(context.TODO(), repositoryURL, "")
+	assert.Nil(t, checkout)
+	assert.Equal(t, transport.ErrAuthenticationRequired, err)
+
+	clnr := New(HostCredentials{serverURL.Host: {Username: "ci", Token: "ghp_secret"}}, 0)
+	checkout, err = clnr.Clone(context.TODO(), repositoryURL, "")
+	assert.Nil(t, checkout)
+	assert.EqualError(t, err, fmt.Sprintf("refusing to send the credentials for %s over plain HTTP, use HTTPS or set allow_insecure", serverURL.Hostname()))
+
+	// the test server doesn't use TLS
+	clnr = New(HostCredentials{serverURL.Host: {Username: "ci", Token: "ghp_secret", AllowInsecure: true}}, 0)
+	checkout, err = clnr.Clone(context.TODO(), repositoryURL, "")
+
+	assert.NoError(t, err)
+	assert.Equal(t, hashes[0].String(), checkout.Repository().Hash)
+	names, err := checkout.Filenames(context.TODO())
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"main.go"}, names)
+}
+
+// newGitHandler serves the bare repositories on the given filesystem through the smart HTTP
+// protocol, requiring a basic authentication with the given user and password.
+// Shallow clones are accepted, although the full history is sent.
+func newGitHandler(fs billy.Filesystem, user string, password string) nethttp.Handler {
+	srv := server.NewServer(server.NewFilesystemLoader(fs))
+	return nethttp.HandlerFunc(func(w nethttp.ResponseWriter, r *nethttp.Request) {
+		if u, p, ok := r.BasicAuth(); !ok || u != user || p != password {
+			w.Header().Set("WWW-Authenticate", `Basic realm="git"`)
+			w.WriteHeader(nethttp.StatusUnauthorized)
+			return
+		}
+
+		repository := path.Dir(r.URL.Path)
+		if r.Method == nethttp.MethodGet {
+			repository = path.Dir(repository)
+		}
+		ep, err := transport.NewEndpoint("http://localhost" + repository)
+		if err != nil {
+			nethttp.Error(w, err.Error(), nethttp.StatusBadRequest)
+			return
+		}
+
+		session, err := srv.NewUploadPackSession(ep, nil)
+		if err != nil {
+			nethttp.Error(w, err.Error(), nethttp.StatusNotFound)
+			return
+		}
+		defer session.Close()
+
+		if r.Method == nethttp.MethodGet {
+			refs, err := session.AdvertisedReferences()
+			if err != nil {
+				nethttp.Error(w, err.Error(), nethttp.StatusInternalServerError)
+				return
+			}
+			refs.Prefix = [][]byte{[]byte("# service=git-upload-pack"), pktline.Flush}
+			refs.Capabilities.Set(capability.Shallow)
+			w.Header().Set("Content-Type", "application/x-git-upload-pack-advertisement")
+			refs.Encode(w)
+			return
+		}
+
+		req := packp.NewUploadPackRequest()
+		if err := req.Decode(r.Body); err != nil {
+			nethttp.Error(w, err.Error(), nethttp.StatusBadRequest)
+			return
+		}
+		// shallow clones get the full history, along with an empty shallow update
+		shallow := !req.Depth.IsZero()
+		req.Depth = packp.DepthCommits(0)
+		req.Capabilities.Delete(capability.Shallow)
+		resp, err := session.UploadPack(r.Context(), req)
+		if err != nil {
+			nethttp.Error(w, err.Error(), nethttp.StatusInternalServerError)
+			return
+		}
+		defer resp.Close()
+		w.Header().Set("Content-Type", "application/x-git-upload-pack-result")
+		if shallow {
+			pktline.NewEncoder(w).Flush()
+		}
+		resp.Encode(w)
+	})
+}
+
+// writeRSAKey writes a new PEM encoded RSA private key on the given path.
+func writeRSAKey(t *testing.T, path string) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		assert.FailNow(t, fmt.Sprintf("unexpected error generating key: %v", err))
+	}
+
+	block := &pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)}
+	if err := ioutil.WriteFile(path, pem.EncodeToMemory(block), 0600); err != nil {
+		assert.FailNow(t, fmt.Sprintf("unexpected error writing file: %v", err))
+	}
+}
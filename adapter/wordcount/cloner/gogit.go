@@ -1,11 +1,21 @@
 package cloner
 
 import (
+	"context"
+	"io"
+	"sync"
+
 	"github.com/eroatta/freqtable/adapter/wordcount"
 	log "github.com/sirupsen/logrus"
 	"gopkg.in/src-d/go-billy.v4"
 	"gopkg.in/src-d/go-billy.v4/memfs"
 	"gopkg.in/src-d/go-git.v4"
+	"gopkg.in/src-d/go-git.v4/config"
+	"gopkg.in/src-d/go-git.v4/plumbing"
+	"gopkg.in/src-d/go-git.v4/plumbing/format/packfile"
+	"gopkg.in/src-d/go-git.v4/plumbing/object"
+	"gopkg.in/src-d/go-git.v4/plumbing/transport"
+	"gopkg.in/src-d/go-git.v4/storage"
 	"gopkg.in/src-d/go-git.v4/storage/memory"
 )
 
@@ -13,50 +23,327 @@ const (
 	rootDir = ""
 )
 
-// New creates and initializes a new cloner.
-func New() wordcount.Cloner {
+// New creates and initializes a new cloner, which authenticates with the credentials of each
+// repository host. Repositories on hosts without credentials are cloned anonymously. A clone whose
+// objects exceed the given size in bytes is aborted. A size of zero disables the limit.
+func New(credentials HostCredentials, maxSize int64) wordcount.Cloner {
 	return &goGitCloner{
-		clonerFunc: goGitClonerFunc,
+		clonerFunc:  goGitClonerFunc,
+		remoteFunc:  goGitRemoteFunc,
+		credentials: credentials,
+		maxSize:     maxSize,
 	}
 }
 
 type goGitCloner struct {
-	clonerFunc clonerFunc
+	clonerFunc  clonerFunc
+	remoteFunc  remoteFunc
+	credentials HostCredentials
+	maxSize     int64
+}
+
+// limitedStorage stores the objects of a repository on memory, failing once the fetched packfile
+// or the stored objects exceed the maximum size. The packfile is counted as it's received, before
+// its objects are resolved, and once the maximum size is exceeded the transfer is cancelled, so the
+// clone of a repository that is too large is aborted.
+type limitedStorage struct {
+	*memory.Storage
+	maxSize  int64
+	size     int64
+	received int64
+	cancel   context.CancelFunc
+
+	// the packfile is parsed concurrently with the transfer, so both can exceed the maximum size
+	mu       sync.Mutex
+	exceeded bool
+}
+
+// newLimitedStorage creates a storage on memory, cancelling the transfer through the given function
+// once the maximum size is exceeded. A size of zero disables the limit.
+func newLimitedStorage(maxSize int64, cancel context.CancelFunc) *limitedStorage {
+	return &limitedStorage{
+		Storage: memory.NewStorage(),
+		maxSize: maxSize,
+		cancel:  cancel,
+	}
+}
+
+// goGitCheckout holds a repository cloned by the goGitCloner, and the checked out commit.
+type goGitCheckout struct {
+	name       string
 	repository *git.Repository
+	commit     *object.Commit
 }
 
 // goGitClonerFunc defines the interface for cloning a remote Git repository.
-type clonerFunc func(url string) (*git.Repository, error)
+type clonerFunc func(ctx context.Context, storage storage.Storer, options *git.CloneOptions) (*git.Repository, error)
+
+// remoteFunc defines the interface for listing the references of a remote Git repository.
+type remoteFunc func(ctx context.Context, url string, auth transport.AuthMethod) ([]*plumbing.Reference, error)
+
+// GoGitClonerFunc clones a remote GitHub repository into the given storage using the src{d}/go-git
+// client. The cloning is aborted if the context is done.
+func goGitClonerFunc(ctx context.Context, storage storage.Storer, options *git.CloneOptions) (*git.Repository, error) {
+	return git.CloneContext(ctx, storage, memfs.New(), options)
+}
 
-// GoGitClonerFunc clones a remote GitHub repository using the src{d}/go-git client.
-func goGitClonerFunc(url string) (*git.Repository, error) {
-	return git.Clone(memory.NewStorage(), memfs.New(), &git.CloneOptions{
-		URL: url,
+// goGitRemoteFunc lists the references of a remote Git repository using the src{d}/go-git client,
+// authenticating with the given method, if any.
+func goGitRemoteFunc(ctx context.Context, url string, auth transport.AuthMethod) ([]*plumbing.Reference, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	remote := git.NewRemote(memory.NewStorage(), &config.RemoteConfig{
+		Name: git.DefaultRemoteName,
+		URLs: []string{url},
 	})
+
+	return remote.List(&git.ListOptions{Auth: auth})
+}
+
+// Clone clones the repository on memory, checks out the given branch, tag or commit hash, and
+// returns a new checkout to access its files. If no ref is given, the default branch is used.
+// Since only the checked out files are mined, branches and tags are cloned without their history,
+// while a commit hash requires the full history of the repository. If the objects of the
+// repository exceed the maximum size, ErrRepositoryTooLarge is returned.
+func (c *goGitCloner) Clone(ctx context.Context, url string, ref string) (wordcount.Checkout, error) {
+	log.WithField("repository", url).WithField("ref", ref).Info("cloning repository")
+	auth, err := c.credentials.auth(url)
+	if err != nil {
+		return nil, err
+	}
+
+	options, err := c.cloneOptions(ctx, url, ref, auth)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	storage := newLimitedStorage(c.maxSize, cancel)
+	repository, err := c.clonerFunc(ctx, storage, options)
+	if storage.isExceeded() {
+		return nil, wordcount.ErrRepositoryTooLarge
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	commit, err := checkout(repository, ref)
+	if err != nil {
+		return nil, err
+	}
+
+	return &goGitCheckout{
+		name:       url,
+		repository: repository,
+		commit:     commit,
+	}, nil
+}
+
+// cloneOptions creates the options to clone only the commit on the given branch or tag, or on the
+// default branch if no ref is given. Since a commit can't be fetched on its own, any other ref
+// is looked up on the full history.
+func (c *goGitCloner) cloneOptions(ctx context.Context, url string, ref string, auth transport.AuthMethod) (*git.CloneOptions, error) {
+	shallow := &git.CloneOptions{
+		URL:          url,
+		Auth:         auth,
+		Depth:        1,
+		SingleBranch: true,
+	}
+	if ref == "" {
+		return shallow, nil
+	}
+
+	refs, err := c.remoteFunc(ctx, url, auth)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, name := range []plumbing.ReferenceName{plumbing.NewBranchReferenceName(ref), plumbing.NewTagReferenceName(ref)} {
+		for _, r := range refs {
+			if r.Name() == name {
+				shallow.ReferenceName = name
+				return shallow, nil
+			}
+		}
+	}
+
+	return &git.CloneOptions{
+		URL:  url,
+		Auth: auth,
+	}, nil
+}
+
+// SetEncodedObject stores the object, unless the stored objects exceed the maximum size.
+func (s *limitedStorage) SetEncodedObject(obj plumbing.EncodedObject) (plumbing.Hash, error) {
+	s.size += obj.Size()
+	if s.maxSize > 0 && s.size > s.maxSize {
+		s.abort()
+		return plumbing.ZeroHash, wordcount.ErrRepositoryTooLarge
+	}
+
+	return s.Storage.SetEncodedObject(obj)
+}
+
+// PackfileWriter provides a writer for a fetched packfile, which counts the received bytes and
+// parses the packfile into the objects of the storage as it's written.
+func (s *limitedStorage) PackfileWriter() (io.WriteCloser, error) {
+	r, w := io.Pipe()
+	done := make(chan error, 1)
+	go func() {
+		// the storage is hidden behind the interface, so its objects are set one by one
+		err := packfile.UpdateObjectStorage(objectStorage{s}, r)
+		r.CloseWithError(err)
+		done <- err
+	}()
+
+	return &packfileParser{pipe: w, done: done, storage: s}, nil
+}
+
+// abort cancels the transfer once the maximum size is exceeded.
+func (s *limitedStorage) abort() {
+	s.mu.Lock()
+	s.exceeded = true
+	s.mu.Unlock()
+	s.cancel()
+}
+
+// isExceeded checks if the transfer was cancelled because the maximum size was exceeded.
+func (s *limitedStorage) isExceeded() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.exceeded
+}
+
+// objectStorage exposes only the methods of a storage.Storer, so a packfile written through it is
+// parsed instead of being written again.
+type objectStorage struct {
+	storage.Storer
+}
+
+// packfileParser writes a fetched packfile into the parser of a limitedStorage.
+type packfileParser struct {
+	pipe    *io.PipeWriter
+	done    <-chan error
+	storage *limitedStorage
+	failed  bool
+}
+
+// Write passes the given bytes to the parser, unless the packfile exceeds the maximum size or the
+// parser failed. In that case the transfer is cancelled and the remaining bytes are discarded,
+// since the transport only kills the remote command when reading from it fails.
+func (w *packfileParser) Write(p []byte) (int, error) {
+	if w.failed {
+		return len(p), nil
+	}
+
+	w.storage.received += int64(len(p))
+	if w.storage.maxSize > 0 && w.storage.received > w.storage.maxSize {
+		w.storage.abort()
+		w.failed = true
+		return len(p), nil
+	}
+
+	if _, err := w.pipe.Write(p); err != nil {
+		w.storage.cancel()
+		w.failed = true
+	}
+
+	return len(p), nil
+}
+
+// Close waits until the parser stores every object of the packfile.
+func (w *packfileParser) Close() error {
+	w.pipe.Close()
+	return <-w.done
 }
 
-func (c *goGitCloner) Clone(url string) (wordcount.Repository, error) {
-	log.WithField("repository", url).Info("cloning repository")
-	repository, err := c.clonerFunc(url)
+// checkout updates the worktree to match the commit referenced by the given branch, tag or
+// commit hash, and returns the commit. If no ref is given, the worktree is kept on the current HEAD.
+func checkout(repository *git.Repository, ref string) (*object.Commit, error) {
+	commit, err := resolve(repository, ref)
+	if err != nil || ref == "" {
+		return commit, err
+	}
+
+	wt, err := repository.Worktree()
 	if err != nil {
-		return wordcount.Repository{}, err
+		return nil, err
+	}
+
+	if err := wt.Checkout(&git.CheckoutOptions{Hash: commit.Hash, Force: true}); err != nil {
+		return nil, err
+	}
+
+	return commit, nil
+}
+
+// resolve finds the commit referenced by the given branch, tag or commit hash. Branches are
+// looked up on the cloned remote too. If no ref is given, the commit on HEAD is returned, which
+// is missing on empty repositories.
+func resolve(repository *git.Repository, ref string) (*object.Commit, error) {
+	if ref == "" {
+		head, err := repository.Head()
+		if err == plumbing.ErrReferenceNotFound {
+			return nil, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		return repository.CommitObject(head.Hash())
+	}
+
+	hash, err := repository.ResolveRevision(plumbing.Revision(ref))
+	if err == plumbing.ErrReferenceNotFound {
+		hash, err = repository.ResolveRevision(plumbing.Revision(git.DefaultRemoteName + "/" + ref))
+	}
+	if err != nil {
+		log.WithError(err).WithField("ref", ref).Debug("unable to resolve the ref")
+		return nil, wordcount.ErrUnknownRevision
+	}
+
+	return repository.CommitObject(*hash)
+}
+
+// Repository provides the information of the cloned repository, including the checked out commit.
+func (c *goGitCheckout) Repository() wordcount.Repository {
+	return newRepository(c.name, c.commit)
+}
+
+// newRepository creates the information of a repository and its checked out commit, if any.
+func newRepository(name string, commit *object.Commit) wordcount.Repository {
+	repository := wordcount.Repository{
+		Name: name,
+		URL:  name,
+	}
+	if commit != nil {
+		repository.Hash = commit.Hash.String()
+		repository.CommitDate = commit.Committer.When
 	}
-	c.repository = repository
 
-	return wordcount.Repository{Name: url}, nil
+	return repository
 }
 
 // Filenames retrieves the list of file names existing on a repository.
-func (c *goGitCloner) Filenames() ([]string, error) {
+func (c *goGitCheckout) Filenames(ctx context.Context) ([]string, error) {
 	wt, err := c.repository.Worktree()
 	if err != nil {
 		return nil, err
 	}
 
-	return read(wt.Filesystem, rootDir)
+	return read(ctx, wt.Filesystem, rootDir)
 }
 
-func read(fs billy.Filesystem, rootDir string) ([]string, error) {
+func read(ctx context.Context, fs billy.Filesystem, rootDir string) ([]string, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
 	files, err := fs.ReadDir(rootDir)
 	if err != nil {
 		return nil, err
@@ -64,8 +351,13 @@ func read(fs billy.Filesystem, rootDir string) ([]string, error) {
 
 	names := make([]string, 0)
 	for _, file := range files {
+		// the Git directory exists only when reading a repository from disk
+		if file.Name() == git.GitDirName {
+			continue
+		}
+
 		if file.IsDir() {
-			subDirFilenames, err := read(fs, fs.Join(rootDir, file.Name()))
+			subDirFilenames, err := read(ctx, fs, fs.Join(rootDir, file.Name()))
 			if err != nil {
 				return nil, err
 			}
@@ -79,25 +371,62 @@ func read(fs billy.Filesystem, rootDir string) ([]string, error) {
 	return names, nil
 }
 
+// FileSize provides the size in bytes of a given file, without reading it.
+func (c *goGitCheckout) FileSize(ctx context.Context, name string) (int64, error) {
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
+
+	wt, err := c.repository.Worktree()
+	if err != nil {
+		return 0, err
+	}
+
+	return fileSize(wt.Filesystem, name)
+}
+
 // File provides the bytes representation of a given file.
-func (c *goGitCloner) File(name string) ([]byte, error) {
+func (c *goGitCheckout) File(ctx context.Context, name string) ([]byte, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
 	wt, err := c.repository.Worktree()
 	if err != nil {
 		return nil, err
 	}
 
-	fileInfo, err := wt.Filesystem.Stat(name)
+	return readFile(wt.Filesystem, name)
+}
+
+func fileSize(fs billy.Filesystem, name string) (int64, error) {
+	fileInfo, err := fs.Stat(name)
+	if err != nil {
+		return 0, err
+	}
+
+	return fileInfo.Size(), nil
+}
+
+func readFile(fs billy.Filesystem, name string) ([]byte, error) {
+	fileInfo, err := fs.Stat(name)
 	if err != nil {
 		return nil, err
 	}
 
-	file, err := wt.Filesystem.Open(name)
+	file, err := fs.Open(name)
 	if err != nil {
 		return nil, err
 	}
+	defer file.Close()
 
 	bytes := make([]byte, fileInfo.Size())
-	_, err = file.Read(bytes)
+	_, err = io.ReadFull(file, bytes)
 
 	return bytes, err
 }
+
+// Close releases the cloned repository. Since it's stored on memory, there is nothing to clean up.
+func (c *goGitCheckout) Close() error {
+	return nil
+}
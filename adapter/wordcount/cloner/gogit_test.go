@@ -1,41 +1,276 @@
 package cloner
 
 import (
+	"context"
 	"errors"
+	"fmt"
+	"os"
 	"testing"
+	"time"
 
+	"gopkg.in/src-d/go-git.v4/storage"
 	"gopkg.in/src-d/go-git.v4/storage/memory"
 
 	"gopkg.in/src-d/go-billy.v4/memfs"
+	"gopkg.in/src-d/go-billy.v4/util"
 
 	"github.com/eroatta/freqtable/adapter/wordcount"
 	"github.com/stretchr/testify/assert"
 	git "gopkg.in/src-d/go-git.v4"
+	"gopkg.in/src-d/go-git.v4/plumbing"
+	"gopkg.in/src-d/go-git.v4/plumbing/object"
+	"gopkg.in/src-d/go-git.v4/plumbing/transport"
 )
 
 func TestClone_OnGoGitCloner_ShouldReturnRepository(t *testing.T) {
 	clnr := goGitCloner{
-		clonerFunc: func(url string) (*git.Repository, error) {
-			return &git.Repository{}, nil
+		clonerFunc: func(ctx context.Context, storage storage.Storer, options *git.CloneOptions) (*git.Repository, error) {
+			return git.Init(memory.NewStorage(), memfs.New())
 		},
 	}
-	repository, err := clnr.Clone("git@github.com/test/case")
+	checkout, err := clnr.Clone(context.TODO(), "git@github.com/test/case", "")
 
 	assert.Nil(t, err, "error should be nil")
-	assert.NotNil(t, repository, "cloned repository shouldn't be nil")
+	assert.NotNil(t, checkout, "checkout shouldn't be nil")
+	assert.Equal(t, wordcount.Repository{Name: "git@github.com/test/case", URL: "git@github.com/test/case"}, checkout.Repository())
+	assert.NoError(t, checkout.Close())
 }
 func TestClone_OnGoGitClonerWithError_ShouldReturnAnError(t *testing.T) {
 	clnr := goGitCloner{
-		clonerFunc: func(url string) (*git.Repository, error) {
+		clonerFunc: func(ctx context.Context, storage storage.Storer, options *git.CloneOptions) (*git.Repository, error) {
 			return nil, errors.New("Connection error")
 		},
 	}
-	repository, err := clnr.Clone("git@github.com/test/case")
+	checkout, err := clnr.Clone(context.TODO(), "git@github.com/test/case", "")
 
-	assert.Equal(t, wordcount.Repository{}, repository, "cloned repository should be empty")
+	assert.Nil(t, checkout, "checkout should be nil")
 	assert.Equal(t, "Connection error", err.Error())
 }
 
+func TestClone_OnGoGitClonerWithCancelledContext_ShouldReturnAnError(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	clnr := goGitCloner{
+		clonerFunc: goGitClonerFunc,
+	}
+	checkout, err := clnr.Clone(ctx, "https://github.com/eroatta/freqtable", "")
+
+	assert.Nil(t, checkout, "checkout should be nil")
+	assert.Error(t, err)
+}
+
+func TestClone_OnGoGitClonerTwice_ShouldReturnIndependentCheckouts(t *testing.T) {
+	repositories := make(map[string]*git.Repository)
+	for _, name := range []string{"first.go", "second.go"} {
+		fs := memfs.New()
+		fs.Create(name)
+		repository, _ := git.Init(memory.NewStorage(), fs)
+		repositories[name] = repository
+	}
+	clnr := goGitCloner{
+		clonerFunc: func(ctx context.Context, storage storage.Storer, options *git.CloneOptions) (*git.Repository, error) {
+			return repositories[options.URL], nil
+		},
+	}
+
+	first, _ := clnr.Clone(context.TODO(), "first.go", "")
+	second, _ := clnr.Clone(context.TODO(), "second.go", "")
+	firstNames, _ := first.Filenames(context.TODO())
+	secondNames, _ := second.Filenames(context.TODO())
+
+	assert.Equal(t, []string{"first.go"}, firstNames)
+	assert.Equal(t, []string{"second.go"}, secondNames)
+}
+
+func TestClone_OnGoGitClonerWithoutRef_ShouldCheckoutHeadCommit(t *testing.T) {
+	repository, hashes := newTestRepository(t, map[string]string{"main.go": "package main"},
+		map[string]string{"util.go": "package main"})
+	clnr := goGitCloner{
+		clonerFunc: func(ctx context.Context, storage storage.Storer, options *git.CloneOptions) (*git.Repository, error) {
+			return repository, nil
+		},
+	}
+
+	checkout, err := clnr.Clone(context.TODO(), "https://github.com/test/case", "")
+
+	assert.NoError(t, err)
+	assert.Equal(t, hashes[1].String(), checkout.Repository().Hash)
+	assert.Equal(t, commitDate(1).Unix(), checkout.Repository().CommitDate.Unix())
+	names, _ := checkout.Filenames(context.TODO())
+	assert.ElementsMatch(t, []string{"main.go", "util.go"}, names)
+}
+
+func TestClone_OnGoGitClonerWithRef_ShouldCheckoutReferencedCommit(t *testing.T) {
+	tests := []struct {
+		name string
+		ref  string
+	}{
+		{"tag", "v1.0.0"},
+		{"remote branch", "release"},
+		{"commit hash", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repository, hashes := newTestRepository(t, map[string]string{"main.go": "package main"},
+				map[string]string{"util.go": "package main"})
+			if _, err := repository.CreateTag("v1.0.0", hashes[0], nil); err != nil {
+				assert.FailNow(t, fmt.Sprintf("unexpected error creating tag: %v", err))
+			}
+			branch := plumbing.NewHashReference(plumbing.NewRemoteReferenceName("origin", "release"), hashes[0])
+			if err := repository.Storer.SetReference(branch); err != nil {
+				assert.FailNow(t, fmt.Sprintf("unexpected error creating branch: %v", err))
+			}
+			clnr := goGitCloner{
+				clonerFunc: func(ctx context.Context, storage storage.Storer, options *git.CloneOptions) (*git.Repository, error) {
+					return repository, nil
+				},
+				remoteFunc: noRemoteRefs,
+			}
+
+			ref := tt.ref
+			if ref == "" {
+				ref = hashes[0].String()
+			}
+			checkout, err := clnr.Clone(context.TODO(), "https://github.com/test/case", ref)
+
+			assert.NoError(t, err)
+			assert.Equal(t, hashes[0].String(), checkout.Repository().Hash)
+			assert.Equal(t, commitDate(0).Unix(), checkout.Repository().CommitDate.Unix())
+			names, _ := checkout.Filenames(context.TODO())
+			assert.Equal(t, []string{"main.go"}, names)
+		})
+	}
+}
+
+func TestClone_OnGoGitClonerWithUnknownRef_ShouldReturnError(t *testing.T) {
+	repository, _ := newTestRepository(t, map[string]string{"main.go": "package main"})
+	clnr := goGitCloner{
+		clonerFunc: func(ctx context.Context, storage storage.Storer, options *git.CloneOptions) (*git.Repository, error) {
+			return repository, nil
+		},
+		remoteFunc: noRemoteRefs,
+	}
+
+	checkout, err := clnr.Clone(context.TODO(), "https://github.com/test/case", "v9.9.9")
+
+	assert.Nil(t, checkout)
+	assert.Equal(t, wordcount.ErrUnknownRevision, err)
+}
+
+func TestCloneOptions_OnSeveralRefs_ShouldCloneOnlyTheReferencedCommit(t *testing.T) {
+	refs := []*plumbing.Reference{
+		plumbing.NewHashReference(plumbing.NewBranchReferenceName("master"), plumbing.ZeroHash),
+		plumbing.NewHashReference(plumbing.NewBranchReferenceName("release"), plumbing.ZeroHash),
+		plumbing.NewHashReference(plumbing.NewTagReferenceName("v1.0.0"), plumbing.ZeroHash),
+	}
+	clnr := goGitCloner{
+		remoteFunc: func(ctx context.Context, url string, auth transport.AuthMethod) ([]*plumbing.Reference, error) {
+			return refs, nil
+		},
+	}
+
+	tests := []struct {
+		ref  string
+		want *git.CloneOptions
+	}{
+		{"", &git.CloneOptions{URL: "https://github.com/test/case", Depth: 1, SingleBranch: true}},
+		{"release", &git.CloneOptions{URL: "https://github.com/test/case", Depth: 1, SingleBranch: true,
+			ReferenceName: "refs/heads/release"}},
+		{"v1.0.0", &git.CloneOptions{URL: "https://github.com/test/case", Depth: 1, SingleBranch: true,
+			ReferenceName: "refs/tags/v1.0.0"}},
+		{"0a1b2c3d", &git.CloneOptions{URL: "https://github.com/test/case"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.ref, func(t *testing.T) {
+			options, err := clnr.cloneOptions(context.TODO(), "https://github.com/test/case", tt.ref, nil)
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, options)
+		})
+	}
+}
+
+func TestClone_OnGoGitClonerWithRepositoryOverMaxSize_ShouldReturnError(t *testing.T) {
+	dir := tempDir(t)
+	defer os.RemoveAll(dir)
+	commitFiles(t, newServedRepository(t, dir), map[string]string{"main.go": "package main"})
+
+	checkout, err := New(nil, 10).Clone(context.TODO(), dir, "")
+	assert.Nil(t, checkout)
+	assert.Equal(t, wordcount.ErrRepositoryTooLarge, err)
+
+	checkout, err = New(nil, 1024).Clone(context.TODO(), dir, "")
+	assert.NoError(t, err)
+	assert.NoError(t, checkout.Close())
+}
+
+func TestPackfileWriter_OnLimitedStorageOverMaxSize_ShouldCancelTransferAndDiscardBytes(t *testing.T) {
+	var cancelled bool
+	storage := newLimitedStorage(4, func() { cancelled = true })
+	w, err := storage.PackfileWriter()
+	assert.NoError(t, err)
+
+	n, err := w.Write([]byte("PACK\x00\x00\x00\x02"))
+	assert.NoError(t, err)
+	assert.Equal(t, 8, n)
+	assert.True(t, storage.isExceeded())
+	assert.True(t, cancelled)
+
+	n, err = w.Write([]byte("discarded"))
+	assert.NoError(t, err)
+	assert.Equal(t, 9, n)
+	assert.Error(t, w.Close())
+}
+
+// noRemoteRefs lists no references, so every ref is looked up on the full history.
+func noRemoteRefs(ctx context.Context, url string, auth transport.AuthMethod) ([]*plumbing.Reference, error) {
+	return nil, nil
+}
+
+// newTestRepository creates a repository on memory with a commit for each given set of files,
+// and returns it along with the hash of every commit.
+func newTestRepository(t *testing.T, commits ...map[string]string) (*git.Repository, []plumbing.Hash) {
+	repository, err := git.Init(memory.NewStorage(), memfs.New())
+	if err != nil {
+		assert.FailNow(t, fmt.Sprintf("unexpected error creating repository: %v", err))
+	}
+
+	return repository, commitFiles(t, repository, commits...)
+}
+
+// commitFiles writes each given set of files on the worktree and commits them, returning the
+// hash of every commit.
+func commitFiles(t *testing.T, repository *git.Repository, commits ...map[string]string) []plumbing.Hash {
+	wt, _ := repository.Worktree()
+
+	hashes := make([]plumbing.Hash, 0, len(commits))
+	for i, files := range commits {
+		for name, content := range files {
+			if err := util.WriteFile(wt.Filesystem, name, []byte(content), 0644); err != nil {
+				assert.FailNow(t, fmt.Sprintf("unexpected error writing file: %v", err))
+			}
+			wt.Add(name)
+		}
+
+		hash, err := wt.Commit(fmt.Sprintf("commit %d", i), &git.CommitOptions{
+			Author: &object.Signature{Name: "test", Email: "test@example.com", When: commitDate(i)},
+		})
+		if err != nil {
+			assert.FailNow(t, fmt.Sprintf("unexpected error committing: %v", err))
+		}
+		hashes = append(hashes, hash)
+	}
+
+	return hashes
+}
+
+// commitDate provides the date of the i-th commit created by newTestRepository.
+func commitDate(i int) time.Time {
+	return time.Date(2020, time.January, 1+i, 12, 0, 0, 0, time.UTC)
+}
+
 func TestFilenames_OnClonedRepositoryWith5Files_ShouldReturn5Names(t *testing.T) {
 	// given a filesystem and a set of files on a repository
 	fs := memfs.New()
@@ -44,12 +279,12 @@ func TestFilenames_OnClonedRepositoryWith5Files_ShouldReturn5Names(t *testing.T)
 		fs.Create(name)
 	}
 	repository, err := git.Init(memory.NewStorage(), fs)
-	clnr := goGitCloner{
+	checkout := goGitCheckout{
 		repository: repository,
 	}
 
 	// when we retrieve all the files from the repository
-	got, err := clnr.Filenames()
+	got, err := checkout.Filenames(context.TODO())
 
 	assert.Nil(t, err, "error while retrieving the files from the repository should be nil")
 	assert.Equal(t, 5, len(got), "number of files must be equal")
@@ -65,12 +300,12 @@ func TestFilenames_OnClonedRepositoryWith2Files1Folder_ShouldReturn2Names(t *tes
 	}
 	fs.MkdirAll("/ignored", 0666)
 	repository, err := git.Init(memory.NewStorage(), fs)
-	clnr := goGitCloner{
+	checkout := goGitCheckout{
 		repository: repository,
 	}
 
 	// when we retrieve all the files from the repository
-	got, err := clnr.Filenames()
+	got, err := checkout.Filenames(context.TODO())
 
 	assert.Nil(t, err, "error while retrieving the files from the repository should be nil")
 	assert.Equal(t, 2, len(got), "number of files must be equal")
@@ -89,12 +324,12 @@ func TestFilenames_OnClonedRepositoryWith3FilesAnd2In1Folder_ShouldReturn3Names(
 	}
 	fs.MkdirAll("ignored", 0666)
 	repository, err := git.Init(memory.NewStorage(), fs)
-	clnr := goGitCloner{
+	checkout := goGitCheckout{
 		repository: repository,
 	}
 
 	// when we retrieve all the files from the repository
-	got, err := clnr.Filenames()
+	got, err := checkout.Filenames(context.TODO())
 
 	assert.Nil(t, err, "error while retrieving the files from the repository should be nil")
 	assert.Equal(t, 3, len(got), "number of files must be equal")
@@ -114,12 +349,12 @@ func TestFilenames_OnClonedRepositoryRepositoryWith1FileAnd2FilesOnSubSubFolder_
 	fs.MkdirAll("ignored", 0666)
 
 	repository, err := git.Init(memory.NewStorage(), fs)
-	clnr := goGitCloner{
+	checkout := goGitCheckout{
 		repository: repository,
 	}
 
 	// when we retrieve all the files from the repository
-	got, err := clnr.Filenames()
+	got, err := checkout.Filenames(context.TODO())
 
 	assert.Nil(t, err, "error while retrieving the files from the repository should be nil")
 	assert.Equal(t, 3, len(got), "number of files must be equal")
@@ -129,12 +364,12 @@ func TestFilenames_OnClonedRepositoryRepositoryWith1FileAnd2FilesOnSubSubFolder_
 func TestFilesnames_OnClonedRepositoryNoFiles_ShouldReturn0Names(t *testing.T) {
 	// given a filesystem but no files on a repository
 	repository, err := git.Init(memory.NewStorage(), memfs.New())
-	clnr := goGitCloner{
+	checkout := goGitCheckout{
 		repository: repository,
 	}
 
 	// when we retrieve all the files from the repository
-	got, err := clnr.Filenames()
+	got, err := checkout.Filenames(context.TODO())
 
 	assert.Nil(t, err, "error while retrieving the files from the repository should be nil")
 	assert.Equal(t, 0, len(got), "number of files must be equal")
@@ -144,12 +379,12 @@ func TestFilesnames_OnClonedRepositoryNoFiles_ShouldReturn0Names(t *testing.T) {
 func TestFilenames_OnClonedRepositoryError_ShouldReturnAnError(t *testing.T) {
 	// given a filesystem but no files on a repository
 	repository, err := git.Init(memory.NewStorage(), nil)
-	clnr := goGitCloner{
+	checkout := goGitCheckout{
 		repository: repository,
 	}
 
 	// when we retrieve all the files from the repository
-	_, err = clnr.Filenames()
+	_, err = checkout.Filenames(context.TODO())
 
 	assert.NotNil(t, err, "error while retrieving the files from the repository should be nil")
 }
@@ -161,26 +396,42 @@ func TestFile_OnClonedRepositoryExistingFile_ShouldReturnAnArrayOfBytes(t *testi
 	_, err = file.Write([]byte("package main"))
 
 	repository, err := git.Init(memory.NewStorage(), fs)
-	clnr := goGitCloner{
+	checkout := goGitCheckout{
 		repository: repository,
 	}
 
 	// when we retrieve all the files from the repository
-	got, err := clnr.File("main.go")
+	got, err := checkout.File(context.TODO(), "main.go")
 
 	assert.Nil(t, err, "error while reading an existing file should be nil")
 	assert.Equal(t, got, []byte("package main"), "raw files should match")
 }
 
+func TestFileSize_OnClonedRepositoryExistingFile_ShouldReturnSize(t *testing.T) {
+	fs := memfs.New()
+	util.WriteFile(fs, "main.go", []byte("package main"), 0644)
+	repository, _ := git.Init(memory.NewStorage(), fs)
+	checkout := goGitCheckout{
+		repository: repository,
+	}
+
+	size, err := checkout.FileSize(context.TODO(), "main.go")
+	assert.NoError(t, err)
+	assert.Equal(t, int64(12), size)
+
+	_, err = checkout.FileSize(context.TODO(), "any_file")
+	assert.EqualError(t, err, "file does not exist")
+}
+
 func TestFile_RepositoryNoFile_ShouldReturnAnError(t *testing.T) {
 	// given a filesystem and but no files on a repository
 	repository, err := git.Init(memory.NewStorage(), memfs.New())
-	clnr := goGitCloner{
+	checkout := goGitCheckout{
 		repository: repository,
 	}
 
 	// when we retrieve all the files from the repository
-	got, err := clnr.File("any_file")
+	got, err := checkout.File(context.TODO(), "any_file")
 
 	assert.NotNil(t, err, "error while reading a non existing file shouldn't be nil")
 	assert.EqualError(t, err, "file does not exist")
@@ -0,0 +1,255 @@
+package cloner
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/eroatta/freqtable/adapter/wordcount"
+	"github.com/eroatta/freqtable/entity"
+	log "github.com/sirupsen/logrus"
+	"gopkg.in/src-d/go-git.v4"
+	"gopkg.in/src-d/go-git.v4/plumbing"
+	"gopkg.in/src-d/go-git.v4/plumbing/object"
+)
+
+// gitHistory holds a Git repository along with its history, and reads the files of any of its
+// commits. The release function, if any, is called once the history is closed.
+type gitHistory struct {
+	name       string
+	repository *git.Repository
+	release    func()
+	once       sync.Once
+}
+
+// CloneHistory clones the repository on memory, along with its whole history. If the objects of
+// the repository exceed the maximum size, ErrRepositoryTooLarge is returned.
+func (c *goGitCloner) CloneHistory(ctx context.Context, url string) (wordcount.History, error) {
+	log.WithField("repository", url).Info("cloning repository history")
+	auth, err := c.credentials.auth(url)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	storage := newLimitedStorage(c.maxSize, cancel)
+	repository, err := c.clonerFunc(ctx, storage, &git.CloneOptions{
+		URL:  url,
+		Auth: auth,
+	})
+	if storage.isExceeded() {
+		return nil, wordcount.ErrRepositoryTooLarge
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &gitHistory{name: url, repository: repository}, nil
+}
+
+// CloneHistory clones the repository into the cache, or fetches its new commits if it's already
+// cached. The repository can't be removed until the history is closed.
+func (c *cachedCloner) CloneHistory(ctx context.Context, url string) (wordcount.History, error) {
+	log.WithField("repository", url).Info("cloning repository history into the cache")
+	repository, release, err := c.open(ctx, url, "")
+	if err != nil {
+		return nil, err
+	}
+
+	return &gitHistory{name: url, repository: repository, release: release}, nil
+}
+
+// CloneHistory opens the Git repository given by a file:// URL or a path, without copying it.
+// Directories that aren't a Git repository have no history, so ErrHistoryUnsupported is returned.
+func (c localCloner) CloneHistory(ctx context.Context, url string) (wordcount.History, error) {
+	log.WithField("repository", url).Info("opening local repository history")
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	repository, err := git.PlainOpen(strings.TrimPrefix(url, fileScheme))
+	if err == git.ErrRepositoryNotExists {
+		return nil, wordcount.ErrHistoryUnsupported
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &gitHistory{name: url, repository: repository}, nil
+}
+
+// Revisions lists either the tagged commits or every N commits of the repository, sorted from the
+// oldest. Tags are sorted by the date of their commits, and bounded by the dates of the From and
+// To commits. Otherwise, the first parent of each commit is followed from To back to From, or to
+// the first commit, taking every N commits along with the newest one. A selection without a
+// positive N takes every commit.
+func (h *gitHistory) Revisions(ctx context.Context, selection entity.RevisionSelection) ([]wordcount.Revision, error) {
+	to, err := resolve(h.repository, selection.To)
+	if err != nil {
+		return nil, err
+	}
+	if to == nil {
+		return nil, fmt.Errorf("repository %s has no commits", h.name)
+	}
+
+	var from *object.Commit
+	if selection.From != "" {
+		if from, err = resolve(h.repository, selection.From); err != nil {
+			return nil, err
+		}
+	}
+
+	if selection.Tags {
+		return h.tags(ctx, from, to)
+	}
+
+	return h.commits(ctx, from, to, selection.Every)
+}
+
+// tags lists the tagged commits whose date is between the dates of the given commits. Annotated
+// tags are peeled to their commits, while tags on other objects are ignored.
+func (h *gitHistory) tags(ctx context.Context, from *object.Commit, to *object.Commit) ([]wordcount.Revision, error) {
+	refs, err := h.repository.Tags()
+	if err != nil {
+		return nil, err
+	}
+	defer refs.Close()
+
+	revisions := make([]wordcount.Revision, 0)
+	err = refs.ForEach(func(ref *plumbing.Reference) error {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		commit, err := h.tagCommit(ref)
+		if err != nil || commit == nil {
+			return err
+		}
+
+		date := commit.Committer.When
+		if (from != nil && date.Before(from.Committer.When)) || date.After(to.Committer.When) {
+			return nil
+		}
+
+		revisions = append(revisions, newRevision(ref.Name().Short(), commit))
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sort.SliceStable(revisions, func(i, j int) bool {
+		if revisions[i].CommitDate.Equal(revisions[j].CommitDate) {
+			return revisions[i].Ref < revisions[j].Ref
+		}
+		return revisions[i].CommitDate.Before(revisions[j].CommitDate)
+	})
+
+	return revisions, nil
+}
+
+// tagCommit provides the commit referenced by a tag, either lightweight or annotated. If the tag
+// doesn't reference a commit, nil is returned.
+func (h *gitHistory) tagCommit(ref *plumbing.Reference) (*object.Commit, error) {
+	tag, err := h.repository.TagObject(ref.Hash())
+	switch err {
+	case nil:
+		commit, err := tag.Commit()
+		if err == object.ErrUnsupportedObject {
+			return nil, nil
+		}
+		return commit, err
+	case plumbing.ErrObjectNotFound:
+		commit, err := h.repository.CommitObject(ref.Hash())
+		if err == plumbing.ErrObjectNotFound {
+			return nil, nil
+		}
+		return commit, err
+	default:
+		return nil, err
+	}
+}
+
+// commits follows the first parent of each commit from the given commit back to the first commit,
+// or to the from commit if given, and takes every N commits from the oldest, along with the newest
+// one. If the from commit isn't reached, ErrUnknownRevision is returned.
+func (h *gitHistory) commits(ctx context.Context, from *object.Commit, to *object.Commit, every int) ([]wordcount.Revision, error) {
+	if every <= 0 {
+		every = 1
+	}
+
+	walked := make([]*object.Commit, 0)
+	commit := to
+	for {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		walked = append(walked, commit)
+		if from != nil && commit.Hash == from.Hash {
+			break
+		}
+		if commit.NumParents() == 0 {
+			if from != nil {
+				log.WithField("from", from.Hash.String()).Debug("the from commit isn't an ancestor of the to commit")
+				return nil, wordcount.ErrUnknownRevision
+			}
+			break
+		}
+
+		parent, err := commit.Parent(0)
+		if err != nil {
+			return nil, err
+		}
+		commit = parent
+	}
+
+	revisions := make([]wordcount.Revision, 0, len(walked)/every+1)
+	for i := len(walked) - 1; i >= 0; i-- {
+		position := len(walked) - 1 - i
+		if position%every == 0 || i == 0 {
+			revisions = append(revisions, newRevision(walked[i].Hash.String(), walked[i]))
+		}
+	}
+
+	return revisions, nil
+}
+
+// newRevision creates the revision of a commit, checked out by the given tag or commit hash.
+func newRevision(ref string, commit *object.Commit) wordcount.Revision {
+	return wordcount.Revision{
+		Ref:        ref,
+		Hash:       commit.Hash.String(),
+		CommitDate: commit.Committer.When,
+	}
+}
+
+// Checkout reads the files of the given branch, tag or commit hash from the repository storage.
+func (h *gitHistory) Checkout(ctx context.Context, ref string) (wordcount.Checkout, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	commit, err := resolve(h.repository, ref)
+	if err != nil {
+		return nil, err
+	}
+	if commit == nil {
+		return nil, fmt.Errorf("repository %s has no commits", h.name)
+	}
+
+	return &commitCheckout{name: h.name, commit: commit}, nil
+}
+
+// Close releases the repository, unlocking it if it's cached.
+func (h *gitHistory) Close() error {
+	if h.release != nil {
+		h.once.Do(h.release)
+	}
+
+	return nil
+}
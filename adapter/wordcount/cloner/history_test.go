@@ -0,0 +1,182 @@
+package cloner
+
+import (
+	"context"
+	"crypto/rand"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/eroatta/freqtable/adapter/wordcount"
+	"github.com/eroatta/freqtable/entity"
+	"github.com/stretchr/testify/assert"
+	git "gopkg.in/src-d/go-git.v4"
+	"gopkg.in/src-d/go-git.v4/plumbing"
+	"gopkg.in/src-d/go-git.v4/plumbing/object"
+	"gopkg.in/src-d/go-git.v4/storage"
+)
+
+func TestRevisions_OnTags_ShouldReturnTaggedCommitsSortedByDate(t *testing.T) {
+	repository, hashes := newTestRepository(t, map[string]string{"main.go": "package main"},
+		map[string]string{"util.go": "package main"}, map[string]string{"extra.go": "package main"})
+	if _, err := repository.CreateTag("v0.2.0", hashes[2], &git.CreateTagOptions{
+		Tagger:  &object.Signature{Name: "test", Email: "test@example.com", When: commitDate(2)},
+		Message: "v0.2.0",
+	}); err != nil {
+		assert.FailNow(t, fmt.Sprintf("unexpected error creating tag: %v", err))
+	}
+	if _, err := repository.CreateTag("v0.1.0", hashes[0], nil); err != nil {
+		assert.FailNow(t, fmt.Sprintf("unexpected error creating tag: %v", err))
+	}
+	history := &gitHistory{name: "https://github.com/test/case", repository: repository}
+
+	revisions, err := history.Revisions(context.TODO(), entity.RevisionSelection{Tags: true})
+	assert.NoError(t, err)
+	assert.Equal(t, []wordcount.Revision{
+		{Ref: "v0.1.0", Hash: hashes[0].String(), CommitDate: revisions[0].CommitDate},
+		{Ref: "v0.2.0", Hash: hashes[2].String(), CommitDate: revisions[1].CommitDate},
+	}, revisions)
+	assert.Equal(t, commitDate(0).Unix(), revisions[0].CommitDate.Unix())
+
+	revisions, err = history.Revisions(context.TODO(), entity.RevisionSelection{Tags: true, From: hashes[1].String()})
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(revisions))
+	assert.Equal(t, "v0.2.0", revisions[0].Ref)
+}
+
+func TestRevisions_OnEveryNCommits_ShouldReturnEveryNCommitsAndTheNewest(t *testing.T) {
+	commits := make([]map[string]string, 5)
+	for i := range commits {
+		commits[i] = map[string]string{fmt.Sprintf("file%d.go", i): "package main"}
+	}
+	repository, hashes := newTestRepository(t, commits...)
+	history := &gitHistory{name: "https://github.com/test/case", repository: repository}
+
+	tests := []struct {
+		name      string
+		selection entity.RevisionSelection
+		expected  []plumbing.Hash
+	}{
+		{"every commit", entity.RevisionSelection{Every: 1}, hashes},
+		{"every 2 commits", entity.RevisionSelection{Every: 2}, []plumbing.Hash{hashes[0], hashes[2], hashes[4]}},
+		{"every 3 commits", entity.RevisionSelection{Every: 3}, []plumbing.Hash{hashes[0], hashes[3], hashes[4]}},
+		{"bounded", entity.RevisionSelection{Every: 2, From: hashes[1].String(), To: hashes[3].String()}, []plumbing.Hash{hashes[1], hashes[3]}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			revisions, err := history.Revisions(context.TODO(), tt.selection)
+
+			assert.NoError(t, err)
+			refs := make([]plumbing.Hash, 0)
+			for _, revision := range revisions {
+				assert.Equal(t, revision.Hash, revision.Ref)
+				refs = append(refs, plumbing.NewHash(revision.Ref))
+			}
+			assert.Equal(t, tt.expected, refs)
+		})
+	}
+}
+
+func TestRevisions_OnUnreachableOrUnknownFrom_ShouldReturnError(t *testing.T) {
+	repository, hashes := newTestRepository(t, map[string]string{"main.go": "package main"},
+		map[string]string{"util.go": "package main"})
+	history := &gitHistory{name: "https://github.com/test/case", repository: repository}
+
+	for _, selection := range []entity.RevisionSelection{
+		{Every: 1, From: hashes[1].String(), To: hashes[0].String()},
+		{Every: 1, From: "v9.9.9"},
+		{Tags: true, To: "v9.9.9"},
+	} {
+		revisions, err := history.Revisions(context.TODO(), selection)
+
+		assert.Nil(t, revisions)
+		assert.Equal(t, wordcount.ErrUnknownRevision, err)
+	}
+}
+
+func TestCloneHistory_OnGoGitCloner_ShouldCheckoutEachRevision(t *testing.T) {
+	repository, hashes := newTestRepository(t, map[string]string{"main.go": "package main"},
+		map[string]string{"util.go": "package main"})
+	var depth int
+	clnr := goGitCloner{
+		clonerFunc: func(ctx context.Context, storage storage.Storer, options *git.CloneOptions) (*git.Repository, error) {
+			depth = options.Depth
+			return repository, nil
+		},
+	}
+
+	history, err := clnr.CloneHistory(context.TODO(), "https://github.com/test/case")
+	assert.NoError(t, err)
+	assert.Equal(t, 0, depth)
+	defer history.Close()
+
+	checkout, err := history.Checkout(context.TODO(), hashes[0].String())
+	assert.NoError(t, err)
+	assert.Equal(t, hashes[0].String(), checkout.Repository().Hash)
+	names, _ := checkout.Filenames(context.TODO())
+	assert.Equal(t, []string{"main.go"}, names)
+	assert.NoError(t, checkout.Close())
+
+	checkout, err = history.Checkout(context.TODO(), "v9.9.9")
+	assert.Nil(t, checkout)
+	assert.Equal(t, wordcount.ErrUnknownRevision, err)
+}
+
+func TestCloneHistory_OnGoGitClonerOverMaxSize_ShouldAbortTransfer(t *testing.T) {
+	dir := tempDir(t)
+	defer os.RemoveAll(dir)
+	// random content can't be compressed, so the fetched packfile exceeds the maximum size
+	large := make([]byte, 128*1024)
+	rand.Read(large)
+	commitFiles(t, newServedRepository(t, dir), map[string]string{"large.go": string(large)})
+
+	history, err := New(nil, 64*1024).(wordcount.HistoryCloner).CloneHistory(context.TODO(), dir)
+	assert.Nil(t, history)
+	assert.Equal(t, wordcount.ErrRepositoryTooLarge, err)
+}
+
+func TestCloneHistory_OnLocalClonerWithPlainDirectory_ShouldReturnError(t *testing.T) {
+	dir := tempDir(t)
+	defer os.RemoveAll(dir)
+	writeFiles(t, dir, map[string]string{"main.go": "package main"})
+
+	history, err := NewLocal().(wordcount.HistoryCloner).CloneHistory(context.TODO(), dir)
+
+	assert.Nil(t, history)
+	assert.Equal(t, wordcount.ErrHistoryUnsupported, err)
+}
+
+func TestCloneHistory_OnCachedCloner_ShouldLockRepositoryUntilClosed(t *testing.T) {
+	dir := tempDir(t)
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "repository")
+	hashes := commitFiles(t, newServedRepository(t, path), map[string]string{"main.go": "package main"},
+		map[string]string{"util.go": "package main"})
+	clnr := NewCached(nil, filepath.Join(dir, "cache"), 0, 0)
+
+	history, err := clnr.(wordcount.HistoryCloner).CloneHistory(context.TODO(), path)
+	assert.NoError(t, err)
+	revisions, err := history.Revisions(context.TODO(), entity.RevisionSelection{Every: 1})
+	assert.NoError(t, err)
+	assert.Equal(t, 2, len(revisions))
+	assert.Equal(t, hashes[1].String(), revisions[1].Hash)
+
+	cloned := make(chan struct{})
+	go func() {
+		checkout, err := clnr.Clone(context.TODO(), path, "")
+		if err == nil {
+			checkout.Close()
+		}
+		close(cloned)
+	}()
+
+	select {
+	case <-cloned:
+		assert.Fail(t, "the repository should be locked by the history")
+	default:
+	}
+	assert.NoError(t, history.Close())
+	<-cloned
+}
@@ -0,0 +1,202 @@
+package cloner
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/eroatta/freqtable/adapter/wordcount"
+	log "github.com/sirupsen/logrus"
+	"gopkg.in/src-d/go-billy.v4"
+	"gopkg.in/src-d/go-billy.v4/osfs"
+	"gopkg.in/src-d/go-git.v4"
+	"gopkg.in/src-d/go-git.v4/plumbing/object"
+)
+
+// fileScheme is the prefix of the URLs referencing a local directory.
+const fileScheme = "file://"
+
+// NewLocal creates and initializes a new cloner for directories and Git repositories on disk.
+func NewLocal() wordcount.Cloner {
+	return localCloner{}
+}
+
+type localCloner struct{}
+
+// dirCheckout holds a directory tree, either read straight from disk or extracted from an archive,
+// and the commit on its HEAD if the directory is the working tree of a Git repository.
+type dirCheckout struct {
+	name   string
+	fs     billy.Filesystem
+	commit *object.Commit
+}
+
+// commitCheckout holds the files of a commit, read from the storage of a Git repository. Since the
+// storage of go-git isn't safe for concurrent use, its objects are read by one caller at a time.
+type commitCheckout struct {
+	name   string
+	commit *object.Commit
+	mu     sync.Mutex
+}
+
+// Clone accesses the directory given by a file:// URL or a path, without copying it. The working
+// tree of a Git repository is read as it is on disk, unless a branch, tag or commit hash is given,
+// in which case the files are read from that commit, leaving the working tree untouched. Bare
+// repositories are always read from a commit, HEAD by default. A ref given for a directory that
+// isn't a Git repository is reported as unknown.
+func (c localCloner) Clone(ctx context.Context, url string, ref string) (wordcount.Checkout, error) {
+	log.WithField("repository", url).WithField("ref", ref).Info("opening local repository")
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	path := strings.TrimPrefix(url, fileScheme)
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	if !info.IsDir() {
+		return nil, fmt.Errorf("%s is not a directory", path)
+	}
+
+	repository, err := git.PlainOpen(path)
+	switch err {
+	case nil:
+		// continue
+	case git.ErrRepositoryNotExists:
+		if ref != "" {
+			return nil, wordcount.ErrUnknownRevision
+		}
+		return &dirCheckout{name: url, fs: osfs.New(path)}, nil
+	default:
+		return nil, err
+	}
+
+	commit, err := resolve(repository, ref)
+	if err != nil {
+		return nil, err
+	}
+
+	wt, err := repository.Worktree()
+	switch {
+	case err == nil && ref == "":
+		return &dirCheckout{name: url, fs: wt.Filesystem, commit: commit}, nil
+	case err != nil && err != git.ErrIsBareRepository:
+		return nil, err
+	case commit == nil:
+		return nil, fmt.Errorf("repository %s has no commits", path)
+	}
+
+	return &commitCheckout{name: url, commit: commit}, nil
+}
+
+// Repository provides the information of the directory, including the commit on HEAD, if any.
+func (c *dirCheckout) Repository() wordcount.Repository {
+	return newRepository(c.name, c.commit)
+}
+
+// Filenames retrieves the list of file names existing on the directory, except the Git directory.
+func (c *dirCheckout) Filenames(ctx context.Context) ([]string, error) {
+	return read(ctx, c.fs, rootDir)
+}
+
+// FileSize provides the size in bytes of a given file, without reading it.
+func (c *dirCheckout) FileSize(ctx context.Context, name string) (int64, error) {
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
+
+	return fileSize(c.fs, name)
+}
+
+// File provides the bytes representation of a given file.
+func (c *dirCheckout) File(ctx context.Context, name string) ([]byte, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	return readFile(c.fs, name)
+}
+
+// Close releases the directory. Since it's read in place or kept on memory, there is nothing to clean up.
+func (c *dirCheckout) Close() error {
+	return nil
+}
+
+// Repository provides the information of the local repository, including the read commit.
+func (c *commitCheckout) Repository() wordcount.Repository {
+	return newRepository(c.name, c.commit)
+}
+
+// Filenames retrieves the list of file names existing on the commit.
+func (c *commitCheckout) Filenames(ctx context.Context) ([]string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	files, err := c.commit.Files()
+	if err != nil {
+		return nil, err
+	}
+	defer files.Close()
+
+	names := make([]string, 0)
+	err = files.ForEach(func(file *object.File) error {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		names = append(names, file.Name)
+		return nil
+	})
+
+	return names, err
+}
+
+// FileSize provides the size in bytes of a given file from the size of its blob, without reading it.
+func (c *commitCheckout) FileSize(ctx context.Context, name string) (int64, error) {
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	file, err := c.commit.File(name)
+	if err != nil {
+		return 0, err
+	}
+
+	return file.Size, nil
+}
+
+// File provides the bytes representation of a given file.
+func (c *commitCheckout) File(ctx context.Context, name string) ([]byte, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	file, err := c.commit.File(name)
+	if err != nil {
+		return nil, err
+	}
+
+	reader, err := file.Reader()
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+
+	return ioutil.ReadAll(reader)
+}
+
+// Close releases the local repository. Since the files are read from its storage, there is
+// nothing to clean up.
+func (c *commitCheckout) Close() error {
+	return nil
+}
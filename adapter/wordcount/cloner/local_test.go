@@ -0,0 +1,198 @@
+package cloner
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/eroatta/freqtable/adapter/wordcount"
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/src-d/go-billy.v4/memfs"
+	"gopkg.in/src-d/go-billy.v4/osfs"
+	git "gopkg.in/src-d/go-git.v4"
+	"gopkg.in/src-d/go-git.v4/plumbing/cache"
+	"gopkg.in/src-d/go-git.v4/storage/filesystem"
+)
+
+func TestClone_OnLocalClonerWithMissingDirectory_ShouldReturnError(t *testing.T) {
+	checkout, err := NewLocal().Clone(context.TODO(), "/freqtable/missing/directory", "")
+
+	assert.Nil(t, checkout)
+	assert.Error(t, err)
+}
+
+func TestClone_OnLocalClonerWithPlainDirectory_ShouldReadFilesFromDisk(t *testing.T) {
+	dir := tempDir(t)
+	defer os.RemoveAll(dir)
+	writeFiles(t, dir, map[string]string{"main.go": "package main", "pkg/util.go": "package pkg"})
+
+	for _, url := range []string{dir, "file://" + dir} {
+		checkout, err := NewLocal().Clone(context.TODO(), url, "")
+
+		assert.NoError(t, err)
+		assert.Equal(t, wordcount.Repository{Name: url, URL: url}, checkout.Repository())
+		names, err := checkout.Filenames(context.TODO())
+		assert.NoError(t, err)
+		assert.ElementsMatch(t, []string{"main.go", "pkg/util.go"}, names)
+		content, err := checkout.File(context.TODO(), "pkg/util.go")
+		assert.NoError(t, err)
+		assert.Equal(t, "package pkg", string(content))
+	}
+}
+
+func TestClone_OnLocalClonerWithPlainDirectoryAndRef_ShouldReturnError(t *testing.T) {
+	dir := tempDir(t)
+	defer os.RemoveAll(dir)
+
+	checkout, err := NewLocal().Clone(context.TODO(), dir, "v1.0.0")
+
+	assert.Nil(t, checkout)
+	assert.Equal(t, wordcount.ErrUnknownRevision, err)
+}
+
+func TestClone_OnLocalClonerWithWorkingTree_ShouldReadFilesFromDiskAtHead(t *testing.T) {
+	dir := tempDir(t)
+	defer os.RemoveAll(dir)
+	repository, err := git.PlainInit(dir, false)
+	if err != nil {
+		assert.FailNow(t, fmt.Sprintf("unexpected error creating repository: %v", err))
+	}
+	hashes := commitFiles(t, repository, map[string]string{"main.go": "package main"})
+	writeFiles(t, dir, map[string]string{"uncommitted.go": "package main"})
+
+	checkout, err := NewLocal().Clone(context.TODO(), dir, "")
+
+	assert.NoError(t, err)
+	assert.Equal(t, hashes[0].String(), checkout.Repository().Hash)
+	assert.Equal(t, commitDate(0).Unix(), checkout.Repository().CommitDate.Unix())
+	names, err := checkout.Filenames(context.TODO())
+	assert.NoError(t, err)
+	assert.ElementsMatch(t, []string{"main.go", "uncommitted.go"}, names)
+}
+
+func TestClone_OnLocalClonerWithWorkingTreeAndRef_ShouldReadFilesFromCommit(t *testing.T) {
+	dir := tempDir(t)
+	defer os.RemoveAll(dir)
+	repository, err := git.PlainInit(dir, false)
+	if err != nil {
+		assert.FailNow(t, fmt.Sprintf("unexpected error creating repository: %v", err))
+	}
+	hashes := commitFiles(t, repository, map[string]string{"main.go": "package main"},
+		map[string]string{"util.go": "package main"})
+	if _, err := repository.CreateTag("v1.0.0", hashes[0], nil); err != nil {
+		assert.FailNow(t, fmt.Sprintf("unexpected error creating tag: %v", err))
+	}
+
+	checkout, err := NewLocal().Clone(context.TODO(), dir, "v1.0.0")
+
+	assert.NoError(t, err)
+	assert.Equal(t, hashes[0].String(), checkout.Repository().Hash)
+	names, err := checkout.Filenames(context.TODO())
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"main.go"}, names)
+	content, err := checkout.File(context.TODO(), "main.go")
+	assert.NoError(t, err)
+	assert.Equal(t, "package main", string(content))
+	size, err := checkout.FileSize(context.TODO(), "main.go")
+	assert.NoError(t, err)
+	assert.Equal(t, int64(12), size)
+
+	// the working tree is left untouched
+	_, err = os.Stat(filepath.Join(dir, "util.go"))
+	assert.NoError(t, err)
+}
+
+func TestClone_OnLocalClonerWithBareRepository_ShouldReadFilesFromCommit(t *testing.T) {
+	dir := tempDir(t)
+	defer os.RemoveAll(dir)
+	repository, err := git.Init(filesystem.NewStorage(osfs.New(dir), cache.NewObjectLRUDefault()), memfs.New())
+	if err != nil {
+		assert.FailNow(t, fmt.Sprintf("unexpected error creating repository: %v", err))
+	}
+	hashes := commitFiles(t, repository, map[string]string{"main.go": "package main"},
+		map[string]string{"pkg/util.go": "package pkg"})
+
+	checkout, err := NewLocal().Clone(context.TODO(), "file://"+dir, "")
+
+	assert.NoError(t, err)
+	assert.Equal(t, hashes[1].String(), checkout.Repository().Hash)
+	names, err := checkout.Filenames(context.TODO())
+	assert.NoError(t, err)
+	assert.ElementsMatch(t, []string{"main.go", "pkg/util.go"}, names)
+
+	checkout, err = NewLocal().Clone(context.TODO(), "file://"+dir, "v9.9.9")
+
+	assert.Nil(t, checkout)
+	assert.Equal(t, wordcount.ErrUnknownRevision, err)
+}
+
+func TestFile_OnLocalClonerWithPackedRepositoryAndSeveralWorkers_ShouldReadEveryFile(t *testing.T) {
+	dir := tempDir(t)
+	defer os.RemoveAll(dir)
+	files := make(map[string]string)
+	for i := 0; i < 50; i++ {
+		files[fmt.Sprintf("pkg%d/file.go", i)] = fmt.Sprintf("package pkg%d", i)
+	}
+	origin := filepath.Join(dir, "origin")
+	commitFiles(t, newServedRepository(t, origin), files)
+
+	// a clone stores the objects on a packfile, read through the shared index of the storage
+	path := filepath.Join(dir, "packed")
+	if _, err := git.PlainClone(path, true, &git.CloneOptions{URL: origin}); err != nil {
+		assert.FailNow(t, fmt.Sprintf("unexpected error cloning repository: %v", err))
+	}
+
+	checkout, err := NewLocal().Clone(context.TODO(), path, "master")
+	assert.NoError(t, err)
+	defer checkout.Close()
+
+	names, err := checkout.Filenames(context.TODO())
+	assert.NoError(t, err)
+	assert.Equal(t, len(files), len(names))
+
+	var wg sync.WaitGroup
+	contents := make([][]byte, len(names))
+	errs := make([]error, len(names))
+	for w := 0; w < 8; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			for i := w; i < len(names); i += 8 {
+				contents[i], errs[i] = checkout.File(context.TODO(), names[i])
+			}
+		}(w)
+	}
+	wg.Wait()
+
+	for i, name := range names {
+		assert.NoError(t, errs[i])
+		assert.Equal(t, files[name], string(contents[i]))
+	}
+}
+
+// tempDir creates a temporary directory, which must be removed by the test.
+func tempDir(t *testing.T) string {
+	dir, err := ioutil.TempDir("", "freqtable")
+	if err != nil {
+		assert.FailNow(t, fmt.Sprintf("unexpected error creating directory: %v", err))
+	}
+
+	return dir
+}
+
+// writeFiles writes the given files on a directory, creating their parent directories.
+func writeFiles(t *testing.T, dir string, files map[string]string) {
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			assert.FailNow(t, fmt.Sprintf("unexpected error creating directory: %v", err))
+		}
+		if err := ioutil.WriteFile(path, []byte(content), 0644); err != nil {
+			assert.FailNow(t, fmt.Sprintf("unexpected error writing file: %v", err))
+		}
+	}
+}
@@ -0,0 +1,41 @@
+//go:build !windows
+// +build !windows
+
+package cloner
+
+import (
+	"os"
+	"syscall"
+)
+
+// lockFile opens the given file, creating it if needed, and locks it among processes, either
+// shared or exclusively. Unless blocking, it fails with errLocked when the lock is held by someone
+// else. The lock is released once the file is closed.
+func lockFile(path string, exclusive bool, block bool) (*os.File, error) {
+	file, err := os.OpenFile(path, os.O_RDONLY|os.O_CREATE, 0644)
+	if err != nil {
+		return nil, err
+	}
+
+	how := syscall.LOCK_SH
+	if exclusive {
+		how = syscall.LOCK_EX
+	}
+	if !block {
+		how |= syscall.LOCK_NB
+	}
+
+	err = syscall.Flock(int(file.Fd()), how)
+	for err == syscall.EINTR {
+		err = syscall.Flock(int(file.Fd()), how)
+	}
+	if err != nil {
+		file.Close()
+		if err == syscall.EWOULDBLOCK {
+			return nil, errLocked
+		}
+		return nil, err
+	}
+
+	return file, nil
+}
@@ -0,0 +1,11 @@
+package cloner
+
+import (
+	"os"
+)
+
+// lockFile opens the given file, creating it if needed. Files aren't locked on Windows, so the
+// cache directory can't be shared among processes.
+func lockFile(path string, exclusive bool, block bool) (*os.File, error) {
+	return os.OpenFile(path, os.O_RDONLY|os.O_CREATE, 0644)
+}
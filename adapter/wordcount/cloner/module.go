@@ -0,0 +1,319 @@
+package cloner
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/eroatta/freqtable/adapter/wordcount"
+	log "github.com/sirupsen/logrus"
+	"gopkg.in/src-d/go-billy.v4/memfs"
+)
+
+const (
+	// defaultProxy is the module proxy used when none is configured.
+	defaultProxy = "https://proxy.golang.org"
+	// latestVersion is the version query resolved to the highest available version.
+	latestVersion = "latest"
+)
+
+// errNotFound indicates that the module proxy doesn't hold the requested file.
+var errNotFound = errors.New("not found on the module proxy")
+
+// NewModule creates and initializes a new cloner for Go modules, which downloads them from the given
+// module proxy. The proxy can be an HTTP or file:// URL, or a GOPROXY list, in which case the first
+// proxy is used. If no proxy is given, proxy.golang.org is used. A module zip larger than the given
+// size in bytes is rejected. A size of zero disables the limit.
+func NewModule(proxy string, maxSize int64) wordcount.Cloner {
+	return moduleCloner{
+		proxy:   proxyURL(proxy),
+		client:  http.DefaultClient,
+		maxSize: maxSize,
+	}
+}
+
+type moduleCloner struct {
+	proxy   string
+	client  *http.Client
+	maxSize int64
+}
+
+// proxyURL picks the first proxy of a GOPROXY list, skipping the direct and off keywords.
+func proxyURL(goproxy string) string {
+	for _, proxy := range strings.FieldsFunc(goproxy, func(r rune) bool { return r == ',' || r == '|' }) {
+		proxy = strings.TrimSpace(proxy)
+		if proxy != "" && proxy != "direct" && proxy != "off" {
+			return strings.TrimSuffix(proxy, "/")
+		}
+	}
+
+	return defaultProxy
+}
+
+// moduleCheckout holds the files of a module, identified by its resolved version, which is
+// reported as the revision of the repository along with the time of the version.
+type moduleCheckout struct {
+	*dirCheckout
+	version string
+	date    time.Time
+}
+
+// Clone downloads the zip of a module given as path@version, such as golang.org/x/tools@v0.1.0,
+// and extracts it on memory. The latest version is resolved to the highest release listed by the
+// proxy. Since a module is identified by its version, a given ref is reported as unknown, as well
+// as a version the proxy doesn't hold. Module paths with empty, dot or dot-dot elements are rejected.
+func (c moduleCloner) Clone(ctx context.Context, url string, ref string) (wordcount.Checkout, error) {
+	log.WithField("module", url).WithField("proxy", c.proxy).Info("downloading module")
+	if ref != "" {
+		return nil, wordcount.ErrUnknownRevision
+	}
+
+	if !wordcount.IsModule(url) {
+		return nil, fmt.Errorf("invalid module %s, use path@version", url)
+	}
+	i := strings.LastIndex(url, "@")
+	module, version := url[:i], url[i+1:]
+
+	escaped, err := escapePath(module)
+	if err != nil {
+		return nil, err
+	}
+
+	if version == latestVersion {
+		version, err = c.latest(ctx, escaped)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	escapedVersion, err := escapePath(version)
+	if err != nil {
+		return nil, err
+	}
+
+	content, err := c.fetch(ctx, escaped+"/@v/"+escapedVersion+".zip")
+	if err == errNotFound {
+		return nil, wordcount.ErrUnknownRevision
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	fs := memfs.New()
+	if err := extractZip(ctx, newEntryWriter(fs, c.maxSize), content, module+"@"+version+"/"); err != nil {
+		return nil, err
+	}
+
+	date, err := c.versionTime(ctx, escaped, escapedVersion)
+	if err != nil {
+		return nil, err
+	}
+
+	return &moduleCheckout{
+		dirCheckout: &dirCheckout{name: module + "@" + version, fs: fs},
+		version:     version,
+		date:        date,
+	}, nil
+}
+
+// Repository provides the information of the module, whose resolved version stands for the hash
+// of its revision.
+func (c *moduleCheckout) Repository() wordcount.Repository {
+	repository := c.dirCheckout.Repository()
+	repository.Hash = c.version
+	repository.CommitDate = c.date
+
+	return repository
+}
+
+// versionTime retrieves the time of a module version from its info file. A proxy without the
+// info file, such as a partial file:// proxy, leaves the time unknown.
+func (c moduleCloner) versionTime(ctx context.Context, module string, version string) (time.Time, error) {
+	content, err := c.fetch(ctx, module+"/@v/"+version+".info")
+	if err == errNotFound {
+		return time.Time{}, nil
+	}
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	var info struct {
+		Time time.Time
+	}
+	if err := json.Unmarshal(content, &info); err != nil {
+		return time.Time{}, err
+	}
+
+	return info.Time, nil
+}
+
+// latest resolves the highest version of a module, preferring releases over pre-releases. If the
+// proxy doesn't list any version, it's asked for the latest one, which may be a pseudo-version.
+func (c moduleCloner) latest(ctx context.Context, module string) (string, error) {
+	list, err := c.fetch(ctx, module+"/@v/list")
+	if err != nil && err != errNotFound {
+		return "", err
+	}
+
+	var release, prerelease string
+	for _, version := range strings.Fields(string(list)) {
+		latest := &release
+		if _, pre := splitVersion(version); pre != "" {
+			latest = &prerelease
+		}
+		if *latest == "" || compareVersions(version, *latest) > 0 {
+			*latest = version
+		}
+	}
+	if release != "" {
+		return release, nil
+	}
+	if prerelease != "" {
+		return prerelease, nil
+	}
+
+	info, err := c.fetch(ctx, module+"/@latest")
+	if err == errNotFound {
+		return "", wordcount.ErrUnknownRevision
+	}
+	if err != nil {
+		return "", err
+	}
+
+	var latestInfo struct {
+		Version string
+	}
+	if err := json.Unmarshal(info, &latestInfo); err != nil {
+		return "", err
+	}
+
+	return latestInfo.Version, nil
+}
+
+// fetch retrieves a file from the module proxy. A missing file is reported as errNotFound, and a
+// file exceeding the maximum size as ErrRepositoryTooLarge.
+func (c moduleCloner) fetch(ctx context.Context, name string) ([]byte, error) {
+	if strings.HasPrefix(c.proxy, fileScheme) {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		file, err := os.Open(filepath.Join(strings.TrimPrefix(c.proxy, fileScheme), filepath.FromSlash(name)))
+		if os.IsNotExist(err) {
+			return nil, errNotFound
+		}
+		if err != nil {
+			return nil, err
+		}
+		defer file.Close()
+
+		return readLimited(file, c.maxSize)
+	}
+
+	url := c.proxy + "/" + name
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := c.client.Do(req.WithContext(ctx))
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+		return readLimited(resp.Body, c.maxSize)
+	case http.StatusNotFound, http.StatusGone:
+		return nil, errNotFound
+	default:
+		return nil, fmt.Errorf("unexpected status %s while downloading %s", resp.Status, url)
+	}
+}
+
+// escapePath applies the case encoding of the module proxy protocol, where every upper-case
+// letter is replaced by an exclamation mark followed by the lower-case letter.
+func escapePath(path string) (string, error) {
+	var b strings.Builder
+	for _, r := range path {
+		switch {
+		case r == '!' || r > unicode.MaxASCII:
+			return "", fmt.Errorf("invalid module path or version %q", path)
+		case unicode.IsUpper(r):
+			b.WriteByte('!')
+			b.WriteRune(unicode.ToLower(r))
+		default:
+			b.WriteRune(r)
+		}
+	}
+
+	return b.String(), nil
+}
+
+// compareVersions compares two semantic versions, such as v1.2.3 or v1.3.0-rc.1, returning a
+// negative number if a precedes b, a positive number if b precedes a, and zero otherwise.
+func compareVersions(a string, b string) int {
+	aCore, aPre := splitVersion(a)
+	bCore, bPre := splitVersion(b)
+
+	if c := compareIdentifiers(aCore, bCore); c != 0 {
+		return c
+	}
+
+	// a release takes precedence over its pre-releases
+	switch {
+	case aPre == bPre:
+		return 0
+	case aPre == "":
+		return 1
+	case bPre == "":
+		return -1
+	}
+
+	return compareIdentifiers(aPre, bPre)
+}
+
+// splitVersion splits a version into its major.minor.patch core and its pre-release, dropping
+// the v prefix and the build metadata.
+func splitVersion(version string) (string, string) {
+	version = strings.TrimPrefix(version, "v")
+	if i := strings.Index(version, "+"); i >= 0 {
+		version = version[:i]
+	}
+
+	if i := strings.Index(version, "-"); i >= 0 {
+		return version[:i], version[i+1:]
+	}
+
+	return version, ""
+}
+
+// compareIdentifiers compares dot-separated identifiers, numerically when both are numbers.
+func compareIdentifiers(a string, b string) int {
+	aIDs, bIDs := strings.Split(a, "."), strings.Split(b, ".")
+	for i := 0; i < len(aIDs) && i < len(bIDs); i++ {
+		aNum, aErr := strconv.Atoi(aIDs[i])
+		bNum, bErr := strconv.Atoi(bIDs[i])
+		switch {
+		case aErr == nil && bErr == nil && aNum != bNum:
+			return aNum - bNum
+		case aErr == nil && bErr != nil:
+			return -1
+		case aErr != nil && bErr == nil:
+			return 1
+		case aErr != nil && bErr != nil && aIDs[i] != bIDs[i]:
+			return strings.Compare(aIDs[i], bIDs[i])
+		}
+	}
+
+	return len(aIDs) - len(bIDs)
+}
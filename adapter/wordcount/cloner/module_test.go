@@ -0,0 +1,175 @@
+package cloner
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/eroatta/freqtable/adapter/wordcount"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewModule_OnSeveralProxies_ShouldUseFirstProxy(t *testing.T) {
+	tests := []struct {
+		goproxy string
+		want    string
+	}{
+		{"", "https://proxy.golang.org"},
+		{"direct", "https://proxy.golang.org"},
+		{"https://goproxy.example.com/", "https://goproxy.example.com"},
+		{"https://goproxy.example.com,https://proxy.golang.org,direct", "https://goproxy.example.com"},
+		{"off|file:///var/goproxy", "file:///var/goproxy"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.goproxy, func(t *testing.T) {
+			assert.Equal(t, tt.want, NewModule(tt.goproxy, 0).(moduleCloner).proxy)
+		})
+	}
+}
+
+func TestClone_OnModuleClonerWithRef_ShouldReturnError(t *testing.T) {
+	checkout, err := NewModule("", 0).Clone(context.TODO(), "golang.org/x/tools@v0.1.0", "master")
+
+	assert.Nil(t, checkout)
+	assert.Equal(t, wordcount.ErrUnknownRevision, err)
+}
+
+func TestClone_OnModuleClonerWithUnknownVersion_ShouldReturnError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusGone)
+	}))
+	defer server.Close()
+
+	checkout, err := NewModule(server.URL, 0).Clone(context.TODO(), "golang.org/x/tools@v9.9.9", "")
+
+	assert.Nil(t, checkout)
+	assert.Equal(t, wordcount.ErrUnknownRevision, err)
+}
+
+func TestClone_OnModuleClonerWithHTTPProxy_ShouldReturnModuleFiles(t *testing.T) {
+	var requested []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requested = append(requested, r.URL.Path)
+		switch r.URL.Path {
+		case "/github.com/!burnt!sushi/toml/@v/list":
+			w.Write([]byte("v0.3.0\nv0.3.1\nv0.4.0-rc.1\nv0.2.0\n"))
+		case "/github.com/!burnt!sushi/toml/@v/v0.3.1.info":
+			w.Write([]byte(`{"Version":"v0.3.1","Time":"2019-01-02T03:04:05Z"}`))
+		case "/github.com/!burnt!sushi/toml/@v/v0.3.1.zip":
+			w.Write(newZip(t, map[string]string{
+				"github.com/BurntSushi/toml@v0.3.1/decode.go":      "package toml",
+				"github.com/BurntSushi/toml@v0.3.1/cmd/tomlv/m.go": "package main",
+				"github.com/BurntSushi/toml@v0.3.1/go.mod":         "module github.com/BurntSushi/toml",
+			}))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	checkout, err := NewModule(server.URL, 0).Clone(context.TODO(), "github.com/BurntSushi/toml@latest", "")
+
+	assert.NoError(t, err)
+	assert.Equal(t, []string{
+		"/github.com/!burnt!sushi/toml/@v/list",
+		"/github.com/!burnt!sushi/toml/@v/v0.3.1.zip",
+		"/github.com/!burnt!sushi/toml/@v/v0.3.1.info",
+	}, requested)
+	assert.Equal(t, wordcount.Repository{
+		Name:       "github.com/BurntSushi/toml@v0.3.1",
+		URL:        "github.com/BurntSushi/toml@v0.3.1",
+		Hash:       "v0.3.1",
+		CommitDate: time.Date(2019, 1, 2, 3, 4, 5, 0, time.UTC),
+	}, checkout.Repository())
+	names, err := checkout.Filenames(context.TODO())
+	assert.NoError(t, err)
+	assert.ElementsMatch(t, []string{"decode.go", "cmd/tomlv/m.go", "go.mod"}, names)
+	content, err := checkout.File(context.TODO(), "decode.go")
+	assert.NoError(t, err)
+	assert.Equal(t, "package toml", string(content))
+}
+
+func TestClone_OnModuleClonerWithFileProxy_ShouldReturnModuleFiles(t *testing.T) {
+	dir := tempDir(t)
+	defer os.RemoveAll(dir)
+	versions := filepath.Join(dir, "golang.org", "x", "text", "@v")
+	if err := os.MkdirAll(versions, 0755); err != nil {
+		assert.FailNow(t, fmt.Sprintf("unexpected error creating directory: %v", err))
+	}
+	archive := newZip(t, map[string]string{"golang.org/x/text@v0.3.0/width/width.go": "package width"})
+	if err := ioutil.WriteFile(filepath.Join(versions, "v0.3.0.zip"), archive, 0644); err != nil {
+		assert.FailNow(t, fmt.Sprintf("unexpected error writing file: %v", err))
+	}
+
+	checkout, err := NewModule("file://"+dir, 0).Clone(context.TODO(), "golang.org/x/text@v0.3.0", "")
+
+	assert.NoError(t, err)
+	assert.Equal(t, "v0.3.0", checkout.Repository().Hash)
+	assert.True(t, checkout.Repository().CommitDate.IsZero())
+	names, err := checkout.Filenames(context.TODO())
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"width/width.go"}, names)
+
+	checkout, err = NewModule("file://"+dir, 0).Clone(context.TODO(), "golang.org/x/text@v0.4.0", "")
+
+	assert.Nil(t, checkout)
+	assert.Equal(t, wordcount.ErrUnknownRevision, err)
+}
+
+func TestClone_OnModuleClonerWithPathOutOfProxy_ShouldReturnError(t *testing.T) {
+	dir := tempDir(t)
+	defer os.RemoveAll(dir)
+	proxy := filepath.Join(dir, "proxy")
+	versions := filepath.Join(dir, "@v")
+	if err := os.MkdirAll(versions, 0755); err != nil {
+		assert.FailNow(t, fmt.Sprintf("unexpected error creating directory: %v", err))
+	}
+	archive := newZip(t, map[string]string{"golang.org/x/..@v1.0.0/secret.go": "package secret"})
+	if err := ioutil.WriteFile(filepath.Join(versions, "v1.0.0.zip"), archive, 0644); err != nil {
+		assert.FailNow(t, fmt.Sprintf("unexpected error writing file: %v", err))
+	}
+
+	for _, module := range []string{"golang.org/x/../..@v1.0.0", "golang.org/x//tools@v1.0.0", "golang.org/x/./tools@v1.0.0"} {
+		checkout, err := NewModule("file://"+proxy, 0).Clone(context.TODO(), module, "")
+
+		assert.Nil(t, checkout)
+		assert.EqualError(t, err, fmt.Sprintf("invalid module %s, use path@version", module))
+	}
+}
+
+func TestCompareVersions_OnSeveralVersions_ShouldFollowSemanticVersioning(t *testing.T) {
+	tests := []struct {
+		a    string
+		b    string
+		want int
+	}{
+		{"v1.0.0", "v1.0.0", 0},
+		{"v1.10.0", "v1.9.0", 1},
+		{"v1.0.0", "v1.0.0-rc.1", 1},
+		{"v1.0.0-rc.2", "v1.0.0-rc.10", -1},
+		{"v1.0.0-alpha", "v1.0.0-alpha.1", -1},
+		{"v1.0.0-1", "v1.0.0-alpha", -1},
+		{"v2.0.0+incompatible", "v1.9.9", 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.a+" "+tt.b, func(t *testing.T) {
+			got := compareVersions(tt.a, tt.b)
+			switch {
+			case tt.want < 0:
+				assert.True(t, got < 0)
+			case tt.want > 0:
+				assert.True(t, got > 0)
+			default:
+				assert.Equal(t, 0, got)
+			}
+		})
+	}
+}
@@ -3,23 +3,41 @@ package wordcount
 import (
 	"go/ast"
 	"go/token"
+	"time"
 )
 
-// Repository holds information of a GitHub repository.
+// Repository holds information of a GitHub repository, including the hash and date of the
+// checked out commit.
 type Repository struct {
 	Name        string
 	URL         string
 	Hash        string
+	CommitDate  time.Time
 	DateCreated string
 	Error       error
 }
 
+// Revision represents a commit on the history of a repository, and the tag or commit hash used
+// to check it out.
+type Revision struct {
+	Ref        string
+	Hash       string
+	CommitDate time.Time
+}
+
 // File represents a file on a code.Repository, and contains a raw representation and
-// a ast.File representation.
+// a ast.File representation. It also keeps the size of the raw representation, the time
+// spent reading and parsing it, and whether it was excluded after parsing. The group holds the
+// path of the module or package the file belongs to, if the extraction is split.
 type File struct {
-	Name    string
-	Raw     []byte
-	AST     *ast.File
-	FileSet *token.FileSet
-	Error   error
+	Name      string
+	Group     string
+	Raw       []byte
+	AST       *ast.File
+	FileSet   *token.FileSet
+	Error     error
+	Excluded  bool
+	Size      int64
+	ReadTime  time.Duration
+	ParseTime time.Duration
 }
@@ -0,0 +1,157 @@
+package wordcount
+
+import (
+	"go/ast"
+	"path"
+	"regexp"
+	"strings"
+
+	"github.com/eroatta/freqtable/entity"
+)
+
+// generatedCodeComment matches the comment that identifies a generated Go file, as defined
+// on https://golang.org/s/generatedcode.
+var generatedCodeComment = regexp.MustCompile(`^// Code generated .* DO NOT EDIT\.$`)
+
+// resolveOptions completes the requested options with the given defaults.
+func resolveOptions(defaults entity.ExtractionOptions, requested entity.ExtractionOptions) entity.ExtractionOptions {
+	resolved := requested
+	if resolved.Include == nil {
+		resolved.Include = defaults.Include
+	}
+	if resolved.Exclude == nil {
+		resolved.Exclude = defaults.Exclude
+	}
+	if resolved.SkipGenerated == nil {
+		skip := defaults.SkipGenerated != nil && *defaults.SkipGenerated
+		resolved.SkipGenerated = &skip
+	}
+	if resolved.TestFiles == "" {
+		resolved.TestFiles = defaults.TestFiles
+	}
+	if resolved.TestFiles == "" {
+		resolved.TestFiles = entity.TestFilesInclude
+	}
+	if resolved.Granularity == "" {
+		resolved.Granularity = defaults.Granularity
+	}
+	if resolved.Granularity == "" {
+		resolved.Granularity = entity.GranularityRepository
+	}
+
+	return resolved
+}
+
+// fileFilter decides which files are mined, based on the extraction options.
+type fileFilter struct {
+	include       []string
+	exclude       []string
+	skipGenerated bool
+	testFiles     entity.TestFilesMode
+}
+
+// newFileFilter creates a fileFilter for the given resolved options.
+func newFileFilter(options entity.ExtractionOptions) fileFilter {
+	return fileFilter{
+		include:       options.Include,
+		exclude:       options.Exclude,
+		skipGenerated: options.SkipGenerated != nil && *options.SkipGenerated,
+		testFiles:     options.TestFiles,
+	}
+}
+
+// isGoFile checks if the file contains Go source code.
+func isGoFile(name string) bool {
+	return strings.HasSuffix(name, ".go")
+}
+
+// accepts checks if a Go file must be mined, based on the test files mode and the include and
+// exclude patterns. If there are include patterns, the file must match at least one of them.
+func (f fileFilter) accepts(name string) bool {
+	isTest := strings.HasSuffix(name, "_test.go")
+	switch {
+	case f.testFiles == entity.TestFilesExclude && isTest:
+		return false
+	case f.testFiles == entity.TestFilesOnly && !isTest:
+		return false
+	}
+
+	if len(f.include) > 0 && !matchAny(f.include, name) {
+		return false
+	}
+
+	return !matchAny(f.exclude, name)
+}
+
+// excludesGenerated checks if the given parsed file must be ignored because it was generated.
+func (f fileFilter) excludesGenerated(file *ast.File) bool {
+	return f.skipGenerated && isGenerated(file)
+}
+
+// isGenerated checks if the file has the generated code comment before its package clause.
+func isGenerated(file *ast.File) bool {
+	for _, group := range file.Comments {
+		if group.Pos() >= file.Package {
+			return false
+		}
+
+		for _, comment := range group.List {
+			if generatedCodeComment.MatchString(comment.Text) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// matchAny checks if the name matches any of the given patterns.
+func matchAny(patterns []string, name string) bool {
+	for _, pattern := range patterns {
+		if matchPattern(pattern, name) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// matchPattern checks if a slash-separated path matches a glob pattern. Besides the path.Match
+// syntax, a "**" element matches any number of directories. A pattern without slashes matches
+// a file or directory name at any depth, so "vendor" excludes every file under a vendor directory.
+func matchPattern(pattern string, name string) bool {
+	pattern = strings.Trim(pattern, "/")
+	if !strings.Contains(pattern, "/") {
+		pattern = "**/" + pattern
+		if matchElements(strings.Split(pattern+"/**", "/"), strings.Split(name, "/")) {
+			return true
+		}
+	}
+
+	return matchElements(strings.Split(pattern, "/"), strings.Split(name, "/"))
+}
+
+func matchElements(pattern []string, name []string) bool {
+	if len(pattern) == 0 {
+		return len(name) == 0
+	}
+
+	if pattern[0] == "**" {
+		for i := 0; i <= len(name); i++ {
+			if matchElements(pattern[1:], name[i:]) {
+				return true
+			}
+		}
+		return false
+	}
+
+	if len(name) == 0 {
+		return false
+	}
+
+	if matched, err := path.Match(pattern[0], name[0]); err != nil || !matched {
+		return false
+	}
+
+	return matchElements(pattern[1:], name[1:])
+}
@@ -0,0 +1,124 @@
+package wordcount
+
+import (
+	"go/parser"
+	"go/token"
+	"testing"
+
+	"github.com/eroatta/freqtable/entity"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMatchPattern_OnSeveralPatterns_ShouldMatchPaths(t *testing.T) {
+	tests := []struct {
+		pattern string
+		name    string
+		want    bool
+	}{
+		{"vendor", "vendor/github.com/pkg/errors/errors.go", true},
+		{"vendor", "internal/vendor/lib.go", true},
+		{"vendor", "vendoring.go", false},
+		{"*.pb.go", "api/v1/service.pb.go", true},
+		{"*.pb.go", "api/v1/service.go", false},
+		{"cmd/*.go", "cmd/main.go", true},
+		{"cmd/*.go", "cmd/tool/main.go", false},
+		{"cmd/**/*.go", "cmd/tool/main.go", true},
+		{"cmd/**/*.go", "cmd/main.go", true},
+		{"**/testdata/**", "pkg/testdata/input.go", true},
+		{"internal/", "internal/util.go", true},
+		{"[", "main.go", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.pattern+" "+tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, matchPattern(tt.pattern, tt.name))
+		})
+	}
+}
+
+func TestAccepts_OnTestFilesModes_ShouldSelectFiles(t *testing.T) {
+	include := newFileFilter(entity.ExtractionOptions{TestFiles: entity.TestFilesInclude})
+	exclude := newFileFilter(entity.ExtractionOptions{TestFiles: entity.TestFilesExclude})
+	only := newFileFilter(entity.ExtractionOptions{TestFiles: entity.TestFilesOnly})
+
+	assert.True(t, include.accepts("main.go"))
+	assert.True(t, include.accepts("main_test.go"))
+	assert.True(t, exclude.accepts("main.go"))
+	assert.False(t, exclude.accepts("main_test.go"))
+	assert.False(t, only.accepts("main.go"))
+	assert.True(t, only.accepts("main_test.go"))
+}
+
+func TestAccepts_OnIncludeAndExcludePatterns_ShouldApplyBoth(t *testing.T) {
+	filter := newFileFilter(entity.ExtractionOptions{
+		Include: []string{"pkg/**", "cmd/**"},
+		Exclude: []string{"*.pb.go"},
+	})
+
+	assert.True(t, filter.accepts("pkg/api/handler.go"))
+	assert.True(t, filter.accepts("cmd/main.go"))
+	assert.False(t, filter.accepts("pkg/api/handler.pb.go"))
+	assert.False(t, filter.accepts("main.go"))
+}
+
+func TestIsGenerated_OnGeneratedCodeComment_ShouldReturnTrue(t *testing.T) {
+	tests := []struct {
+		name string
+		src  string
+		want bool
+	}{
+		{"generated", "// Code generated by protoc-gen-go. DO NOT EDIT.\n\npackage api", true},
+		{"generated after license", "// Copyright 2020\n\n// Code generated by stringer; DO NOT EDIT.\n\npackage api", true},
+		{"comment after package clause", "package api\n\n// Code generated by protoc-gen-go. DO NOT EDIT.\n", false},
+		{"not matching comment", "// Code generated by hand, please edit.\n\npackage api", false},
+		{"no comments", "package api", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			node, err := parser.ParseFile(token.NewFileSet(), "api.go", tt.src, parser.ParseComments)
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, isGenerated(node))
+		})
+	}
+}
+
+func TestResolveOptions_OnUnsetFields_ShouldUseDefaults(t *testing.T) {
+	skip := true
+	defaults := entity.ExtractionOptions{
+		Exclude:       []string{"vendor"},
+		SkipGenerated: &skip,
+	}
+
+	resolved := resolveOptions(defaults, entity.ExtractionOptions{Include: []string{"cmd/**"}})
+
+	assert.Equal(t, []string{"cmd/**"}, resolved.Include)
+	assert.Equal(t, []string{"vendor"}, resolved.Exclude)
+	assert.True(t, *resolved.SkipGenerated)
+	assert.Equal(t, entity.TestFilesInclude, resolved.TestFiles)
+	assert.Equal(t, entity.GranularityRepository, resolved.Granularity)
+}
+
+func TestResolveOptions_OnSetFields_ShouldOverrideDefaults(t *testing.T) {
+	skip, noSkip := true, false
+	defaults := entity.ExtractionOptions{
+		Exclude:       []string{"vendor"},
+		SkipGenerated: &skip,
+		TestFiles:     entity.TestFilesExclude,
+		Granularity:   entity.GranularityModule,
+	}
+
+	resolved := resolveOptions(defaults, entity.ExtractionOptions{
+		Exclude:       []string{},
+		SkipGenerated: &noSkip,
+		TestFiles:     entity.TestFilesOnly,
+		Granularity:   entity.GranularityPackage,
+	})
+
+	assert.Nil(t, resolved.Include)
+	assert.Equal(t, []string{}, resolved.Exclude)
+	assert.False(t, *resolved.SkipGenerated)
+	assert.Equal(t, entity.TestFilesOnly, resolved.TestFiles)
+	assert.Equal(t, entity.GranularityPackage, resolved.Granularity)
+}
@@ -0,0 +1,59 @@
+package wordcount
+
+import (
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/eroatta/freqtable/entity"
+)
+
+// fileGrouper assigns each Go file to the module or package it belongs to, based on the
+// granularity of the extraction.
+type fileGrouper struct {
+	granularity entity.Granularity
+	modules     []string
+}
+
+// newFileGrouper creates a fileGrouper for the given granularity. The Go modules are found on the
+// given files, as the directories holding a go.mod file.
+func newFileGrouper(granularity entity.Granularity, files []string) fileGrouper {
+	modules := make([]string, 0)
+	if granularity == entity.GranularityModule {
+		for _, f := range files {
+			if path.Base(f) == "go.mod" {
+				modules = append(modules, path.Dir(f))
+			}
+		}
+	}
+
+	// the longest paths go first, so nested modules are matched before their parents
+	sort.Slice(modules, func(i, j int) bool {
+		return len(modules[i]) > len(modules[j])
+	})
+
+	return fileGrouper{
+		granularity: granularity,
+		modules:     modules,
+	}
+}
+
+// group provides the path of the module or package of the given file, relative to the repository
+// root. Files outside of any module belong to the root. If the whole repository is a single unit,
+// every file belongs to the empty group.
+func (g fileGrouper) group(name string) string {
+	dir := path.Dir(name)
+	switch g.granularity {
+	case entity.GranularityPackage:
+		return dir
+	case entity.GranularityModule:
+		for _, module := range g.modules {
+			if module == "." || dir == module || strings.HasPrefix(dir, module+"/") {
+				return module
+			}
+		}
+		return "."
+	default:
+		return ""
+	}
+}
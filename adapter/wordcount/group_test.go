@@ -0,0 +1,41 @@
+package wordcount
+
+import (
+	"testing"
+
+	"github.com/eroatta/freqtable/entity"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGroup_OnEachGranularity_ShouldAssignFilesToGroups(t *testing.T) {
+	files := []string{"go.mod", "main.go", "api/go.mod", "api/v1/service.go", "apiclient/client.go",
+		"storage/postgres/db.go", "tools/go.mod", "tools/gen/go.mod", "tools/gen/main.go"}
+	tests := []struct {
+		granularity entity.Granularity
+		name        string
+		want        string
+	}{
+		{entity.GranularityRepository, "api/v1/service.go", ""},
+		{"", "main.go", ""},
+		{entity.GranularityPackage, "main.go", "."},
+		{entity.GranularityPackage, "storage/postgres/db.go", "storage/postgres"},
+		{entity.GranularityModule, "main.go", "."},
+		{entity.GranularityModule, "api/v1/service.go", "api"},
+		{entity.GranularityModule, "apiclient/client.go", "."},
+		{entity.GranularityModule, "storage/postgres/db.go", "."},
+		{entity.GranularityModule, "tools/gen/main.go", "tools/gen"},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.granularity)+" "+tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, newFileGrouper(tt.granularity, files).group(tt.name))
+		})
+	}
+}
+
+func TestGroup_OnModuleGranularityWithoutRootModule_ShouldAssignOuterFilesToRoot(t *testing.T) {
+	grouper := newFileGrouper(entity.GranularityModule, []string{"cmd/main.go", "lib/go.mod", "lib/lib.go"})
+
+	assert.Equal(t, ".", grouper.group("cmd/main.go"))
+	assert.Equal(t, "lib", grouper.group("lib/lib.go"))
+}
@@ -1,17 +1,181 @@
 package wordcount
 
-import "go/ast"
-
-// mine traverses each Abstract Syntax Tree and applies every given miner to extract
-// the required pre-processing information. It returns the miner after work is done.
-func mine(parsed []File, miner Miner) Miner {
-	for _, f := range parsed {
-		if f.AST == nil {
-			continue
+import (
+	"context"
+	"fmt"
+	"go/ast"
+	"go/scanner"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/eroatta/freqtable/entity"
+	log "github.com/sirupsen/logrus"
+)
+
+// mine traverses each Abstract Syntax Tree as soon as it's received, and drops it afterwards.
+// Every worker applies its own miners, one for each group of files, created by the given factory,
+// and the miners are merged once every file was mined. It returns the miner of the whole set of
+// files, the miner of each group, unless every file belongs to the empty group, and the report
+// of the received files, or the context error if it's done before mining every file. Excluded
+// files are only counted. If a file exceeded the limits, ErrRepositoryTooLarge is returned.
+func mine(ctx context.Context, parsedc <-chan File, newMiner MinerFactory, workers int) (Miner, map[string]Miner, entity.ExtractionReport, error) {
+	groups := make([]map[string]Miner, workers)
+	reports := make([]entity.ExtractionReport, workers)
+	tooLarge := make([]bool, workers)
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		groups[i] = make(map[string]Miner)
+
+		wg.Add(1)
+		go func(miners map[string]Miner, report *entity.ExtractionReport, tooLarge *bool) {
+			defer wg.Done()
+			for f := range parsedc {
+				report.Bytes += f.Size
+				report.ReadDuration += f.ReadTime
+				report.ParseDuration += f.ParseTime
+
+				if f.Error == ErrRepositoryTooLarge {
+					*tooLarge = true
+					continue
+				}
+
+				if f.Error != nil {
+					log.WithError(f.Error).Error(fmt.Sprintf("error when trying to parse file %s", f.Name))
+					report.SkippedFiles = append(report.SkippedFiles, skipped(f))
+					continue
+				}
+
+				if f.Excluded {
+					report.ExcludedFiles++
+					continue
+				}
+
+				if f.AST == nil {
+					continue
+				}
+
+				miner, ok := miners[f.Group]
+				if !ok {
+					miner = newMiner()
+					miners[f.Group] = miner
+				}
+
+				start := time.Now()
+				ast.Walk(miner, f.AST)
+				report.MineDuration += time.Since(start)
+				report.ParsedFiles++
+			}
+		}(groups[i], &reports[i], &tooLarge[i])
+	}
+	wg.Wait()
+
+	if err := ctx.Err(); err != nil {
+		return nil, nil, entity.ExtractionReport{}, err
+	}
+
+	for _, exceeded := range tooLarge {
+		if exceeded {
+			return nil, nil, entity.ExtractionReport{}, ErrRepositoryTooLarge
+		}
+	}
+
+	parts, err := mergeGroups(groups)
+	if err != nil {
+		return nil, nil, entity.ExtractionReport{}, err
+	}
+
+	if whole, ok := parts[""]; ok && len(parts) == 1 {
+		return whole, nil, mergeReports(reports), nil
+	}
+	if len(parts) == 0 {
+		return newMiner(), nil, mergeReports(reports), nil
+	}
+
+	paths := make([]string, 0, len(parts))
+	for path := range parts {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	whole := newMiner()
+	for _, path := range paths {
+		if err := whole.Merge(parts[path]); err != nil {
+			return nil, nil, entity.ExtractionReport{}, err
 		}
+	}
+
+	return whole, parts, mergeReports(reports), nil
+}
+
+// mergeGroups joins the miners applied by each worker on the same group of files.
+func mergeGroups(groups []map[string]Miner) (map[string]Miner, error) {
+	byGroup := make(map[string][]Miner)
+	for _, miners := range groups {
+		for group, miner := range miners {
+			byGroup[group] = append(byGroup[group], miner)
+		}
+	}
+
+	merged := make(map[string]Miner, len(byGroup))
+	for group, miners := range byGroup {
+		miner, err := merge(miners)
+		if err != nil {
+			return nil, err
+		}
+		merged[group] = miner
+	}
+
+	return merged, nil
+}
+
+// merge joins the state of the given miners into the first one.
+func merge(miners []Miner) (Miner, error) {
+	for _, other := range miners[1:] {
+		if err := miners[0].Merge(other); err != nil {
+			return nil, err
+		}
+	}
+
+	return miners[0], nil
+}
+
+// mergeReports sums the reports built by each worker. Skipped files are sorted by name.
+func mergeReports(reports []entity.ExtractionReport) entity.ExtractionReport {
+	merged := entity.ExtractionReport{
+		SkippedFiles: make([]entity.SkippedFile, 0),
+	}
+	for _, r := range reports {
+		merged.GoFiles += r.GoFiles
+		merged.ExcludedFiles += r.ExcludedFiles
+		merged.ParsedFiles += r.ParsedFiles
+		merged.SkippedFiles = append(merged.SkippedFiles, r.SkippedFiles...)
+		merged.Bytes += r.Bytes
+		merged.ReadDuration += r.ReadDuration
+		merged.ParseDuration += r.ParseDuration
+		merged.MineDuration += r.MineDuration
+	}
+
+	sort.Slice(merged.SkippedFiles, func(i, j int) bool {
+		return merged.SkippedFiles[i].Name < merged.SkippedFiles[j].Name
+	})
+
+	return merged
+}
+
+// skipped describes why a file couldn't be mined, including the position of the first
+// syntax error if the file couldn't be parsed.
+func skipped(f File) entity.SkippedFile {
+	file := entity.SkippedFile{
+		Name:   f.Name,
+		Reason: f.Error.Error(),
+	}
 
-		ast.Walk(miner, f.AST)
+	if list, ok := f.Error.(scanner.ErrorList); ok && len(list) > 0 {
+		file.Reason = list[0].Msg
+		file.Line = list[0].Pos.Line
+		file.Column = list[0].Pos.Column
 	}
 
-	return miner
+	return file
 }
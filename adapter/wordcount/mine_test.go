@@ -1,33 +1,87 @@
 package wordcount
 
 import (
+	"context"
+	"errors"
 	"go/ast"
 	"go/parser"
 	"go/token"
 	"testing"
+	"time"
 
+	"github.com/eroatta/freqtable/entity"
 	"github.com/stretchr/testify/assert"
 )
 
 func TestMine_OnNoFiles_ShouldReturnMinersWithoutResults(t *testing.T) {
-	processed := mine([]File{}, &miner{name: "empty"})
+	processed, _, report, err := mine(context.TODO(), parsedChannel(), newTestMiner("empty"), 1)
 	emptyMiner, ok := processed.(*miner)
 
+	assert.NoError(t, err)
+	assert.Equal(t, 0, report.ParsedFiles)
 	assert.True(t, ok)
 	assert.NotNil(t, emptyMiner)
 	assert.Equal(t, 0, emptyMiner.visits)
 }
 
 func TestMine_OnFileWithNilAST_ShouldReturnMinersWithoutResults(t *testing.T) {
-	processed := mine([]File{{Name: "main.go"}}, &miner{name: "empty"})
+	processed, _, report, err := mine(context.TODO(), parsedChannel(File{Name: "main.go"}), newTestMiner("empty"), 1)
 	emptyMiner, ok := processed.(*miner)
 
+	assert.NoError(t, err)
+	assert.Equal(t, 0, report.ParsedFiles)
 	assert.True(t, ok)
 	assert.NotNil(t, emptyMiner)
 	assert.Equal(t, 0, emptyMiner.visits)
 }
 
-func TestMine_OnTwoMiners_ShouldReturnResultsBothMiners(t *testing.T) {
+func TestMine_OnFileWithError_ShouldSkipFile(t *testing.T) {
+	processed, _, report, err := mine(context.TODO(),
+		parsedChannel(File{Name: "main.go", Error: errors.New("file does not exist")}), newTestMiner("empty"), 1)
+
+	assert.NoError(t, err)
+	assert.Equal(t, 0, report.ParsedFiles)
+	assert.Equal(t, []entity.SkippedFile{{Name: "main.go", Reason: "file does not exist"}}, report.SkippedFiles)
+	assert.Equal(t, 0, processed.(*miner).visits)
+}
+
+func TestMine_OnFileWithSyntaxError_ShouldReportPosition(t *testing.T) {
+	fset := token.NewFileSet()
+	node, parseErr := parser.ParseFile(fset, "main.go", "package main\n\nfunc main() {\n\tx := \n}", parser.AllErrors)
+
+	_, _, report, err := mine(context.TODO(),
+		parsedChannel(File{Name: "main.go", AST: node, FileSet: fset, Error: parseErr}), newTestMiner("empty"), 1)
+
+	assert.NoError(t, err)
+	assert.Equal(t, []entity.SkippedFile{{Name: "main.go", Reason: "expected operand, found '}'", Line: 5, Column: 1}},
+		report.SkippedFiles)
+}
+
+func TestMine_OnSeveralFiles_ShouldAccumulateReport(t *testing.T) {
+	fset := token.NewFileSet()
+	node, _ := parser.ParseFile(fset, "main.go", `package main`, parser.AllErrors)
+	files := []File{
+		{Name: "main.go", AST: node, FileSet: fset, Size: 12, ReadTime: time.Second, ParseTime: 2 * time.Second},
+		{Name: "b.go", Error: errors.New("permission denied"), Size: 0, ReadTime: time.Second},
+		{Name: "a.go", Error: errors.New("permission denied"), Size: 0, ReadTime: time.Second},
+		{Name: "gen.go", Excluded: true, Size: 20, ReadTime: time.Second, ParseTime: time.Second},
+	}
+
+	_, _, report, err := mine(context.TODO(), parsedChannel(files...), newTestMiner("first"), 2)
+
+	assert.NoError(t, err)
+	assert.Equal(t, 1, report.ExcludedFiles)
+	assert.Equal(t, 1, report.ParsedFiles)
+	assert.Equal(t, int64(32), report.Bytes)
+	assert.Equal(t, 4*time.Second, report.ReadDuration)
+	assert.Equal(t, 3*time.Second, report.ParseDuration)
+	assert.Equal(t, []entity.SkippedFile{
+		{Name: "a.go", Reason: "permission denied"},
+		{Name: "b.go", Reason: "permission denied"},
+	}, report.SkippedFiles)
+}
+
+func TestMine_OnTwoFiles_ShouldReturnResultsForBothFiles(t *testing.T) {
 	/* Created AST:
 	    0  *ast.File {
 	    1  .  Doc: nil
@@ -64,18 +118,93 @@ func TestMine_OnTwoMiners_ShouldReturnResultsBothMiners(t *testing.T) {
 		FileSet: testFileset,
 	}
 
-	testMiner := &miner{name: "first"}
-	processed := mine([]File{file1, file2}, testMiner)
+	processed, _, report, err := mine(context.TODO(), parsedChannel(file1, file2), newTestMiner("first"), 1)
 	firstMiner, ok := processed.(*miner)
 
+	assert.NoError(t, err)
+	assert.Equal(t, 2, report.ParsedFiles)
 	assert.True(t, ok)
 	assert.NotNil(t, firstMiner)
 	assert.Equal(t, 8, firstMiner.visits)
 }
 
+func TestMine_OnSeveralWorkers_ShouldMergeEveryMiner(t *testing.T) {
+	testFileset := token.NewFileSet()
+	files := make([]File, 0)
+	for i := 0; i < 10; i++ {
+		node, _ := parser.ParseFile(testFileset, "main.go", `package main`, parser.AllErrors)
+		files = append(files, File{Name: "main.go", AST: node, FileSet: testFileset})
+	}
+
+	processed, parts, report, err := mine(context.TODO(), parsedChannel(files...), newTestMiner("first"), 4)
+
+	assert.NoError(t, err)
+	assert.Nil(t, parts)
+	assert.Equal(t, 10, report.ParsedFiles)
+	assert.Equal(t, 40, processed.(*miner).visits)
+}
+
+func TestMine_OnGroupedFiles_ShouldReturnMinerForEachGroup(t *testing.T) {
+	testFileset := token.NewFileSet()
+	files := make([]File, 0)
+	for _, group := range []string{".", "api", "api", "storage"} {
+		node, _ := parser.ParseFile(testFileset, "main.go", `package main`, parser.AllErrors)
+		files = append(files, File{Name: "main.go", Group: group, AST: node, FileSet: testFileset})
+	}
+
+	processed, parts, report, err := mine(context.TODO(), parsedChannel(files...), newTestMiner("first"), 2)
+
+	assert.NoError(t, err)
+	assert.Equal(t, 4, report.ParsedFiles)
+	assert.Equal(t, 16, processed.(*miner).visits)
+	assert.Equal(t, 3, len(parts))
+	assert.Equal(t, 4, parts["."].(*miner).visits)
+	assert.Equal(t, 8, parts["api"].(*miner).visits)
+	assert.Equal(t, 4, parts["storage"].(*miner).visits)
+}
+
+func TestMine_OnCancelledContext_ShouldReturnError(t *testing.T) {
+	testFileset := token.NewFileSet()
+	ast1, _ := parser.ParseFile(testFileset, "main.go", `package main`, parser.AllErrors)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	processed, _, report, err := mine(ctx, parsedChannel(File{Name: "main.go", AST: ast1, FileSet: testFileset}),
+		newTestMiner("first"), 1)
+
+	assert.Equal(t, context.Canceled, err)
+	assert.Equal(t, 0, report.ParsedFiles)
+	assert.Nil(t, processed)
+}
+
+func TestMerge_OnFailingMiner_ShouldReturnError(t *testing.T) {
+	processed, err := merge([]Miner{&miner{name: "first"}, &miner{name: "second", err: errors.New("incompatible miner")}})
+
+	assert.EqualError(t, err, "incompatible miner")
+	assert.Nil(t, processed)
+}
+
+// parsedChannel creates a closed channel containing the given files.
+func parsedChannel(files ...File) <-chan File {
+	parsedc := make(chan File, len(files))
+	for _, f := range files {
+		parsedc <- f
+	}
+	close(parsedc)
+
+	return parsedc
+}
+
+func newTestMiner(name string) MinerFactory {
+	return func() Miner {
+		return &miner{name: name}
+	}
+}
+
 type miner struct {
 	name   string
 	visits int
+	err    error
 }
 
 func (m *miner) Name() string {
@@ -87,6 +216,16 @@ func (m *miner) Visit(n ast.Node) ast.Visitor {
 	return m
 }
 
+func (m *miner) Merge(other Miner) error {
+	o := other.(*miner)
+	if o.err != nil {
+		return o.err
+	}
+
+	m.visits += o.visits
+	return nil
+}
+
 func (m *miner) Results() map[string]int {
 	return nil
 }
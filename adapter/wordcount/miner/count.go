@@ -1,11 +1,13 @@
 package miner
 
 import (
+	"fmt"
 	"go/ast"
 	"go/token"
 	"regexp"
 	"strings"
 
+	"github.com/eroatta/freqtable/adapter/wordcount"
 	"github.com/eroatta/token/conserv"
 )
 
@@ -225,6 +227,20 @@ func countOnFile(elem *ast.File) []string {
 	return tokens
 }
 
+// Merge adds the word count of another Count miner.
+func (m Count) Merge(other wordcount.Miner) error {
+	count, ok := other.(Count)
+	if !ok {
+		return fmt.Errorf("unable to merge a %s miner into a count miner", other.Name())
+	}
+
+	for word, times := range count.words {
+		m.words[word] += times
+	}
+
+	return nil
+}
+
 // Results returns the word count.
 func (m Count) Results() map[string]int {
 	return m.words
@@ -7,6 +7,7 @@ import (
 	"go/token"
 	"testing"
 
+	"github.com/eroatta/freqtable/adapter/wordcount"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -860,3 +861,44 @@ func TestResults_OnCountExtractorAfterExtraction_ShouldReturnWordCount(t *testin
 
 	assert.Equal(t, 1, wordCount["main"], fmt.Sprintf("invalid number of occurrences for element: main"))
 }
+
+func TestMerge_OnCount_ShouldAddTheOtherWordCount(t *testing.T) {
+	count := NewCount()
+	count.words["main"] = 1
+	count.words["word"] = 2
+	other := NewCount()
+	other.words["word"] = 3
+	other.words["count"] = 1
+
+	err := count.Merge(other)
+
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]int{"main": 1, "word": 5, "count": 1}, count.Results())
+	assert.Equal(t, map[string]int{"word": 3, "count": 1}, other.Results())
+}
+
+func TestMerge_OnCountWithDifferentMiner_ShouldReturnError(t *testing.T) {
+	count := NewCount()
+
+	err := count.Merge(otherMiner{})
+
+	assert.EqualError(t, err, "unable to merge a other miner into a count miner")
+}
+
+type otherMiner struct{}
+
+func (m otherMiner) Name() string {
+	return "other"
+}
+
+func (m otherMiner) Visit(node ast.Node) ast.Visitor {
+	return m
+}
+
+func (m otherMiner) Merge(other wordcount.Miner) error {
+	return nil
+}
+
+func (m otherMiner) Results() map[string]int {
+	return nil
+}
@@ -1,38 +1,62 @@
 package wordcount
 
 import (
+	"context"
 	"go/parser"
 	"go/token"
+	"sync"
+	"time"
 )
 
-// parse parses a file and creates an Abstract Syntax Tree (AST) representation.
-// It handles and returns a channel of code.File elements.
-func parse(filesc <-chan File) chan File {
-	fset := token.NewFileSet()
-
+// parse parses a file and creates an Abstract Syntax Tree (AST) representation, using the given
+// number of workers. It handles and returns a channel of code.File elements, which is closed
+// when every file was parsed or the context is done. Each file gets its own token.FileSet and
+// its raw content is dropped once parsed, so nothing is retained between files. Files that
+// couldn't be read are sent without parsing, and generated files rejected by the filter are
+// sent as excluded, without their AST.
+func parse(ctx context.Context, filesc <-chan File, filter fileFilter, workers int) chan File {
 	parsedc := make(chan File)
-	go func() {
-		for file := range filesc {
-			node, err := parser.ParseFile(fset, file.Name, file.Raw, parser.ParseComments)
-
-			file.AST = node
-			file.FileSet = fset
-			file.Error = err
-			parsedc <- file
-		}
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for file := range filesc {
+				if ctx.Err() != nil {
+					return
+				}
+
+				if file.Error == nil {
+					start := time.Now()
+					fset := token.NewFileSet()
+					node, err := parser.ParseFile(fset, file.Name, file.Raw, parser.ParseComments)
+
+					file.AST = node
+					file.FileSet = fset
+					file.Error = err
+					file.ParseTime = time.Since(start)
+
+					if err == nil && filter.excludesGenerated(node) {
+						file.AST = nil
+						file.FileSet = nil
+						file.Excluded = true
+					}
+				}
+				file.Raw = nil
+
+				select {
+				case parsedc <- file:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}()
+	}
 
+	go func() {
+		wg.Wait()
 		close(parsedc)
 	}()
 
 	return parsedc
 }
-
-// merge joins files when necessary.
-func merge(parsedc <-chan File) []File {
-	files := make([]File, 0)
-	for file := range parsedc {
-		files = append(files, file)
-	}
-
-	return files
-}
@@ -1,6 +1,9 @@
 package wordcount
 
 import (
+	"context"
+	"errors"
+	"fmt"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -10,7 +13,7 @@ func TestParse_OnClosedChannel_ShouldSendNoElements(t *testing.T) {
 	filesc := make(chan File)
 	close(filesc)
 
-	parsedc := parse(filesc)
+	parsedc := parse(context.TODO(), filesc, fileFilter{}, 1)
 
 	var parsedFiles int
 	for range parsedc {
@@ -30,7 +33,7 @@ func TestParse_OnFileWithError_ShouldSendFileWithErrorMessage(t *testing.T) {
 		close(filesc)
 	}()
 
-	parsedc := parse(filesc)
+	parsedc := parse(context.TODO(), filesc, fileFilter{}, 1)
 
 	files := make([]File, 0)
 	for file := range parsedc {
@@ -41,7 +44,7 @@ func TestParse_OnFileWithError_ShouldSendFileWithErrorMessage(t *testing.T) {
 	assert.EqualError(t, files[0].Error, "failing.go:1:1: expected 'package', found packaaage")
 }
 
-func TestParse_OnTwoFiles_ShouldSendTwoParsedFilesWithSameFileset(t *testing.T) {
+func TestParse_OnTwoFiles_ShouldSendTwoParsedFilesWithTheirOwnFileset(t *testing.T) {
 	filesc := make(chan File)
 	go func() {
 		filesc <- File{
@@ -57,7 +60,7 @@ func TestParse_OnTwoFiles_ShouldSendTwoParsedFilesWithSameFileset(t *testing.T)
 		close(filesc)
 	}()
 
-	parsedc := parse(filesc)
+	parsedc := parse(context.TODO(), filesc, fileFilter{}, 1)
 
 	files := make(map[string]File)
 	for file := range parsedc {
@@ -76,27 +79,54 @@ func TestParse_OnTwoFiles_ShouldSendTwoParsedFilesWithSameFileset(t *testing.T)
 	assert.NotNil(t, testF.FileSet)
 	assert.NoError(t, testF.Error)
 
-	assert.Equal(t, mainF.FileSet, testF.FileSet)
+	assert.NotEqual(t, mainF.FileSet, testF.FileSet)
+	assert.Nil(t, mainF.Raw)
+	assert.Nil(t, testF.Raw)
 }
 
-func TestMerge_OnClosedChannel_ShouldReturnEmptyArray(t *testing.T) {
-	parsedc := make(chan File)
-	close(parsedc)
+func TestParse_OnSeveralWorkers_ShouldParseEveryFile(t *testing.T) {
+	filesc := make(chan File)
+	go func() {
+		for i := 0; i < 20; i++ {
+			filesc <- File{
+				Name: fmt.Sprintf("file%d.go", i),
+				Raw:  []byte(fmt.Sprintf("package pkg%d", i)),
+			}
+		}
+		close(filesc)
+	}()
 
-	got := merge(parsedc)
+	parsedc := parse(context.TODO(), filesc, fileFilter{}, 4)
 
-	assert.Empty(t, got)
+	files := make(map[string]File)
+	for file := range parsedc {
+		files[file.Name] = file
+	}
+
+	assert.Equal(t, 20, len(files))
+	for i := 0; i < 20; i++ {
+		file := files[fmt.Sprintf("file%d.go", i)]
+		assert.NoError(t, file.Error)
+		assert.Equal(t, fmt.Sprintf("pkg%d", i), file.AST.Name.Name)
+	}
 }
 
-func TestMerge_OnTwoFiles_ShouldReturnTwoFiles(t *testing.T) {
-	parsedc := make(chan File)
-	go func() {
-		parsedc <- File{}
-		parsedc <- File{}
-		close(parsedc)
-	}()
+func TestParse_OnFileWithReadError_ShouldSendFileWithoutParsing(t *testing.T) {
+	filesc := make(chan File, 1)
+	filesc <- File{
+		Name:  "missing.go",
+		Error: errors.New("file does not exist"),
+	}
+	close(filesc)
+
+	parsedc := parse(context.TODO(), filesc, fileFilter{}, 1)
 
-	got := merge(parsedc)
+	files := make([]File, 0)
+	for file := range parsedc {
+		files = append(files, file)
+	}
 
-	assert.Equal(t, 2, len(got))
+	assert.Equal(t, 1, len(files))
+	assert.EqualError(t, files[0].Error, "file does not exist")
+	assert.Nil(t, files[0].AST)
 }
@@ -1,9 +1,15 @@
 package wordcount
 
 import (
+	"context"
 	"errors"
 	"fmt"
+	"runtime"
+	"sort"
+	"time"
 
+	"github.com/eroatta/freqtable/entity"
+	"github.com/eroatta/freqtable/repository"
 	log "github.com/sirupsen/logrus"
 )
 
@@ -12,6 +18,14 @@ var (
 	ErrCloningRepository = errors.New("Error while reading/cloning remote repository")
 	// ErrParsingFile indicates an error while converting the source code to its Abstract Syntax Tree representation.
 	ErrParsingFile = errors.New("Error while parsing source code to AST")
+	// ErrUnknownRevision indicates that the requested branch, tag or commit doesn't exist on the repository.
+	ErrUnknownRevision = repository.ErrUnknownRevision
+	// ErrRepositoryTooLarge indicates that the repository exceeds the size, Go files or file size limits.
+	ErrRepositoryTooLarge = repository.ErrRepositoryTooLarge
+	// ErrHistoryUnsupported indicates that the Cloner for the repository can't access its history.
+	ErrHistoryUnsupported = repository.ErrHistoryUnsupported
+	// ErrLocalRepository indicates that the repository is on the local disk, but no LocalCloner is set.
+	ErrLocalRepository = repository.ErrLocalRepository
 )
 
 // Processor handles the logic to extract the word count from a remote source code repository.
@@ -21,38 +35,187 @@ type Processor struct {
 
 // NewProcessor creates a new Processor based on the provided configuration.
 func NewProcessor(config ProcessorConfig) Processor {
+	if config.Workers <= 0 {
+		config.Workers = runtime.NumCPU()
+	}
+
 	return Processor{
 		config: config,
 	}
 }
 
-// Extract explores the source code and applies the processor-defined miner.
-func (p Processor) Extract(url string) (map[string]int, error) {
-	// cloning step
-	_, filesc, err := clone(url, p.config.Cloner)
+// Extract explores the source code at the requested revision and applies the processor-defined
+// miner on the files selected by the given options, completed with the processor defaults. It
+// returns the mining results, split by module or package if requested, the applied options, the
+// extracted revision, if known, and the report of the processed files. If the context is done
+// before finishing, the extraction is stopped and the context error is returned.
+func (p Processor) Extract(ctx context.Context, url string, options entity.ExtractionOptions) (entity.Extraction, error) {
+	cloner, err := p.cloner(url)
+	if err != nil {
+		return entity.Extraction{}, err
+	}
+
+	return p.extract(ctx, url, cloner, resolveOptions(p.config.Options, options))
+}
+
+// ExtractHistory clones the repository once, along with its history, and extracts each revision
+// chosen by the selection, as Extract does, from the oldest revision. Each extraction is handed to
+// fn as soon as it's done, so it isn't kept in memory, and the extractions already handed over are
+// kept by the caller if a later revision fails. An error returned by fn stops the extraction. The
+// options applied on each extraction hold the tag or commit hash of its revision. Revisions
+// without any Go file that can be parsed, such as the first commits of a repository, are left
+// out. If the Cloner for the repository can't access its history, ErrHistoryUnsupported is
+// returned.
+func (p Processor) ExtractHistory(ctx context.Context, url string, selection entity.RevisionSelection, options entity.ExtractionOptions, fn func(entity.Extraction) error) error {
+	chosen, err := p.cloner(url)
+	if err != nil {
+		return err
+	}
+
+	cloner, ok := chosen.(HistoryCloner)
+	if !ok {
+		return ErrHistoryUnsupported
+	}
+
+	history, err := cloner.CloneHistory(ctx, url)
+	if err != nil {
+		return cloneError(ctx, url, err)
+	}
+	defer history.Close()
+
+	revisions, err := history.Revisions(ctx, selection)
 	if err != nil {
-		log.WithError(err).Error(fmt.Sprintf("error reading repository %s", url))
-		return nil, ErrCloningRepository
+		return cloneError(ctx, url, err)
+	}
+	if p.config.Limits.MaxRevisions > 0 && len(revisions) > p.config.Limits.MaxRevisions {
+		return ErrRepositoryTooLarge
 	}
 
-	// parsing & mining steps
-	parsedc := parse(filesc)
-	files := merge(parsedc)
+	options = resolveOptions(p.config.Options, options)
+	for _, revision := range revisions {
+		revisionOptions := options
+		revisionOptions.Ref = revision.Ref
 
-	valid := make([]File, 0)
-	for _, file := range files {
-		if file.Error != nil {
-			log.WithError(file.Error).Error(fmt.Sprintf("error when trying to parse file %s", file.Name))
+		extraction, err := p.extract(ctx, url, historyCloner{history}, revisionOptions)
+		if err == ErrParsingFile {
+			log.WithField("ref", revision.Ref).Warn(fmt.Sprintf("no Go files to mine on revision of %s", url))
 			continue
 		}
-		valid = append(valid, file)
+		if err != nil {
+			return err
+		}
+
+		if err := fn(extraction); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// extract explores the source code accessed by the given Cloner, applying the resolved options.
+func (p Processor) extract(ctx context.Context, url string, cloner Cloner, options entity.ExtractionOptions) (entity.Extraction, error) {
+	start := time.Now()
+	filter := newFileFilter(options)
+
+	// cloning step
+	checkout, cloneReport, filesc, err := clone(ctx, url, options.Ref, cloner, filter, options.Granularity, p.config.Limits, p.config.Workers)
+	if err != nil {
+		return entity.Extraction{}, cloneError(ctx, url, err)
+	}
+	defer checkout.Close()
+	cloneDuration := time.Since(start)
+
+	// parsing & mining steps, where each file is mined as soon as it's parsed
+	parsedc := parse(ctx, filesc, filter, p.config.Workers)
+	miner, parts, report, err := mine(ctx, parsedc, p.config.MinerFactory, p.config.Workers)
+	if err != nil {
+		return entity.Extraction{}, err
 	}
 
 	// if every file can't be parsed, then fail
-	if len(valid) == 0 {
-		return nil, ErrParsingFile
+	if report.ParsedFiles == 0 {
+		return entity.Extraction{}, ErrParsingFile
+	}
+
+	report.Files = cloneReport.Files
+	report.GoFiles = cloneReport.GoFiles
+	report.ExcludedFiles += cloneReport.ExcludedFiles
+	report.CloneDuration = cloneDuration
+	report.TotalDuration = time.Since(start)
+
+	extraction := entity.Extraction{
+		Values:  miner.Results(),
+		Options: options,
+		Report:  report,
+		Parts:   extractionParts(parts),
+	}
+	if repo := checkout.Repository(); repo.Hash != "" {
+		extraction.Revision = &entity.Revision{Hash: repo.Hash, Date: repo.CommitDate}
+	}
+
+	return extraction, nil
+}
+
+// extractionParts provides the word count of each module or package, sorted by path, or nil if the
+// extraction isn't split.
+func extractionParts(miners map[string]Miner) []entity.ExtractionPart {
+	if miners == nil {
+		return nil
+	}
+
+	parts := make([]entity.ExtractionPart, 0, len(miners))
+	for path, miner := range miners {
+		parts = append(parts, entity.ExtractionPart{Path: path, Values: miner.Results()})
+	}
+	sort.Slice(parts, func(i, j int) bool {
+		return parts[i].Path < parts[j].Path
+	})
+
+	return parts
+}
+
+// cloneError provides the error returned when the repository can't be cloned: the context error
+// if it's done, the errors describing the repository, its history or the requested revision, or
+// ErrCloningRepository.
+func cloneError(ctx context.Context, url string, err error) error {
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+	if err == ErrUnknownRevision || err == ErrRepositoryTooLarge || err == ErrHistoryUnsupported {
+		return err
+	}
+
+	log.WithError(err).Error(fmt.Sprintf("error reading repository %s", url))
+	return ErrCloningRepository
+}
+
+// historyCloner checks out the revisions of a cloned History.
+type historyCloner struct {
+	history History
+}
+
+// Clone provides the files of the given revision.
+func (c historyCloner) Clone(ctx context.Context, url string, ref string) (Checkout, error) {
+	return c.history.Checkout(ctx, ref)
+}
+
+// cloner chooses the Cloner for the given URL, using the ModuleCloner for Go modules, the
+// ArchiveCloner for archives and the LocalCloner for local repositories. Without a LocalCloner,
+// local repositories and archives are rejected with ErrLocalRepository.
+func (p Processor) cloner(url string) (Cloner, error) {
+	if p.config.ModuleCloner != nil && IsModule(url) {
+		return p.config.ModuleCloner, nil
+	}
+	if p.config.LocalCloner == nil && isLocal(url) {
+		return nil, ErrLocalRepository
+	}
+	if p.config.ArchiveCloner != nil && isArchive(url) {
+		return p.config.ArchiveCloner, nil
+	}
+	if p.config.LocalCloner != nil && isLocal(url) {
+		return p.config.LocalCloner, nil
 	}
 
-	miningResults := mine(valid, p.config.Miner)
-	return miningResults.Results(), nil
+	return p.config.Cloner, nil
 }
@@ -1,29 +1,102 @@
 package wordcount
 
-import "go/ast"
+import (
+	"context"
+	"go/ast"
+
+	"github.com/eroatta/freqtable/entity"
+)
 
 // ProcessorConfig defines the properties available for configuration for a Processor.
 type ProcessorConfig struct {
 	Cloner Cloner
-	Miner  Miner
+	// LocalCloner accesses the repositories given by a file:// URL or a path. If not set, those
+	// repositories are rejected, so that a server doesn't expose its own files.
+	LocalCloner Cloner
+	// ArchiveCloner accesses the repositories given as a tar.gz or zip archive, either by a URL
+	// or a path. If not set, those repositories are accessed by the Cloner or the LocalCloner.
+	// Archives given by a path are rejected too unless a LocalCloner is set.
+	ArchiveCloner Cloner
+	// ModuleCloner accesses the Go modules given as path@version, such as golang.org/x/tools@v0.1.0.
+	// If not set, those modules are accessed by the LocalCloner or the Cloner.
+	ModuleCloner Cloner
+	MinerFactory MinerFactory
+	// Workers defines the number of goroutines reading and parsing files on each stage.
+	// If not positive, the number of available CPUs is used.
+	Workers int
+	// Options defines the default rules to select the mined files, applied when an extraction
+	// doesn't set them. By default, every Go file is mined.
+	Options entity.ExtractionOptions
+	// Limits defines the maximum number of mined Go files and their maximum size. The size of
+	// the repository is limited by each Cloner.
+	Limits Limits
+}
+
+// Limits defines the guard rails stopping the extraction of a repository that is too large,
+// which fails with ErrRepositoryTooLarge. A zero value disables the limit.
+type Limits struct {
+	// MaxGoFiles is the maximum number of Go files selected to be mined.
+	MaxGoFiles int
+	// MaxFileSize is the maximum size in bytes of a mined file.
+	MaxFileSize int64
+	// MaxRevisions is the maximum number of revisions extracted from the history of a repository.
+	MaxRevisions int
 }
 
-// Cloner interface is used to define a custom cloner.
+// Cloner interface is used to define a custom cloner. It must be safe for concurrent use,
+// since every extraction gets its own Checkout.
 type Cloner interface {
-	// Clone accesses a repository and clones it.
-	Clone(url string) (Repository, error)
+	// Clone accesses a repository and clones it, checking out the given branch, tag or commit
+	// hash. If no ref is given, the default branch is used. It must return ErrUnknownRevision
+	// if the ref doesn't exist, and ErrRepositoryTooLarge if the repository exceeds its size limit.
+	Clone(ctx context.Context, url string, ref string) (Checkout, error)
+}
+
+// HistoryCloner interface is implemented by the Cloners able to access several revisions of a
+// repository from a single clone.
+type HistoryCloner interface {
+	// CloneHistory accesses a repository and clones it, along with its history.
+	CloneHistory(ctx context.Context, url string) (History, error)
+}
+
+// History interface is used to access the revisions of a cloned repository.
+type History interface {
+	// Revisions lists the revisions chosen by the selection, sorted from the oldest. It must return
+	// ErrUnknownRevision if any of the bounding refs doesn't exist.
+	Revisions(ctx context.Context, selection entity.RevisionSelection) ([]Revision, error)
+	// Checkout provides the files of the given branch, tag or commit hash.
+	Checkout(ctx context.Context, ref string) (Checkout, error)
+	// Close releases the resources held by the cloned repository.
+	Close() error
+}
+
+// Checkout interface is used to access the files of a cloned repository.
+type Checkout interface {
+	// Repository provides the information of the cloned repository.
+	Repository() Repository
 	// Filenames retrieves the names of the existing files on a repository.
-	Filenames() ([]string, error)
+	Filenames(ctx context.Context) ([]string, error)
+	// FileSize provides the size in bytes of a given file, without reading it.
+	FileSize(ctx context.Context, name string) (int64, error)
 	// File provides the bytes representation of a given file.
-	File(name string) ([]byte, error)
+	File(ctx context.Context, name string) ([]byte, error)
+	// Close releases the resources held by the cloned repository.
+	Close() error
 }
 
+// MinerFactory creates a new Miner. It's called once per extraction, so each extraction works
+// on its own isolated state.
+type MinerFactory func() Miner
+
 // Miner interface is used to define a custom miner.
 type Miner interface {
 	// Name provides the name of the miner.
 	Name() string
 	// Visit applies the mining logic while traversing the Abstract Syntax Tree.
 	Visit(node ast.Node) ast.Visitor
+	// Merge joins the state of another miner, created by the same factory and applied on a
+	// different set of files. Miners needing cross-file state must combine it here.
+	Merge(other Miner) error
 	// Results provides the mining results.
 	Results() map[string]int
 }
@@ -1,11 +1,18 @@
 package wordcount_test
 
 import (
+	"context"
 	"errors"
+	"fmt"
 	"go/ast"
+	"sync"
+	"sync/atomic"
 	"testing"
+	"time"
 
 	"github.com/eroatta/freqtable/adapter/wordcount"
+	"github.com/eroatta/freqtable/adapter/wordcount/miner"
+	"github.com/eroatta/freqtable/entity"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -22,10 +29,9 @@ func TestExtract_OnProcessorWithFailingCloningStep_ShouldReturnError(t *testing.
 
 	config := wordcount.ProcessorConfig{
 		Cloner: cloner,
-		Miner:  nil,
 	}
 	processor := wordcount.NewProcessor(config)
-	_, err := processor.Extract("https://github.com/eroatta/freqtable")
+	_, err := processor.Extract(context.TODO(), "https://github.com/eroatta/freqtable", entity.ExtractionOptions{})
 
 	assert.EqualError(t, err, wordcount.ErrCloningRepository.Error())
 }
@@ -43,11 +49,11 @@ func TestExtract_OnProcessorWithFailingParsingStep_ShouldReturnError(t *testing.
 	}
 
 	config := wordcount.ProcessorConfig{
-		Cloner: cloner,
-		Miner:  nil,
+		Cloner:       cloner,
+		MinerFactory: func() wordcount.Miner { return testMiner{} },
 	}
 	processor := wordcount.NewProcessor(config)
-	_, err := processor.Extract("https://github.com/eroatta/freqtable")
+	_, err := processor.Extract(context.TODO(), "https://github.com/eroatta/freqtable", entity.ExtractionOptions{})
 
 	assert.EqualError(t, err, wordcount.ErrParsingFile.Error())
 }
@@ -71,15 +77,22 @@ func TestExtract_OnProcessorWithOneFailedFileParsingStep_ShouldReturnReturnValid
 	}
 
 	config := wordcount.ProcessorConfig{
-		Cloner: cloner,
-		Miner:  miner,
+		Cloner:       cloner,
+		MinerFactory: func() wordcount.Miner { return miner },
 	}
 	processor := wordcount.NewProcessor(config)
-	results, err := processor.Extract("https://github.com/eroatta/freqtable")
+	results, err := processor.Extract(context.TODO(), "https://github.com/eroatta/freqtable", entity.ExtractionOptions{})
 
 	assert.NoError(t, err)
-	assert.Equal(t, 1, len(results))
-	assert.Equal(t, 1, results["main"])
+	assert.Equal(t, 1, len(results.Values))
+	assert.Equal(t, 1, results.Values["main"])
+	assert.Equal(t, 2, results.Report.Files)
+	assert.Equal(t, 2, results.Report.GoFiles)
+	assert.Equal(t, 1, results.Report.ParsedFiles)
+	assert.Equal(t, []entity.SkippedFile{{Name: "main.go", Reason: "expected 'package', found packaaaage", Line: 1, Column: 1}},
+		results.Report.SkippedFiles)
+	assert.Equal(t, int64(27), results.Report.Bytes)
+	assert.True(t, results.Report.TotalDuration >= results.Report.CloneDuration)
 }
 
 func TestExtract_OnProcessor_ShouldReturnValidResults(t *testing.T) {
@@ -100,16 +113,455 @@ func TestExtract_OnProcessor_ShouldReturnValidResults(t *testing.T) {
 		},
 	}
 
+	config := wordcount.ProcessorConfig{
+		Cloner:       cloner,
+		MinerFactory: func() wordcount.Miner { return miner },
+	}
+	processor := wordcount.NewProcessor(config)
+	results, err := processor.Extract(context.TODO(), "https://github.com/eroatta/freqtable", entity.ExtractionOptions{})
+
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(results.Values))
+	assert.Equal(t, 1, results.Values["main"])
+}
+
+func TestExtract_OnProcessorWithUnknownRevision_ShouldReturnError(t *testing.T) {
+	cloner := testCloner{
+		err: wordcount.ErrUnknownRevision,
+	}
+
 	config := wordcount.ProcessorConfig{
 		Cloner: cloner,
-		Miner:  miner,
 	}
 	processor := wordcount.NewProcessor(config)
-	results, err := processor.Extract("https://github.com/eroatta/freqtable")
+	_, err := processor.Extract(context.TODO(), "https://github.com/eroatta/freqtable", entity.ExtractionOptions{Ref: "v9.9.9"})
+
+	assert.Equal(t, wordcount.ErrUnknownRevision, err)
+}
+
+func TestExtract_OnProcessorWithLimits_ShouldReturnRepositoryTooLargeError(t *testing.T) {
+	tests := []struct {
+		name   string
+		cloner testCloner
+		limits wordcount.Limits
+	}{
+		{"repository size", testCloner{err: wordcount.ErrRepositoryTooLarge}, wordcount.Limits{}},
+		{"go files", testCloner{filenames: []string{"main.go", "util.go"}}, wordcount.Limits{MaxGoFiles: 1}},
+		{"file size", testCloner{
+			filenames: []string{"main.go"},
+			files:     map[string][]byte{"main.go": []byte("package main")},
+		}, wordcount.Limits{MaxFileSize: 8}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := wordcount.ProcessorConfig{
+				Cloner:       tt.cloner,
+				MinerFactory: func() wordcount.Miner { return testMiner{} },
+				Limits:       tt.limits,
+			}
+			processor := wordcount.NewProcessor(config)
+			_, err := processor.Extract(context.TODO(), "https://github.com/eroatta/freqtable", entity.ExtractionOptions{})
+
+			assert.Equal(t, wordcount.ErrRepositoryTooLarge, err)
+		})
+	}
+}
+
+func TestExtract_OnProcessorWithCheckedOutCommit_ShouldReturnRevision(t *testing.T) {
+	date := time.Date(2020, time.March, 1, 10, 0, 0, 0, time.UTC)
+	cloner := testCloner{
+		repository: wordcount.Repository{
+			Name:       "freqtable",
+			URL:        "https://github.com/eroatta/freqtable",
+			Hash:       "0a1b2c3d4e5f60718293a4b5c6d7e8f901234567",
+			CommitDate: date,
+		},
+		filenames: []string{"main.go"},
+		files: map[string][]byte{
+			"main.go": []byte("package main"),
+		},
+	}
+
+	config := wordcount.ProcessorConfig{
+		Cloner:       cloner,
+		MinerFactory: func() wordcount.Miner { return testMiner{} },
+	}
+	processor := wordcount.NewProcessor(config)
+	results, err := processor.Extract(context.TODO(), "https://github.com/eroatta/freqtable", entity.ExtractionOptions{Ref: "v1.0.0"})
 
 	assert.NoError(t, err)
-	assert.Equal(t, 1, len(results))
-	assert.Equal(t, 1, results["main"])
+	assert.Equal(t, &entity.Revision{Hash: "0a1b2c3d4e5f60718293a4b5c6d7e8f901234567", Date: date}, results.Revision)
+	assert.Equal(t, "v1.0.0", results.Options.Ref)
+}
+
+func TestExtract_OnProcessorWithSeveralCloners_ShouldChooseClonerByURL(t *testing.T) {
+	local := testCloner{
+		filenames: []string{"main.go"},
+		files: map[string][]byte{
+			"main.go": []byte("package main"),
+		},
+	}
+	archive := testCloner{
+		filenames: []string{"main.go", "README.md"},
+		files: map[string][]byte{
+			"main.go": []byte("package main"),
+		},
+	}
+	module := testCloner{
+		filenames: []string{"main.go", "go.mod", "LICENSE"},
+		files: map[string][]byte{
+			"main.go": []byte("package main"),
+		},
+	}
+
+	config := wordcount.ProcessorConfig{
+		Cloner:        testCloner{err: errors.New("HTTP 404 Not Found")},
+		LocalCloner:   local,
+		ArchiveCloner: archive,
+		ModuleCloner:  module,
+		MinerFactory:  func() wordcount.Miner { return testMiner{} },
+	}
+	processor := wordcount.NewProcessor(config)
+
+	tests := []struct {
+		url   string
+		files int
+	}{
+		{"/home/ci/freqtable", 1},
+		{"file:///home/ci/freqtable", 1},
+		{"/home/ci/freqtable.tar.gz", 2},
+		{"https://github.com/eroatta/freqtable/archive/v1.0.0.zip", 2},
+		{"golang.org/x/tools@v0.1.0", 3},
+	}
+
+	for _, tt := range tests {
+		results, err := processor.Extract(context.TODO(), tt.url, entity.ExtractionOptions{})
+
+		assert.NoError(t, err)
+		assert.Equal(t, tt.files, results.Report.Files, tt.url)
+	}
+
+	_, err := processor.Extract(context.TODO(), "https://github.com/eroatta/freqtable", entity.ExtractionOptions{})
+	assert.EqualError(t, err, wordcount.ErrCloningRepository.Error())
+}
+
+func TestExtract_OnProcessorWithoutLocalCloner_ShouldRejectLocalRepositories(t *testing.T) {
+	cloner := testCloner{filenames: []string{"main.go"}, files: map[string][]byte{"main.go": []byte("package main")}}
+	config := wordcount.ProcessorConfig{
+		Cloner:        cloner,
+		ArchiveCloner: cloner,
+		MinerFactory:  func() wordcount.Miner { return testMiner{} },
+	}
+	processor := wordcount.NewProcessor(config)
+
+	for _, url := range []string{"/etc", "file:///etc", "../freqtable", "/var/backups/freqtable.tar.gz", "file:///tmp/freqtable.zip"} {
+		_, err := processor.Extract(context.TODO(), url, entity.ExtractionOptions{})
+		assert.Equal(t, wordcount.ErrLocalRepository, err, url)
+
+		err = processor.ExtractHistory(context.TODO(), url, entity.RevisionSelection{Tags: true}, entity.ExtractionOptions{},
+			func(entity.Extraction) error { return nil })
+		assert.Equal(t, wordcount.ErrLocalRepository, err, url)
+	}
+
+	for _, url := range []string{"https://github.com/eroatta/freqtable", "https://github.com/eroatta/freqtable/archive/v1.0.0.zip"} {
+		_, err := processor.Extract(context.TODO(), url, entity.ExtractionOptions{})
+		assert.NoError(t, err, url)
+	}
+}
+
+func TestExtract_OnProcessorWithOptions_ShouldMineSelectedFilesOnly(t *testing.T) {
+	cloner := testCloner{
+		repository: wordcount.Repository{
+			Name: "freqtable",
+			URL:  "https://github.com/eroatta/freqtable",
+		},
+		filenames: []string{"main.go", "main_test.go", "api/api.pb.go", "vendor/lib/lib.go", "README.md"},
+		files: map[string][]byte{
+			"main.go":           []byte("package main\n\nfunc count() {}"),
+			"main_test.go":      []byte("package main\n\nfunc testCount() {}"),
+			"api/api.pb.go":     []byte("// Code generated by protoc-gen-go. DO NOT EDIT.\n\npackage api\n\nfunc proto() {}"),
+			"vendor/lib/lib.go": []byte("package lib\n\nfunc vendored() {}"),
+		},
+	}
+
+	skip := true
+	config := wordcount.ProcessorConfig{
+		Cloner:       cloner,
+		MinerFactory: func() wordcount.Miner { return miner.NewCount() },
+		Options: entity.ExtractionOptions{
+			Exclude:       []string{"vendor"},
+			SkipGenerated: &skip,
+		},
+	}
+	processor := wordcount.NewProcessor(config)
+	results, err := processor.Extract(context.TODO(), "https://github.com/eroatta/freqtable",
+		entity.ExtractionOptions{TestFiles: entity.TestFilesExclude})
+
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]int{"count": 1}, results.Values)
+	assert.Equal(t, entity.ExtractionOptions{
+		Exclude:       []string{"vendor"},
+		SkipGenerated: &skip,
+		TestFiles:     entity.TestFilesExclude,
+		Granularity:   entity.GranularityRepository,
+	}, results.Options)
+	assert.Equal(t, 5, results.Report.Files)
+	assert.Equal(t, 4, results.Report.GoFiles)
+	assert.Equal(t, 3, results.Report.ExcludedFiles)
+	assert.Equal(t, 1, results.Report.ParsedFiles)
+}
+
+func TestExtract_OnModuleGranularity_ShouldSplitValuesByModule(t *testing.T) {
+	cloner := testCloner{
+		repository: wordcount.Repository{
+			Name: "freqtable",
+			URL:  "https://github.com/eroatta/freqtable",
+		},
+		filenames: []string{"go.mod", "main.go", "api/go.mod", "api/api.go", "api/v1/service.go"},
+		files: map[string][]byte{
+			"main.go":           []byte("package main\n\nfunc count() {}"),
+			"api/api.go":        []byte("package api\n\nfunc count() {}"),
+			"api/v1/service.go": []byte("package v1\n\nfunc serve() {}"),
+		},
+	}
+
+	config := wordcount.ProcessorConfig{
+		Cloner:       cloner,
+		MinerFactory: func() wordcount.Miner { return miner.NewCount() },
+	}
+	processor := wordcount.NewProcessor(config)
+	results, err := processor.Extract(context.TODO(), "https://github.com/eroatta/freqtable",
+		entity.ExtractionOptions{Granularity: entity.GranularityModule})
+
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]int{"count": 2, "serve": 1}, results.Values)
+	assert.Equal(t, entity.GranularityModule, results.Options.Granularity)
+	assert.Equal(t, []entity.ExtractionPart{
+		{Path: ".", Values: map[string]int{"count": 1}},
+		{Path: "api", Values: map[string]int{"count": 1, "serve": 1}},
+	}, results.Parts)
+}
+
+func TestExtract_OnProcessorWithCancelledContext_ShouldReturnContextError(t *testing.T) {
+	cloner := testCloner{
+		repository: wordcount.Repository{
+			Name: "freqtable",
+			URL:  "https://github.com/eroatta/freqtable",
+		},
+		filenames: []string{"main.go"},
+		files: map[string][]byte{
+			"main.go": []byte("package main"),
+		},
+	}
+
+	config := wordcount.ProcessorConfig{
+		Cloner:       cloner,
+		MinerFactory: func() wordcount.Miner { return testMiner{} },
+	}
+	processor := wordcount.NewProcessor(config)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	results, err := processor.Extract(ctx, "https://github.com/eroatta/freqtable", entity.ExtractionOptions{})
+
+	assert.Equal(t, context.Canceled, err)
+	assert.Equal(t, entity.Extraction{}, results)
+}
+
+func TestExtract_OnProcessorWithCancelledCloning_ShouldReturnContextError(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	config := wordcount.ProcessorConfig{
+		Cloner: testCloner{
+			err: ctx.Err(),
+		},
+	}
+	processor := wordcount.NewProcessor(config)
+	results, err := processor.Extract(ctx, "https://github.com/eroatta/freqtable", entity.ExtractionOptions{})
+
+	assert.Equal(t, context.Canceled, err)
+	assert.Equal(t, entity.Extraction{}, results)
+}
+
+func TestExtract_OnProcessorWithConcurrentExtractions_ShouldIsolateResults(t *testing.T) {
+	var closed int32
+	cloner := testCloner{
+		repository: wordcount.Repository{
+			Name: "freqtable",
+			URL:  "https://github.com/eroatta/freqtable",
+		},
+		filenames: []string{"main.go"},
+		files: map[string][]byte{
+			"main.go": []byte("package main\n\nfunc countWords(wordList []string) int { return len(wordList) }"),
+		},
+		closed: &closed,
+	}
+
+	config := wordcount.ProcessorConfig{
+		Cloner:       cloner,
+		MinerFactory: func() wordcount.Miner { return miner.NewCount() },
+	}
+	processor := wordcount.NewProcessor(config)
+
+	expected := map[string]int{"count": 1, "words": 1, "word": 1, "list": 1}
+	var wg sync.WaitGroup
+	results := make([]entity.Extraction, 10)
+	errs := make([]error, 10)
+	for i := range results {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			results[i], errs[i] = processor.Extract(context.TODO(), "https://github.com/eroatta/freqtable", entity.ExtractionOptions{})
+		}(i)
+	}
+	wg.Wait()
+
+	for i := range results {
+		assert.NoError(t, errs[i])
+		assert.Equal(t, expected, results[i].Values)
+	}
+	assert.Equal(t, int32(10), closed)
+}
+
+func TestExtract_OnProcessorWithSeveralWorkers_ShouldReturnSameResultsAsOneWorker(t *testing.T) {
+	cloner := testCloner{
+		repository: wordcount.Repository{
+			Name: "freqtable",
+			URL:  "https://github.com/eroatta/freqtable",
+		},
+		filenames: []string{},
+		files:     map[string][]byte{},
+	}
+	for i := 0; i < 50; i++ {
+		name := fmt.Sprintf("file%d.go", i)
+		cloner.filenames = append(cloner.filenames, name)
+		cloner.files[name] = []byte(fmt.Sprintf("package main\n\nvar wordCount%d = %d\n\nfunc parseFile%d() {}", i%5, i, i%7))
+	}
+
+	extract := func(workers int) map[string]int {
+		processor := wordcount.NewProcessor(wordcount.ProcessorConfig{
+			Cloner:       cloner,
+			MinerFactory: func() wordcount.Miner { return miner.NewCount() },
+			Workers:      workers,
+		})
+		results, err := processor.Extract(context.TODO(), "https://github.com/eroatta/freqtable", entity.ExtractionOptions{})
+		assert.NoError(t, err)
+		return results.Values
+	}
+
+	expected := extract(1)
+	assert.Equal(t, 50, expected["word"])
+	assert.Equal(t, 50, expected["parse"])
+	assert.Equal(t, expected, extract(8))
+}
+
+func TestExtractHistory_OnProcessorWithoutHistoryCloner_ShouldReturnError(t *testing.T) {
+	config := wordcount.ProcessorConfig{
+		Cloner: testCloner{},
+	}
+	processor := wordcount.NewProcessor(config)
+	err := processor.ExtractHistory(context.TODO(), "https://github.com/eroatta/freqtable",
+		entity.RevisionSelection{Tags: true}, entity.ExtractionOptions{}, func(entity.Extraction) error {
+			assert.FailNow(t, "no extraction expected")
+			return nil
+		})
+
+	assert.Equal(t, wordcount.ErrHistoryUnsupported, err)
+}
+
+func TestExtractHistory_OnProcessor_ShouldExtractEachRevisionWithGoFiles(t *testing.T) {
+	var closed int32
+	cloner := testHistoryCloner{
+		revisions: []wordcount.Revision{{Ref: "v0.1.0"}, {Ref: "v0.2.0"}, {Ref: "v0.3.0"}},
+		checkouts: map[string]testCloner{
+			"v0.1.0": {repository: wordcount.Repository{Hash: "a1"}, filenames: []string{"main.go"},
+				files: map[string][]byte{"main.go": []byte("package main")}},
+			"v0.2.0": {repository: wordcount.Repository{Hash: "b2"}, filenames: []string{"README.md"}},
+			"v0.3.0": {repository: wordcount.Repository{Hash: "c3"}, filenames: []string{"main.go"},
+				files: map[string][]byte{"main.go": []byte("package main")}},
+		},
+		testCloner: testCloner{closed: &closed},
+	}
+
+	config := wordcount.ProcessorConfig{
+		Cloner:       cloner,
+		MinerFactory: func() wordcount.Miner { return testMiner{results: map[string]int{"main": 1}} },
+	}
+	processor := wordcount.NewProcessor(config)
+	extractions := make([]entity.Extraction, 0)
+	err := processor.ExtractHistory(context.TODO(), "https://github.com/eroatta/freqtable",
+		entity.RevisionSelection{Tags: true}, entity.ExtractionOptions{Ref: "master"}, func(extraction entity.Extraction) error {
+			extractions = append(extractions, extraction)
+			return nil
+		})
+
+	assert.NoError(t, err)
+	assert.Equal(t, 2, len(extractions))
+	assert.Equal(t, "v0.1.0", extractions[0].Options.Ref)
+	assert.Equal(t, "a1", extractions[0].Revision.Hash)
+	assert.Equal(t, "v0.3.0", extractions[1].Options.Ref)
+	assert.Equal(t, "c3", extractions[1].Revision.Hash)
+	assert.Equal(t, 1, extractions[1].Values["main"])
+	assert.Equal(t, int32(1), atomic.LoadInt32(&closed))
+}
+
+func TestExtractHistory_OnProcessor_WhenHandlerFails_ShouldStopExtraction(t *testing.T) {
+	cloner := testHistoryCloner{
+		revisions: []wordcount.Revision{{Ref: "v0.1.0"}, {Ref: "v0.2.0"}},
+		checkouts: map[string]testCloner{
+			"v0.1.0": {repository: wordcount.Repository{Hash: "a1"}, filenames: []string{"main.go"},
+				files: map[string][]byte{"main.go": []byte("package main")}},
+			"v0.2.0": {repository: wordcount.Repository{Hash: "b2"}, filenames: []string{"main.go"},
+				files: map[string][]byte{"main.go": []byte("package main")}},
+		},
+	}
+
+	config := wordcount.ProcessorConfig{
+		Cloner:       cloner,
+		MinerFactory: func() wordcount.Miner { return testMiner{results: map[string]int{"main": 1}} },
+	}
+	processor := wordcount.NewProcessor(config)
+	refs := make([]string, 0)
+	err := processor.ExtractHistory(context.TODO(), "https://github.com/eroatta/freqtable",
+		entity.RevisionSelection{Tags: true}, entity.ExtractionOptions{}, func(extraction entity.Extraction) error {
+			refs = append(refs, extraction.Options.Ref)
+			return errors.New("error saving extraction")
+		})
+
+	assert.EqualError(t, err, "error saving extraction")
+	assert.Equal(t, []string{"v0.1.0"}, refs)
+}
+
+func TestExtractHistory_OnProcessorWithFailingHistory_ShouldReturnError(t *testing.T) {
+	tests := []struct {
+		name     string
+		cloner   testHistoryCloner
+		limits   wordcount.Limits
+		expected error
+	}{
+		{"failed cloning", testHistoryCloner{testCloner: testCloner{err: errors.New("HTTP 404 Not Found")}}, wordcount.Limits{}, wordcount.ErrCloningRepository},
+		{"unknown revision", testHistoryCloner{revisionsErr: wordcount.ErrUnknownRevision}, wordcount.Limits{}, wordcount.ErrUnknownRevision},
+		{"too many revisions", testHistoryCloner{revisions: []wordcount.Revision{{Ref: "v0.1.0"}, {Ref: "v0.2.0"}}},
+			wordcount.Limits{MaxRevisions: 1}, wordcount.ErrRepositoryTooLarge},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := wordcount.ProcessorConfig{
+				Cloner:       tt.cloner,
+				MinerFactory: func() wordcount.Miner { return testMiner{} },
+				Limits:       tt.limits,
+			}
+			processor := wordcount.NewProcessor(config)
+			err := processor.ExtractHistory(context.TODO(), "https://github.com/eroatta/freqtable",
+				entity.RevisionSelection{Every: 1}, entity.ExtractionOptions{}, func(entity.Extraction) error {
+					assert.FailNow(t, "no extraction expected")
+					return nil
+				})
+
+			assert.Equal(t, tt.expected, err)
+		})
+	}
 }
 
 type testCloner struct {
@@ -117,24 +569,64 @@ type testCloner struct {
 	filenames  []string
 	files      map[string][]byte
 	err        error
+	closed     *int32
 }
 
-func (t testCloner) Clone(url string) (wordcount.Repository, error) {
+func (t testCloner) Clone(ctx context.Context, url string, ref string) (wordcount.Checkout, error) {
 	if t.err != nil {
-		return wordcount.Repository{}, t.err
+		return nil, t.err
 	}
 
-	return t.repository, nil
+	return t, nil
+}
+
+func (t testCloner) Repository() wordcount.Repository {
+	return t.repository
 }
 
-func (t testCloner) Filenames() ([]string, error) {
+func (t testCloner) Filenames(ctx context.Context) ([]string, error) {
 	return t.filenames, nil
 }
 
-func (t testCloner) File(name string) ([]byte, error) {
+func (t testCloner) FileSize(ctx context.Context, name string) (int64, error) {
+	return int64(len(t.files[name])), nil
+}
+
+func (t testCloner) File(ctx context.Context, name string) ([]byte, error) {
 	return t.files[name], nil
 }
 
+func (t testCloner) Close() error {
+	if t.closed != nil {
+		atomic.AddInt32(t.closed, 1)
+	}
+
+	return nil
+}
+
+type testHistoryCloner struct {
+	testCloner
+	revisions    []wordcount.Revision
+	revisionsErr error
+	checkouts    map[string]testCloner
+}
+
+func (t testHistoryCloner) CloneHistory(ctx context.Context, url string) (wordcount.History, error) {
+	if t.err != nil {
+		return nil, t.err
+	}
+
+	return t, nil
+}
+
+func (t testHistoryCloner) Revisions(ctx context.Context, selection entity.RevisionSelection) ([]wordcount.Revision, error) {
+	return t.revisions, t.revisionsErr
+}
+
+func (t testHistoryCloner) Checkout(ctx context.Context, ref string) (wordcount.Checkout, error) {
+	return t.checkouts[ref], nil
+}
+
 type testMiner struct {
 	results map[string]int
 }
@@ -147,6 +639,10 @@ func (t testMiner) Visit(node ast.Node) ast.Visitor {
 	return t
 }
 
+func (t testMiner) Merge(other wordcount.Miner) error {
+	return nil
+}
+
 func (t testMiner) Results() map[string]int {
 	return t.results
 }
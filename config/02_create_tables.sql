@@ -1,12 +1,22 @@
 -- DROP TABLE frequency_table;
 CREATE TABLE frequency_table (
 	id serial NOT NULL,
-	"name" varchar(200) UNIQUE NOT NULL,
+	"name" text UNIQUE NOT NULL,
 	date_created timestamp NOT NULL,
 	last_updated timestamp NULL,
+	revision_hash text NULL,
+	revision_date timestamp NULL,
+	options jsonb NULL,
+	report jsonb NULL,
+	parent_id int4 NULL,
+	path text NULL,
+	source text NULL,
 	CONSTRAINT frequency_table_pk PRIMARY KEY (id)
 );
 
+-- DROP INDEX frequency_table_parent_id_idx;
+CREATE INDEX frequency_table_parent_id_idx ON frequency_table (parent_id);
+
 ALTER TABLE frequency_table OWNER TO postgres;
 GRANT ALL ON TABLE frequency_table TO postgres;
 
@@ -18,5 +28,53 @@ CREATE TABLE frequency_table_item (
 	CONSTRAINT frequency_table_item_un UNIQUE (frequency_table_id, word)
 );
 
+-- DROP INDEX frequency_table_item_word_idx;
+CREATE INDEX frequency_table_item_word_idx ON frequency_table_item (word);
+
 ALTER TABLE frequency_table_item OWNER TO postgres;
 GRANT ALL ON TABLE frequency_table_item TO postgres;
+
+-- DROP TABLE extraction_job;
+CREATE TABLE extraction_job (
+	id serial NOT NULL,
+	url text NOT NULL,
+	options jsonb NULL,
+	selection jsonb NULL,
+	status varchar(20) NOT NULL,
+	frequency_table_id int4 NULL,
+	series_id int4 NULL,
+	error text NULL,
+	date_created timestamp NOT NULL,
+	last_updated timestamp NULL,
+	CONSTRAINT extraction_job_pk PRIMARY KEY (id)
+);
+
+ALTER TABLE extraction_job OWNER TO postgres;
+GRANT ALL ON TABLE extraction_job TO postgres;
+
+-- DROP TABLE series;
+CREATE TABLE series (
+	id serial NOT NULL,
+	"name" text NOT NULL,
+	selection jsonb NOT NULL,
+	options jsonb NULL,
+	date_created timestamp NOT NULL,
+	CONSTRAINT series_pk PRIMARY KEY (id)
+);
+
+ALTER TABLE series OWNER TO postgres;
+GRANT ALL ON TABLE series TO postgres;
+
+-- DROP TABLE series_item;
+CREATE TABLE series_item (
+	series_id int4 NOT NULL,
+	"position" int4 NOT NULL,
+	frequency_table_id int4 NOT NULL,
+	ref text NOT NULL,
+	revision_hash text NOT NULL,
+	revision_date timestamp NOT NULL,
+	CONSTRAINT series_item_un UNIQUE (series_id, "position")
+);
+
+ALTER TABLE series_item OWNER TO postgres;
+GRANT ALL ON TABLE series_item TO postgres;
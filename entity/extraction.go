@@ -0,0 +1,94 @@
+package entity
+
+import "time"
+
+// Extraction represents the outcome of extracting the word count from a source code repository,
+// including the values, the options applied, the extracted revision, if known, and the report
+// describing how they were obtained. Unless the granularity is the whole repository, the values
+// are also split into parts, one for each Go module or package, sorted by path.
+type Extraction struct {
+	Values   map[string]int
+	Options  ExtractionOptions
+	Revision *Revision
+	Report   ExtractionReport
+	Parts    []ExtractionPart
+}
+
+// ExtractionPart represents the word count of a Go module or package of a source code repository,
+// identified by the slash-separated path of its directory, relative to the repository root.
+type ExtractionPart struct {
+	Path   string
+	Values map[string]int
+}
+
+// Revision represents the commit of a source code repository used on an extraction, or the
+// version of a Go module.
+type Revision struct {
+	Hash string
+	Date time.Time
+}
+
+// TestFilesMode defines how the test files are handled during an extraction.
+type TestFilesMode string
+
+const (
+	// TestFilesInclude indicates that the test files are mined along with the rest of the files.
+	TestFilesInclude TestFilesMode = "include"
+	// TestFilesExclude indicates that the test files are ignored.
+	TestFilesExclude TestFilesMode = "exclude"
+	// TestFilesOnly indicates that only the test files are mined.
+	TestFilesOnly TestFilesMode = "only"
+)
+
+// Granularity defines how the word count of a source code repository is split during an extraction.
+type Granularity string
+
+const (
+	// GranularityRepository indicates that the whole repository is counted as a single unit.
+	GranularityRepository Granularity = "repository"
+	// GranularityModule indicates that each Go module, bounded by its go.mod file, is also counted
+	// on its own.
+	GranularityModule Granularity = "module"
+	// GranularityPackage indicates that each package directory is also counted on its own.
+	GranularityPackage Granularity = "package"
+)
+
+// ExtractionOptions defines the branch, tag or commit hash to extract, and the rules to select the
+// Go files mined during an extraction. Include and Exclude hold glob patterns matched against the
+// file paths. An empty Ref stands for the default branch, and other unset fields fall back to the
+// defaults of the word count repository. The Granularity defines whether the extraction is also
+// split by module or package.
+type ExtractionOptions struct {
+	Ref           string
+	Include       []string
+	Exclude       []string
+	SkipGenerated *bool
+	TestFiles     TestFilesMode
+	Granularity   Granularity
+}
+
+// ExtractionReport describes the files processed during an extraction, the files excluded by
+// the extraction options, the files skipped and the time spent on each stage. Stage durations
+// are accumulated across every worker.
+type ExtractionReport struct {
+	Files         int
+	GoFiles       int
+	ExcludedFiles int
+	ParsedFiles   int
+	SkippedFiles  []SkippedFile
+	Bytes         int64
+	CloneDuration time.Duration
+	ReadDuration  time.Duration
+	ParseDuration time.Duration
+	MineDuration  time.Duration
+	TotalDuration time.Duration
+}
+
+// SkippedFile represents a file that couldn't be mined, the reason and the position
+// of the issue, if known.
+type SkippedFile struct {
+	Name   string
+	Reason string
+	Line   int
+	Column int
+}
@@ -3,11 +3,56 @@ package entity
 import "time"
 
 // FrequencyTable represents a frequency table, indluding its unique identifier,
-// the related values and the error if any.
+// the related values and the error if any. The source, which is the URL, path or Go module it was
+// extracted from, the options, the revision and the report are only available for the frequency
+// tables extracted from a source code repository. The frequency tables
+// extracted from a Go module or package of a repository reference the table of the whole
+// repository as their parent, and hold the path of the module or package.
 type FrequencyTable struct {
 	ID          int64
 	Name        string
+	Source      string
 	DateCreated time.Time
 	LastUpdated time.Time
 	Values      map[string]int
+	Options     *ExtractionOptions
+	Revision    *Revision
+	Report      *ExtractionReport
+	ParentID    int64
+	Path        string
+}
+
+// FrequencyTableSummary represents the metadata of a frequency table, including the number
+// of different words (vocabulary) it contains, but not its values.
+type FrequencyTableSummary struct {
+	ID          int64
+	Name        string
+	DateCreated time.Time
+	LastUpdated time.Time
+	Vocabulary  int
+}
+
+// WordCount represents a word and the number of times it appears on a frequency table.
+type WordCount struct {
+	Word  string
+	Count int
+}
+
+// RankedWord represents a word on a frequency table, including its position (rank) from the most
+// frequent word and its frequency relative to the total number of occurrences.
+type RankedWord struct {
+	Rank      int
+	Word      string
+	Count     int
+	Frequency float64
+}
+
+// WordUsage represents the occurrences of a word on a frequency table, including the total number
+// of occurrences of every word on that table and the frequency of the word relative to it.
+type WordUsage struct {
+	FrequencyTableID   int64
+	FrequencyTableName string
+	Count              int
+	Total              int
+	Frequency          float64
 }
@@ -0,0 +1,33 @@
+package entity
+
+import "time"
+
+// JobStatus represents the state of an extraction job.
+type JobStatus string
+
+const (
+	// JobQueued indicates that the job is waiting to be processed.
+	JobQueued JobStatus = "queued"
+	// JobRunning indicates that the job is being processed.
+	JobRunning JobStatus = "running"
+	// JobSucceeded indicates that the job was processed and the frequency table was created.
+	JobSucceeded JobStatus = "succeeded"
+	// JobFailed indicates that the job was processed but the frequency table couldn't be created.
+	JobFailed JobStatus = "failed"
+)
+
+// Job represents an asynchronous extraction of a frequency table, including the requested
+// extraction options, its status, the identifier of the resulting frequency table and the error if any.
+// A job with a revision selection extracts a series instead, identified by SeriesID once it succeeds.
+type Job struct {
+	ID               int64
+	URL              string
+	Options          ExtractionOptions
+	Selection        *RevisionSelection
+	Status           JobStatus
+	FrequencyTableID int64
+	SeriesID         int64
+	Error            string
+	DateCreated      time.Time
+	LastUpdated      time.Time
+}
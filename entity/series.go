@@ -0,0 +1,53 @@
+package entity
+
+import "time"
+
+// RevisionSelection defines the revisions of a repository extracted on a series: either every
+// tag or every N commits, following the first parent of each commit. From and To are branches,
+// tags or commit hashes bounding the revisions. An empty From stands for the first commit, and
+// an empty To for the default branch.
+type RevisionSelection struct {
+	Tags  bool
+	Every int
+	From  string
+	To    string
+}
+
+// Series represents the frequency tables extracted from several revisions of a repository,
+// linked together and sorted from the oldest revision.
+type Series struct {
+	ID          int64
+	Name        string
+	Selection   RevisionSelection
+	Options     ExtractionOptions
+	DateCreated time.Time
+	Entries     []SeriesEntry
+}
+
+// SeriesEntry represents a revision of a series, identified by its tag or commit hash, and the
+// frequency table extracted from it.
+type SeriesEntry struct {
+	FrequencyTableID int64
+	Ref              string
+	Revision         Revision
+}
+
+// VocabularyChange represents the differences between the frequency tables of two consecutive
+// entries of a series: the size of each vocabulary, the words added and removed, and the words
+// whose count changed the most.
+type VocabularyChange struct {
+	From           SeriesEntry
+	To             SeriesEntry
+	FromVocabulary int
+	ToVocabulary   int
+	Added          []WordCount
+	Removed        []WordCount
+	Changed        []WordChange
+}
+
+// WordChange represents a word appearing on two frequency tables and its count on each one.
+type WordChange struct {
+	Word   string
+	Before int
+	After  int
+}
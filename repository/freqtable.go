@@ -2,14 +2,61 @@ package repository
 
 import (
 	"context"
+	"errors"
+	"time"
 
 	"github.com/eroatta/freqtable/entity"
 )
 
+var (
+	// ErrNoResults indicates that the given query has no results.
+	ErrNoResults = errors.New("No results for the given query")
+	// ErrDuplicated indicates that an element with the same unique values already exists.
+	ErrDuplicated = errors.New("An element with the same unique values already exists")
+	// ErrNotExtracted indicates that the frequency table wasn't extracted from a source code
+	// repository, as the merged ones, so it can't be extracted again.
+	ErrNotExtracted = errors.New("The frequency table wasn't extracted from a source code repository")
+)
+
 // FrequencyTableRepository represents a repository capable of storing a given model.FrequencyTable.
 type FrequencyTableRepository interface {
 	// Get retrieves a model.FrequencyTable through the ID.
 	Get(ctx context.Context, ID int64) (entity.FrequencyTable, error)
 	// Save saves a model.FrequencyTable on the underlaying datasource.
 	Save(ctx context.Context, ft entity.FrequencyTable) (int64, error)
+	// SaveWithChildren saves a model.FrequencyTable along with the children of its modules or
+	// packages, setting their parent ID, so either every table is saved or none. It returns the ID
+	// of the parent.
+	SaveWithChildren(ctx context.Context, ft entity.FrequencyTable, children []entity.FrequencyTable) (int64, error)
+	// Update replaces the values of an existing model.FrequencyTable and sets its last updated date.
+	Update(ctx context.Context, ft entity.FrequencyTable) error
+	// FindByName retrieves the ID of the model.FrequencyTable with exactly the given name.
+	FindByName(ctx context.Context, name string) (int64, error)
+	// List retrieves a page of model.FrequencyTableSummary matching the given filter, sorted by ID.
+	// It also returns the cursor for the next page, or zero if there are no more elements.
+	List(ctx context.Context, filter FrequencyTableFilter) ([]entity.FrequencyTableSummary, int64, error)
+	// Top retrieves up to n of the most frequent words with at least min occurrences, sorted by count and then by
+	// word. It also returns the total number of occurrences of every word on the model.FrequencyTable.
+	Top(ctx context.Context, ID int64, n int, min int) ([]entity.WordCount, int, error)
+	// FindWord retrieves the occurrences of the given word on every model.FrequencyTable containing it, sorted
+	// from the highest count. Each model.WordUsage includes the total number of occurrences on its table.
+	FindWord(ctx context.Context, word string) ([]entity.WordUsage, error)
+}
+
+// FrequencyTableFilter defines the criteria to list frequency tables. Zero values are ignored.
+type FrequencyTableFilter struct {
+	// Name filters the frequency tables whose name contains the given value.
+	Name string
+	// CreatedAfter filters the frequency tables created after the given time.
+	CreatedAfter time.Time
+	// CreatedBefore filters the frequency tables created before the given time.
+	CreatedBefore time.Time
+	// MinVocabulary filters the frequency tables with at least the given number of words.
+	MinVocabulary int
+	// ParentID filters the frequency tables extracted from the modules or packages of the given one.
+	ParentID int64
+	// Cursor is the ID of the last element from the previous page.
+	Cursor int64
+	// Limit is the maximum number of elements on a page.
+	Limit int
 }
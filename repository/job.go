@@ -0,0 +1,19 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/eroatta/freqtable/entity"
+)
+
+// JobRepository represents a repository capable of storing a given model.Job.
+type JobRepository interface {
+	// Get retrieves a model.Job through the ID.
+	Get(ctx context.Context, ID int64) (entity.Job, error)
+	// Save saves a new model.Job on the underlaying datasource.
+	Save(ctx context.Context, job entity.Job) (int64, error)
+	// Update updates the status, results and last updated date of an existing model.Job.
+	Update(ctx context.Context, job entity.Job) error
+	// FindByStatus retrieves every model.Job on any of the given statuses, sorted by ID.
+	FindByStatus(ctx context.Context, statuses ...entity.JobStatus) ([]entity.Job, error)
+}
@@ -0,0 +1,15 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/eroatta/freqtable/entity"
+)
+
+// SeriesRepository represents a repository capable of storing a given model.Series.
+type SeriesRepository interface {
+	// Get retrieves a model.Series through the ID, including its entries.
+	Get(ctx context.Context, ID int64) (entity.Series, error)
+	// Save saves a new model.Series, along with its entries, on the underlaying datasource.
+	Save(ctx context.Context, series entity.Series) (int64, error)
+}
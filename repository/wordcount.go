@@ -1,8 +1,34 @@
 package repository
 
+import (
+	"context"
+	"errors"
+
+	"github.com/eroatta/freqtable/entity"
+)
+
+var (
+	// ErrUnknownRevision indicates that the requested branch, tag or commit doesn't exist on the repository.
+	ErrUnknownRevision = errors.New("The requested branch, tag or commit doesn't exist on the repository")
+	// ErrRepositoryTooLarge indicates that the repository exceeds the size, Go files or file size limits.
+	ErrRepositoryTooLarge = errors.New("The repository exceeds the size limits")
+	// ErrHistoryUnsupported indicates that the history of the source code can't be accessed, as on
+	// archives and Go modules.
+	ErrHistoryUnsupported = errors.New("The history of the source code isn't available")
+	// ErrLocalRepository indicates that the source code is on the local disk, which can't be accessed.
+	ErrLocalRepository = errors.New("The source code on the local disk can't be accessed")
+)
+
 // WordCountRepository represents a repository capable of extracting the dictionary
 // words count from a source code repository.
 type WordCountRepository interface {
-	// Extract extracts a map of words and counts from a source code repository.
-	Extract(url string) (map[string]int, error)
+	// Extract extracts a map of words and counts from the files of a source code repository
+	// selected by the given options, along with the options applied, the extracted revision and
+	// a report of the processed files. The extraction is stopped if the context is done.
+	Extract(ctx context.Context, url string, options entity.ExtractionOptions) (entity.Extraction, error)
+	// ExtractHistory extracts the word count from each revision of a source code repository chosen
+	// by the selection, from the oldest revision, applying the same options on each one. Each
+	// extraction is handed to fn as soon as it's done, and an error returned by fn stops the
+	// extraction and is returned.
+	ExtractHistory(ctx context.Context, url string, selection entity.RevisionSelection, options entity.ExtractionOptions, fn func(entity.Extraction) error) error
 }
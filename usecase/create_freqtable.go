@@ -2,6 +2,8 @@ package usecase
 
 import (
 	"context"
+	"fmt"
+	"sync"
 	"time"
 
 	"github.com/eroatta/freqtable/entity"
@@ -11,10 +13,23 @@ import (
 // CreateFrequencyTableUsecase defines the contract for the use cases related to the
 // creation of one or several frenquency tables.
 type CreateFrequencyTableUsecase interface {
-	// Create creates a single frequency table.
-	Create(ctx context.Context, url string) (entity.FrequencyTable, error)
+	// Create creates a single frequency table, mining the files selected by the given options.
+	Create(ctx context.Context, url string, options entity.ExtractionOptions) (entity.FrequencyTable, error)
+	// CreateMultiple creates a frequency table for each one of the given URLs, applying the same options.
+	CreateMultiple(ctx context.Context, urls []string, options entity.ExtractionOptions) []CreationResult
 }
 
+// CreationResult holds the outcome of the frequency table creation for a given URL.
+type CreationResult struct {
+	URL            string
+	FrequencyTable entity.FrequencyTable
+	Error          error
+}
+
+// maxConcurrentExtractions defines how many extractions can run at the same time
+// while creating multiple frequency tables.
+const maxConcurrentExtractions = 4
+
 // NewCreateFrequencyTableUsecase initializes a new CreateFrequencyTableUsecase handler
 // with the given repositories.
 func NewCreateFrequencyTableUsecase(wcr repository.WordCountRepository, ftr repository.FrequencyTableRepository) createFrequencyTableUsecase {
@@ -29,20 +44,28 @@ type createFrequencyTableUsecase struct {
 	ftr repository.FrequencyTableRepository
 }
 
-// Create creates a new entity.FrequencyTable from the given URL.
-func (uc createFrequencyTableUsecase) Create(ctx context.Context, url string) (entity.FrequencyTable, error) {
+// Create creates a new entity.FrequencyTable from the given URL, storing the options applied
+// during the extraction and the extracted revision. The table is named after the URL, along with
+// the ref if any, so each ref of a repository gets its own table. If the extraction was split by
+// module or package, a child entity.FrequencyTable is also created for each one of them, along
+// with the parent, so either every table is created or none.
+func (uc createFrequencyTableUsecase) Create(ctx context.Context, url string, options entity.ExtractionOptions) (entity.FrequencyTable, error) {
 	ft := entity.FrequencyTable{
-		Name:        url,
+		Name:        tableName(url, options.Ref),
+		Source:      url,
 		DateCreated: time.Now(),
 	}
 
-	values, err := uc.wcr.Extract(url)
+	extraction, err := uc.wcr.Extract(ctx, url, options)
 	if err != nil {
 		return entity.FrequencyTable{}, err
 	}
-	ft.Values = values
+	ft.Values = extraction.Values
+	ft.Options = &extraction.Options
+	ft.Revision = extraction.Revision
+	ft.Report = &extraction.Report
 
-	id, err := uc.ftr.Save(ctx, ft)
+	id, err := uc.ftr.SaveWithChildren(ctx, ft, newChildren(ft, extraction.Parts))
 	if err != nil {
 		return entity.FrequencyTable{}, err
 	}
@@ -50,3 +73,49 @@ func (uc createFrequencyTableUsecase) Create(ctx context.Context, url string) (e
 
 	return ft, nil
 }
+
+// tableName provides the name of the frequency table extracted from the given URL and ref, such as
+// https://github.com/eroatta/freqtable@v1.0.0. Without a ref, the table is named after the URL.
+func tableName(url string, ref string) string {
+	if ref == "" {
+		return url
+	}
+
+	return fmt.Sprintf("%s@%s", url, ref)
+}
+
+// CreateMultiple creates a new entity.FrequencyTable for each given URL, running a bounded
+// number of extractions concurrently. A failure on a given URL doesn't stop the remaining ones,
+// and the results are returned in the same order as the URLs.
+func (uc createFrequencyTableUsecase) CreateMultiple(ctx context.Context, urls []string, options entity.ExtractionOptions) []CreationResult {
+	results := make([]CreationResult, len(urls))
+	semaphore := make(chan struct{}, maxConcurrentExtractions)
+
+	var wg sync.WaitGroup
+	for i, url := range urls {
+		wg.Add(1)
+		go func(i int, url string) {
+			defer wg.Done()
+			select {
+			case semaphore <- struct{}{}:
+				defer func() { <-semaphore }()
+			case <-ctx.Done():
+			}
+
+			if err := ctx.Err(); err != nil {
+				results[i] = CreationResult{URL: url, Error: err}
+				return
+			}
+
+			ft, err := uc.Create(ctx, url, options)
+			results[i] = CreationResult{
+				URL:            url,
+				FrequencyTable: ft,
+				Error:          err,
+			}
+		}(i, url)
+	}
+	wg.Wait()
+
+	return results
+}
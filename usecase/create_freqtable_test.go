@@ -6,6 +6,7 @@ import (
 	"testing"
 
 	"github.com/eroatta/freqtable/entity"
+	"github.com/eroatta/freqtable/repository"
 	"github.com/eroatta/freqtable/usecase"
 	"github.com/stretchr/testify/assert"
 )
@@ -24,7 +25,8 @@ func TestCreate_OnCreateFrequencyTableUsecase_ShouldCreateFrequencyTable(t *test
 				"table":     3,
 			},
 		},
-		err: nil,
+		revision: &entity.Revision{Hash: "0a1b2c3d4e5f60718293a4b5c6d7e8f901234567"},
+		err:      nil,
 	}
 
 	ftr := testFrequencyTableRepository{
@@ -33,16 +35,21 @@ func TestCreate_OnCreateFrequencyTableUsecase_ShouldCreateFrequencyTable(t *test
 	}
 
 	uc := usecase.NewCreateFrequencyTableUsecase(wcr, ftr)
-	ft, err := uc.Create(context.TODO(), "https://github.com/eroatta/freqtable")
+	options := entity.ExtractionOptions{Ref: "v1.0.0", Exclude: []string{"vendor"}, TestFiles: entity.TestFilesExclude}
+	ft, err := uc.Create(context.TODO(), "https://github.com/eroatta/freqtable", options)
 
 	assert.NotNil(t, ft)
 	assert.NoError(t, err)
+	assert.Equal(t, &options, ft.Options)
+	assert.Equal(t, &entity.Revision{Hash: "0a1b2c3d4e5f60718293a4b5c6d7e8f901234567"}, ft.Revision)
 	assert.Equal(t, int64(1234567890), ft.ID)
-	assert.Equal(t, "https://github.com/eroatta/freqtable", ft.Name)
+	assert.Equal(t, "https://github.com/eroatta/freqtable@v1.0.0", ft.Name)
+	assert.Equal(t, "https://github.com/eroatta/freqtable", ft.Source)
 	// TODO: add validations for date
 	assert.Equal(t, 2, len(ft.Values))
 	assert.Equal(t, 2, ft.Values["frequency"])
 	assert.Equal(t, 3, ft.Values["table"])
+	assert.Equal(t, &entity.ExtractionReport{GoFiles: 1, ParsedFiles: 1}, ft.Report)
 }
 
 func TestCreate_OnCreateFrequencyTableUsecase_WhenErrorCounting_ShouldReturnError(t *testing.T) {
@@ -52,7 +59,7 @@ func TestCreate_OnCreateFrequencyTableUsecase_WhenErrorCounting_ShouldReturnErro
 	}
 
 	uc := usecase.NewCreateFrequencyTableUsecase(wcr, nil)
-	ft, err := uc.Create(context.TODO(), "https://github.com/eroatta/freqtable")
+	ft, err := uc.Create(context.TODO(), "https://github.com/eroatta/freqtable", entity.ExtractionOptions{})
 
 	assert.NotNil(t, ft)
 	assert.EqualError(t, err, "error while extracting")
@@ -75,36 +82,293 @@ func TestCreate_OnCreateFrequencyTableUsecase_WhenSavingResults_ShouldReturnErro
 	}
 
 	uc := usecase.NewCreateFrequencyTableUsecase(wcr, ftr)
-	ft, err := uc.Create(context.TODO(), "https://github.com/eroatta/freqtable")
+	ft, err := uc.Create(context.TODO(), "https://github.com/eroatta/freqtable", entity.ExtractionOptions{})
 
 	assert.NotNil(t, ft)
 	assert.EqualError(t, err, "error while persisting")
 	assert.Equal(t, entity.FrequencyTable{}, ft)
 }
 
+func TestCreate_OnCreateFrequencyTableUsecase_WhenExtractionSplitByPackage_ShouldCreateChildFrequencyTables(t *testing.T) {
+	wcr := testWordCountRepository{
+		extractions: map[string]map[string]int{
+			"https://github.com/eroatta/freqtable": map[string]int{"frequency": 2, "table": 3},
+		},
+		parts: []entity.ExtractionPart{
+			{Path: "adapter/rest", Values: map[string]int{"table": 1}},
+			{Path: "entity", Values: map[string]int{"frequency": 2, "table": 2}},
+		},
+	}
+	tables := make(map[int64]entity.FrequencyTable)
+	ftr := testHierarchyRepository{tables: tables}
+
+	uc := usecase.NewCreateFrequencyTableUsecase(wcr, ftr)
+	options := entity.ExtractionOptions{Granularity: entity.GranularityPackage}
+	ft, err := uc.Create(context.TODO(), "https://github.com/eroatta/freqtable", options)
+
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), ft.ID)
+	assert.Equal(t, 3, len(tables))
+	assert.Equal(t, "https://github.com/eroatta/freqtable#adapter/rest", tables[2].Name)
+	assert.Equal(t, int64(1), tables[2].ParentID)
+	assert.Equal(t, "adapter/rest", tables[2].Path)
+	assert.Equal(t, map[string]int{"table": 1}, tables[2].Values)
+	assert.Equal(t, &options, tables[2].Options)
+	assert.Equal(t, "https://github.com/eroatta/freqtable#entity", tables[3].Name)
+	assert.Equal(t, int64(1), tables[3].ParentID)
+}
+
+func TestCreate_OnCreateFrequencyTableUsecase_WhenRootPackage_ShouldNameItAsRoot(t *testing.T) {
+	wcr := testWordCountRepository{
+		extractions: map[string]map[string]int{
+			"https://github.com/eroatta/freqtable": map[string]int{"frequency": 2},
+		},
+		parts: []entity.ExtractionPart{
+			{Path: ".", Values: map[string]int{"frequency": 2}},
+		},
+	}
+	tables := make(map[int64]entity.FrequencyTable)
+
+	uc := usecase.NewCreateFrequencyTableUsecase(wcr, testHierarchyRepository{tables: tables})
+	_, err := uc.Create(context.TODO(), "https://github.com/eroatta/freqtable", entity.ExtractionOptions{Granularity: entity.GranularityPackage})
+
+	assert.NoError(t, err)
+	assert.Equal(t, "https://github.com/eroatta/freqtable#(root)", tables[2].Name)
+	assert.Equal(t, ".", tables[2].Path)
+}
+
+func TestCreateMultiple_OnCreateFrequencyTableUsecase_ShouldReturnResultsForEachURL(t *testing.T) {
+	wcr := testWordCountRepository{
+		extractions: map[string]map[string]int{
+			"https://github.com/eroatta/freqtable": map[string]int{
+				"frequency": 2,
+			},
+			"https://github.com/eroatta/token": map[string]int{
+				"token": 5,
+			},
+		},
+		err: errors.New("error while extracting"),
+	}
+
+	ftr := testFrequencyTableRepository{
+		id: 1234567890,
+	}
+
+	uc := usecase.NewCreateFrequencyTableUsecase(wcr, ftr)
+	results := uc.CreateMultiple(context.TODO(), []string{
+		"https://github.com/eroatta/freqtable",
+		"https://github.com/eroatta/unknown",
+		"https://github.com/eroatta/token",
+	}, entity.ExtractionOptions{})
+
+	assert.Equal(t, 3, len(results))
+
+	assert.Equal(t, "https://github.com/eroatta/freqtable", results[0].URL)
+	assert.NoError(t, results[0].Error)
+	assert.Equal(t, 2, results[0].FrequencyTable.Values["frequency"])
+
+	assert.Equal(t, "https://github.com/eroatta/unknown", results[1].URL)
+	assert.EqualError(t, results[1].Error, "error while extracting")
+	assert.Equal(t, entity.FrequencyTable{}, results[1].FrequencyTable)
+
+	assert.Equal(t, "https://github.com/eroatta/token", results[2].URL)
+	assert.NoError(t, results[2].Error)
+	assert.Equal(t, 5, results[2].FrequencyTable.Values["token"])
+}
+
+func TestCreateMultiple_OnCreateFrequencyTableUsecase_WhenContextCancelled_ShouldReturnErrors(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	uc := usecase.NewCreateFrequencyTableUsecase(testWordCountRepository{}, testFrequencyTableRepository{})
+	results := uc.CreateMultiple(ctx, []string{
+		"https://github.com/eroatta/freqtable",
+		"https://github.com/eroatta/token",
+		"https://github.com/eroatta/src-reader",
+		"https://github.com/eroatta/src-splitter",
+		"https://github.com/eroatta/freqtable-ui",
+	}, entity.ExtractionOptions{})
+
+	assert.Equal(t, 5, len(results))
+	for _, result := range results {
+		assert.Equal(t, context.Canceled, result.Error)
+	}
+}
+
 type testWordCountRepository struct {
 	extractions map[string]map[string]int
+	history     []entity.Extraction
+	revision    *entity.Revision
+	parts       []entity.ExtractionPart
 	err         error
 }
 
-func (twc testWordCountRepository) Extract(url string) (map[string]int, error) {
+func (twc testWordCountRepository) Extract(ctx context.Context, url string, options entity.ExtractionOptions) (entity.Extraction, error) {
 	if val, ok := twc.extractions[url]; ok {
-		return val, nil
+		return entity.Extraction{
+			Values:   val,
+			Options:  options,
+			Revision: twc.revision,
+			Report:   entity.ExtractionReport{GoFiles: 1, ParsedFiles: 1},
+			Parts:    twc.parts,
+		}, nil
 	}
 
-	return nil, twc.err
+	return entity.Extraction{}, twc.err
+}
+
+func (twc testWordCountRepository) ExtractHistory(ctx context.Context, url string, selection entity.RevisionSelection, options entity.ExtractionOptions, fn func(entity.Extraction) error) error {
+	for _, extraction := range twc.history {
+		if err := fn(extraction); err != nil {
+			return err
+		}
+	}
+
+	return twc.err
 }
 
 type testFrequencyTableRepository struct {
-	frequencyTable entity.FrequencyTable
-	id             int64
-	err            error
+	frequencyTable  entity.FrequencyTable
+	frequencyTables map[int64]entity.FrequencyTable
+	summaries       []entity.FrequencyTableSummary
+	names           map[string]int64
+	filter          *repository.FrequencyTableFilter
+	updated         *entity.FrequencyTable
+	words           []entity.WordCount
+	total           int
+	topLimit        *int
+	usages          []entity.WordUsage
+	id              int64
+	saveErr         error
+	err             error
 }
 
 func (tft testFrequencyTableRepository) Get(ctx context.Context, id int64) (entity.FrequencyTable, error) {
+	if tft.frequencyTables != nil {
+		if ft, ok := tft.frequencyTables[id]; ok {
+			return ft, nil
+		}
+		return entity.FrequencyTable{}, repository.ErrNoResults
+	}
+
 	return tft.frequencyTable, tft.err
 }
 
 func (tft testFrequencyTableRepository) Save(ctx context.Context, ft entity.FrequencyTable) (int64, error) {
+	if tft.saveErr != nil {
+		return 0, tft.saveErr
+	}
+
 	return tft.id, tft.err
 }
+
+func (tft testFrequencyTableRepository) SaveWithChildren(ctx context.Context, ft entity.FrequencyTable, children []entity.FrequencyTable) (int64, error) {
+	return tft.Save(ctx, ft)
+}
+
+func (tft testFrequencyTableRepository) Update(ctx context.Context, ft entity.FrequencyTable) error {
+	if tft.updated != nil {
+		*tft.updated = ft
+	}
+
+	return tft.err
+}
+
+func (tft testFrequencyTableRepository) FindByName(ctx context.Context, name string) (int64, error) {
+	if id, ok := tft.names[name]; ok {
+		return id, nil
+	}
+
+	return 0, repository.ErrNoResults
+}
+
+func (tft testFrequencyTableRepository) List(ctx context.Context, filter repository.FrequencyTableFilter) ([]entity.FrequencyTableSummary, int64, error) {
+	if tft.filter != nil {
+		*tft.filter = filter
+	}
+
+	if tft.err != nil {
+		return nil, 0, tft.err
+	}
+
+	return tft.summaries, tft.id, nil
+}
+
+func (tft testFrequencyTableRepository) Top(ctx context.Context, id int64, n int, min int) ([]entity.WordCount, int, error) {
+	if tft.topLimit != nil {
+		*tft.topLimit = n
+	}
+
+	if tft.err != nil {
+		return nil, 0, tft.err
+	}
+
+	return tft.words, tft.total, nil
+}
+
+func (tft testFrequencyTableRepository) FindWord(ctx context.Context, word string) ([]entity.WordUsage, error) {
+	return tft.usages, tft.err
+}
+
+// testHierarchyRepository stores the frequency tables on the given map, so the child tables of a
+// parent can be listed.
+type testHierarchyRepository struct {
+	testFrequencyTableRepository
+	tables map[int64]entity.FrequencyTable
+}
+
+func (th testHierarchyRepository) Get(ctx context.Context, id int64) (entity.FrequencyTable, error) {
+	ft, ok := th.tables[id]
+	if !ok {
+		return entity.FrequencyTable{}, repository.ErrNoResults
+	}
+
+	return ft, nil
+}
+
+func (th testHierarchyRepository) Save(ctx context.Context, ft entity.FrequencyTable) (int64, error) {
+	ft.ID = int64(len(th.tables) + 1)
+	th.tables[ft.ID] = ft
+
+	return ft.ID, nil
+}
+
+func (th testHierarchyRepository) SaveWithChildren(ctx context.Context, ft entity.FrequencyTable, children []entity.FrequencyTable) (int64, error) {
+	id, _ := th.Save(ctx, ft)
+	for _, child := range children {
+		child.ParentID = id
+		th.Save(ctx, child)
+	}
+
+	return id, nil
+}
+
+func (th testHierarchyRepository) Update(ctx context.Context, ft entity.FrequencyTable) error {
+	if _, ok := th.tables[ft.ID]; !ok {
+		return repository.ErrNoResults
+	}
+	th.tables[ft.ID] = ft
+
+	return nil
+}
+
+func (th testHierarchyRepository) FindByName(ctx context.Context, name string) (int64, error) {
+	for id, ft := range th.tables {
+		if ft.Name == name {
+			return id, nil
+		}
+	}
+
+	return 0, repository.ErrNoResults
+}
+
+func (th testHierarchyRepository) List(ctx context.Context, filter repository.FrequencyTableFilter) ([]entity.FrequencyTableSummary, int64, error) {
+	summaries := make([]entity.FrequencyTableSummary, 0)
+	for _, ft := range th.tables {
+		if ft.ParentID == filter.ParentID {
+			summaries = append(summaries, entity.FrequencyTableSummary{ID: ft.ID, Name: ft.Name})
+		}
+	}
+
+	return summaries, 0, nil
+}
@@ -0,0 +1,214 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/eroatta/freqtable/entity"
+	"github.com/eroatta/freqtable/repository"
+	log "github.com/sirupsen/logrus"
+)
+
+var (
+	// ErrJobQueueFull indicates that the job couldn't be queued because there are too many pending jobs.
+	ErrJobQueueFull = errors.New("Too many pending jobs, try again later")
+)
+
+// jobQueueSize defines the maximum number of pending jobs waiting for a worker.
+const jobQueueSize = 1000
+
+// ExtractionJobUsecase defines the contract for the use cases related to the asynchronous
+// extraction of frequency tables.
+type ExtractionJobUsecase interface {
+	// Submit queues a new job to create a frequency table in the background, using the given options.
+	Submit(ctx context.Context, url string, options entity.ExtractionOptions) (entity.Job, error)
+	// SubmitSeries queues a new job to create a series in the background, from the revisions chosen
+	// by the selection and using the given options.
+	SubmitSeries(ctx context.Context, url string, selection entity.RevisionSelection, options entity.ExtractionOptions) (entity.Job, error)
+	// Get retrieves a job, including its status and results.
+	Get(ctx context.Context, id int64) (entity.Job, error)
+}
+
+// NewExtractionJobUsecase initializes a new ExtractionJobUsecase handler with the given use cases
+// and repositories. Queued jobs aren't processed until the workers are started.
+func NewExtractionJobUsecase(createUC CreateFrequencyTableUsecase, updateUC UpdateFrequencyTableUsecase,
+	seriesUC SeriesUsecase, ftr repository.FrequencyTableRepository, jr repository.JobRepository, workers int) extractionJobUsecase {
+	if workers <= 0 {
+		workers = 1
+	}
+
+	return extractionJobUsecase{
+		createUC: createUC,
+		updateUC: updateUC,
+		seriesUC: seriesUC,
+		ftr:      ftr,
+		jr:       jr,
+		workers:  workers,
+		queue:    make(chan int64, jobQueueSize),
+	}
+}
+
+type extractionJobUsecase struct {
+	createUC CreateFrequencyTableUsecase
+	updateUC UpdateFrequencyTableUsecase
+	seriesUC SeriesUsecase
+	ftr      repository.FrequencyTableRepository
+	jr       repository.JobRepository
+	workers  int
+	queue    chan int64
+}
+
+// Start re-queues the jobs interrupted by a previous shutdown and launches the workers, which
+// process jobs until the given context is done.
+func (uc extractionJobUsecase) Start(ctx context.Context) error {
+	pending, err := uc.jr.FindByStatus(ctx, entity.JobQueued, entity.JobRunning)
+	if err != nil {
+		return err
+	}
+
+	for i := 0; i < uc.workers; i++ {
+		go uc.work(ctx)
+	}
+
+	go func() {
+		for _, job := range pending {
+			log.WithField("job", job.ID).Info("re-queueing pending job")
+			select {
+			case uc.queue <- job.ID:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	return nil
+}
+
+// Submit saves a new queued entity.Job for the given URL and options, and sends it to the workers.
+func (uc extractionJobUsecase) Submit(ctx context.Context, url string, options entity.ExtractionOptions) (entity.Job, error) {
+	return uc.submit(ctx, entity.Job{
+		URL:         url,
+		Options:     options,
+		Status:      entity.JobQueued,
+		DateCreated: time.Now(),
+	})
+}
+
+// SubmitSeries saves a new queued entity.Job for the given URL, selection and options, and sends it
+// to the workers.
+func (uc extractionJobUsecase) SubmitSeries(ctx context.Context, url string, selection entity.RevisionSelection, options entity.ExtractionOptions) (entity.Job, error) {
+	return uc.submit(ctx, entity.Job{
+		URL:         url,
+		Options:     options,
+		Selection:   &selection,
+		Status:      entity.JobQueued,
+		DateCreated: time.Now(),
+	})
+}
+
+// submit saves the given entity.Job and sends it to the workers.
+func (uc extractionJobUsecase) submit(ctx context.Context, job entity.Job) (entity.Job, error) {
+	id, err := uc.jr.Save(ctx, job)
+	if err != nil {
+		return entity.Job{}, err
+	}
+	job.ID = id
+
+	select {
+	case uc.queue <- job.ID:
+		return job, nil
+	default:
+		job.Status = entity.JobFailed
+		job.Error = ErrJobQueueFull.Error()
+		job.LastUpdated = time.Now()
+		if err := uc.jr.Update(ctx, job); err != nil {
+			log.WithError(err).Error(fmt.Sprintf("error updating job %d", job.ID))
+		}
+		return entity.Job{}, ErrJobQueueFull
+	}
+}
+
+// Get retrieves the entity.Job identified by the given ID.
+func (uc extractionJobUsecase) Get(ctx context.Context, id int64) (entity.Job, error) {
+	return uc.jr.Get(ctx, id)
+}
+
+// work processes the queued jobs until the given context is done.
+func (uc extractionJobUsecase) work(ctx context.Context) {
+	for {
+		select {
+		case id := <-uc.queue:
+			uc.process(ctx, id)
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// process creates the frequency table or the series for a given job, and keeps its status up to date.
+func (uc extractionJobUsecase) process(ctx context.Context, id int64) {
+	job, err := uc.jr.Get(ctx, id)
+	if err != nil {
+		log.WithError(err).Error(fmt.Sprintf("error retrieving job %d", id))
+		return
+	}
+
+	// a job left running was interrupted, maybe once its frequency table was created
+	rerun := job.Status == entity.JobRunning
+	job.Status = entity.JobRunning
+	job.LastUpdated = time.Now()
+	if err := uc.jr.Update(ctx, job); err != nil {
+		log.WithError(err).Error(fmt.Sprintf("error updating job %d", id))
+		return
+	}
+
+	if job.Selection != nil {
+		// a series updates the frequency tables already stored, so a rerun creates it again
+		var series entity.Series
+		series, err = uc.seriesUC.Create(ctx, job.URL, *job.Selection, job.Options)
+		job.SeriesID = series.ID
+	} else {
+		var ft entity.FrequencyTable
+		ft, err = uc.run(ctx, job, rerun)
+		job.FrequencyTableID = ft.ID
+	}
+
+	if err != nil {
+		log.WithError(err).Error(fmt.Sprintf("error processing job %d", id))
+		job.Status = entity.JobFailed
+		job.Error = err.Error()
+	} else {
+		job.Status = entity.JobSucceeded
+	}
+
+	// the job is left running on shutdown, so it gets re-queued on the next start
+	if ctx.Err() != nil {
+		return
+	}
+
+	job.LastUpdated = time.Now()
+	if err := uc.jr.Update(ctx, job); err != nil {
+		log.WithError(err).Error(fmt.Sprintf("error updating job %d", id))
+	}
+}
+
+// run creates the frequency table for a given job. On a rerun, the frequency table created by the
+// interrupted run is refreshed instead, since it can't be created twice.
+func (uc extractionJobUsecase) run(ctx context.Context, job entity.Job, rerun bool) (entity.FrequencyTable, error) {
+	if rerun {
+		id, err := uc.ftr.FindByName(ctx, tableName(job.URL, job.Options.Ref))
+		switch err {
+		case nil:
+			log.WithField("job", job.ID).Info(fmt.Sprintf("refreshing frequency table %d", id))
+			return uc.updateUC.Refresh(ctx, id)
+		case repository.ErrNoResults:
+			// the interrupted run didn't create it
+		default:
+			return entity.FrequencyTable{}, err
+		}
+	}
+
+	return uc.createUC.Create(ctx, job.URL, job.Options)
+}
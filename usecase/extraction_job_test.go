@@ -0,0 +1,315 @@
+package usecase_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/eroatta/freqtable/entity"
+	"github.com/eroatta/freqtable/repository"
+	"github.com/eroatta/freqtable/usecase"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewExtractionJobUsecase_ShouldReturnNewInstance(t *testing.T) {
+	uc := usecase.NewExtractionJobUsecase(nil, nil, nil, nil, nil, 2)
+
+	assert.NotNil(t, uc)
+}
+
+func TestSubmit_OnExtractionJobUsecase_WhenSavingJob_ShouldReturnError(t *testing.T) {
+	jr := newTestJobRepository()
+	jr.err = errors.New("error while persisting")
+
+	uc := usecase.NewExtractionJobUsecase(nil, nil, nil, nil, jr, 1)
+	job, err := uc.Submit(context.TODO(), "https://github.com/eroatta/freqtable", entity.ExtractionOptions{})
+
+	assert.EqualError(t, err, "error while persisting")
+	assert.Equal(t, entity.Job{}, job)
+}
+
+func TestSubmit_OnExtractionJobUsecase_ShouldReturnQueuedJob(t *testing.T) {
+	jr := newTestJobRepository()
+
+	uc := usecase.NewExtractionJobUsecase(nil, nil, nil, nil, jr, 1)
+	options := entity.ExtractionOptions{TestFiles: entity.TestFilesOnly}
+	job, err := uc.Submit(context.TODO(), "https://github.com/eroatta/freqtable", options)
+
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), job.ID)
+	assert.Equal(t, "https://github.com/eroatta/freqtable", job.URL)
+	assert.Equal(t, options, job.Options)
+	assert.Equal(t, entity.JobQueued, job.Status)
+
+	stored, err := uc.Get(context.TODO(), job.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, entity.JobQueued, stored.Status)
+	assert.Equal(t, options, stored.Options)
+}
+
+func TestStart_OnExtractionJobUsecase_ShouldProcessSubmittedJobs(t *testing.T) {
+	createUC := testCreateUsecase{
+		tables: map[string]entity.FrequencyTable{
+			"https://github.com/eroatta/freqtable": {ID: 1234},
+		},
+		err: errors.New("error cloning repository"),
+	}
+	jr := newTestJobRepository()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	uc := usecase.NewExtractionJobUsecase(createUC, nil, nil, testFrequencyTableRepository{}, jr, 2)
+	assert.NoError(t, uc.Start(ctx))
+
+	succeeded, _ := uc.Submit(context.TODO(), "https://github.com/eroatta/freqtable", entity.ExtractionOptions{})
+	failed, _ := uc.Submit(context.TODO(), "https://github.com/eroatta/unknown", entity.ExtractionOptions{})
+
+	job := waitForJob(t, uc, succeeded.ID)
+	assert.Equal(t, entity.JobSucceeded, job.Status)
+	assert.Equal(t, int64(1234), job.FrequencyTableID)
+	assert.Empty(t, job.Error)
+	assert.False(t, job.LastUpdated.IsZero())
+
+	job = waitForJob(t, uc, failed.ID)
+	assert.Equal(t, entity.JobFailed, job.Status)
+	assert.Equal(t, int64(0), job.FrequencyTableID)
+	assert.Equal(t, "error cloning repository", job.Error)
+}
+
+func TestStart_OnExtractionJobUsecase_ShouldProcessSubmittedSeries(t *testing.T) {
+	seriesUC := &testSeriesUsecase{series: entity.Series{ID: 42}}
+	jr := newTestJobRepository()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	uc := usecase.NewExtractionJobUsecase(nil, nil, seriesUC, testFrequencyTableRepository{}, jr, 1)
+	assert.NoError(t, uc.Start(ctx))
+
+	selection := entity.RevisionSelection{Every: 10, From: "v0.1.0"}
+	options := entity.ExtractionOptions{TestFiles: entity.TestFilesExclude}
+	submitted, err := uc.SubmitSeries(context.TODO(), "https://github.com/eroatta/freqtable", selection, options)
+	assert.NoError(t, err)
+	assert.Equal(t, &selection, submitted.Selection)
+	assert.Equal(t, entity.JobQueued, submitted.Status)
+
+	job := waitForJob(t, uc, submitted.ID)
+	assert.Equal(t, entity.JobSucceeded, job.Status)
+	assert.Equal(t, int64(42), job.SeriesID)
+	assert.Equal(t, int64(0), job.FrequencyTableID)
+	assert.Empty(t, job.Error)
+
+	seriesUC.Lock()
+	defer seriesUC.Unlock()
+	assert.Equal(t, "https://github.com/eroatta/freqtable", seriesUC.url)
+	assert.Equal(t, selection, seriesUC.selection)
+	assert.Equal(t, options, seriesUC.options)
+}
+
+func TestStart_OnExtractionJobUsecase_WhenSeriesFails_ShouldStoreError(t *testing.T) {
+	seriesUC := &testSeriesUsecase{err: repository.ErrUnknownRevision}
+	jr := newTestJobRepository()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	uc := usecase.NewExtractionJobUsecase(nil, nil, seriesUC, testFrequencyTableRepository{}, jr, 1)
+	assert.NoError(t, uc.Start(ctx))
+
+	submitted, _ := uc.SubmitSeries(context.TODO(), "https://github.com/eroatta/freqtable", entity.RevisionSelection{Tags: true, From: "v9.9.9"}, entity.ExtractionOptions{})
+
+	job := waitForJob(t, uc, submitted.ID)
+	assert.Equal(t, entity.JobFailed, job.Status)
+	assert.Equal(t, int64(0), job.SeriesID)
+	assert.Equal(t, repository.ErrUnknownRevision.Error(), job.Error)
+}
+
+func TestStart_OnExtractionJobUsecase_ShouldResumePendingJobs(t *testing.T) {
+	createUC := testCreateUsecase{
+		tables: map[string]entity.FrequencyTable{
+			"https://github.com/eroatta/freqtable": {ID: 1234},
+			"https://github.com/eroatta/token":     {ID: 5678},
+		},
+	}
+	jr := newTestJobRepository()
+	queued, _ := jr.Save(context.TODO(), entity.Job{URL: "https://github.com/eroatta/freqtable", Status: entity.JobQueued})
+	running, _ := jr.Save(context.TODO(), entity.Job{URL: "https://github.com/eroatta/token", Status: entity.JobRunning})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	uc := usecase.NewExtractionJobUsecase(createUC, nil, nil, testFrequencyTableRepository{}, jr, 1)
+	assert.NoError(t, uc.Start(ctx))
+
+	job := waitForJob(t, uc, queued)
+	assert.Equal(t, entity.JobSucceeded, job.Status)
+	assert.Equal(t, int64(1234), job.FrequencyTableID)
+
+	job = waitForJob(t, uc, running)
+	assert.Equal(t, entity.JobSucceeded, job.Status)
+	assert.Equal(t, int64(5678), job.FrequencyTableID)
+}
+
+func TestStart_OnExtractionJobUsecase_WhenRunningJobCreatedItsTable_ShouldRefreshExistingTable(t *testing.T) {
+	url := "https://github.com/eroatta/freqtable"
+	createUC := testCreateUsecase{err: repository.ErrDuplicated}
+	ftr := testHierarchyRepository{tables: map[int64]entity.FrequencyTable{
+		7: {ID: 7, Name: url + "@v1.0.0", Source: url, Values: map[string]int{"table": 1}},
+	}}
+	wcr := testWordCountRepository{extractions: map[string]map[string]int{url: {"table": 5}}}
+	updateUC := usecase.NewUpdateFrequencyTableUsecase(wcr, ftr)
+	jr := newTestJobRepository()
+	running, _ := jr.Save(context.TODO(), entity.Job{URL: url, Options: entity.ExtractionOptions{Ref: "v1.0.0"}, Status: entity.JobRunning})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	uc := usecase.NewExtractionJobUsecase(createUC, updateUC, nil, ftr, jr, 1)
+	assert.NoError(t, uc.Start(ctx))
+
+	job := waitForJob(t, uc, running)
+	assert.Equal(t, entity.JobSucceeded, job.Status)
+	assert.Equal(t, int64(7), job.FrequencyTableID)
+	assert.Empty(t, job.Error)
+	assert.Equal(t, map[string]int{"table": 5}, ftr.tables[7].Values)
+}
+
+func TestStart_OnExtractionJobUsecase_WhenRetrievingPendingJobs_ShouldReturnError(t *testing.T) {
+	jr := newTestJobRepository()
+	jr.err = errors.New("connection refused")
+
+	uc := usecase.NewExtractionJobUsecase(nil, nil, nil, nil, jr, 1)
+	err := uc.Start(context.TODO())
+
+	assert.EqualError(t, err, "connection refused")
+}
+
+// waitForJob polls the job until it's finished.
+func waitForJob(t *testing.T, uc usecase.ExtractionJobUsecase, id int64) entity.Job {
+	deadline := time.Now().Add(2 * time.Second)
+	for time.Now().Before(deadline) {
+		job, err := uc.Get(context.TODO(), id)
+		if err == nil && (job.Status == entity.JobSucceeded || job.Status == entity.JobFailed) {
+			return job
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	assert.FailNow(t, "job not finished on time")
+	return entity.Job{}
+}
+
+type testCreateUsecase struct {
+	tables map[string]entity.FrequencyTable
+	err    error
+}
+
+func (tc testCreateUsecase) Create(ctx context.Context, url string, options entity.ExtractionOptions) (entity.FrequencyTable, error) {
+	if ft, ok := tc.tables[url]; ok {
+		return ft, nil
+	}
+
+	return entity.FrequencyTable{}, tc.err
+}
+
+func (tc testCreateUsecase) CreateMultiple(ctx context.Context, urls []string, options entity.ExtractionOptions) []usecase.CreationResult {
+	return nil
+}
+
+type testSeriesUsecase struct {
+	sync.Mutex
+	series    entity.Series
+	err       error
+	url       string
+	selection entity.RevisionSelection
+	options   entity.ExtractionOptions
+}
+
+func (ts *testSeriesUsecase) Create(ctx context.Context, url string, selection entity.RevisionSelection, options entity.ExtractionOptions) (entity.Series, error) {
+	ts.Lock()
+	defer ts.Unlock()
+
+	ts.url = url
+	ts.selection = selection
+	ts.options = options
+	if ts.err != nil {
+		return entity.Series{}, ts.err
+	}
+
+	return ts.series, nil
+}
+
+func (ts *testSeriesUsecase) Get(ctx context.Context, id int64) (entity.Series, error) {
+	return entity.Series{}, ts.err
+}
+
+func (ts *testSeriesUsecase) Diff(ctx context.Context, id int64, limit int) ([]entity.VocabularyChange, error) {
+	return nil, ts.err
+}
+
+type testJobRepository struct {
+	sync.Mutex
+	jobs map[int64]entity.Job
+	err  error
+}
+
+func newTestJobRepository() *testJobRepository {
+	return &testJobRepository{
+		jobs: make(map[int64]entity.Job),
+	}
+}
+
+func (tj *testJobRepository) Get(ctx context.Context, id int64) (entity.Job, error) {
+	tj.Lock()
+	defer tj.Unlock()
+
+	job, ok := tj.jobs[id]
+	if !ok {
+		return entity.Job{}, repository.ErrNoResults
+	}
+
+	return job, nil
+}
+
+func (tj *testJobRepository) Save(ctx context.Context, job entity.Job) (int64, error) {
+	tj.Lock()
+	defer tj.Unlock()
+
+	if tj.err != nil {
+		return 0, tj.err
+	}
+
+	job.ID = int64(len(tj.jobs) + 1)
+	tj.jobs[job.ID] = job
+	return job.ID, nil
+}
+
+func (tj *testJobRepository) Update(ctx context.Context, job entity.Job) error {
+	tj.Lock()
+	defer tj.Unlock()
+
+	tj.jobs[job.ID] = job
+	return tj.err
+}
+
+func (tj *testJobRepository) FindByStatus(ctx context.Context, statuses ...entity.JobStatus) ([]entity.Job, error) {
+	tj.Lock()
+	defer tj.Unlock()
+
+	if tj.err != nil {
+		return nil, tj.err
+	}
+
+	jobs := make([]entity.Job, 0)
+	for id := int64(1); id <= int64(len(tj.jobs)); id++ {
+		for _, status := range statuses {
+			if tj.jobs[id].Status == status {
+				jobs = append(jobs, tj.jobs[id])
+			}
+		}
+	}
+	return jobs, nil
+}
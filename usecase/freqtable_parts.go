@@ -0,0 +1,101 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/eroatta/freqtable/entity"
+	"github.com/eroatta/freqtable/repository"
+)
+
+// rootName names the frequency table of the module or package on the root directory of a
+// repository, whose path is ".". Since a Go import path can't hold parentheses, it can't clash with
+// the directory of another module or package.
+const rootName = "(root)"
+
+// newChildren creates a child entity.FrequencyTable for each module or package extracted along
+// with the given parent, named after the parent and the path of the part.
+func newChildren(parent entity.FrequencyTable, parts []entity.ExtractionPart) []entity.FrequencyTable {
+	children := make([]entity.FrequencyTable, 0, len(parts))
+	for _, part := range parts {
+		children = append(children, entity.FrequencyTable{
+			ParentID:    parent.ID,
+			Path:        part.Path,
+			Name:        childName(parent.Name, part.Path),
+			Source:      parent.Source,
+			DateCreated: parent.DateCreated,
+			Values:      part.Values,
+			Options:     parent.Options,
+			Revision:    parent.Revision,
+		})
+	}
+
+	return children
+}
+
+// saveParts stores a child entity.FrequencyTable for each module or package extracted along with
+// the given parent, which is already stored. The children already stored for the parent are
+// updated with the new values, while the ones whose module or package no longer exists are kept as
+// they are.
+func saveParts(ctx context.Context, ftr repository.FrequencyTableRepository, parent entity.FrequencyTable, parts []entity.ExtractionPart) error {
+	if len(parts) == 0 {
+		return nil
+	}
+
+	existing, err := children(ctx, ftr, parent.ID)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	for _, child := range newChildren(parent, parts) {
+		child.DateCreated = now
+		if id, ok := existing[child.Name]; ok {
+			child.ID = id
+			child.LastUpdated = now
+			if err := ftr.Update(ctx, child); err != nil {
+				return err
+			}
+			continue
+		}
+
+		if _, err := ftr.Save(ctx, child); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// children retrieves the IDs of the child frequency tables of the given parent, by name.
+func children(ctx context.Context, ftr repository.FrequencyTableRepository, parentID int64) (map[string]int64, error) {
+	ids := make(map[string]int64)
+	filter := repository.FrequencyTableFilter{ParentID: parentID}
+	for {
+		summaries, next, err := ftr.List(ctx, filter)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, summary := range summaries {
+			ids[summary.Name] = summary.ID
+		}
+
+		if next == 0 {
+			return ids, nil
+		}
+		filter.Cursor = next
+	}
+}
+
+// childName provides the name of the frequency table of a module or package, such as
+// https://github.com/eroatta/freqtable#adapter/rest, or https://github.com/eroatta/freqtable#(root)
+// for the root directory.
+func childName(parent string, path string) string {
+	if path == "." {
+		path = rootName
+	}
+
+	return fmt.Sprintf("%s#%s", parent, path)
+}
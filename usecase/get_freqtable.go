@@ -0,0 +1,111 @@
+package usecase
+
+import (
+	"context"
+	"sort"
+
+	"github.com/eroatta/freqtable/entity"
+	"github.com/eroatta/freqtable/repository"
+)
+
+// WordOrder defines the criteria used to sort the words of a frequency table.
+type WordOrder string
+
+const (
+	// OrderByCount sorts the words from the most frequent to the least frequent one.
+	OrderByCount WordOrder = "count"
+	// OrderByWord sorts the words alphabetically.
+	OrderByWord WordOrder = "word"
+)
+
+// WordsQuery defines how the words of a frequency table must be sorted and paginated.
+type WordsQuery struct {
+	Order  WordOrder
+	Offset int
+	Limit  int
+}
+
+// GetFrequencyTableUsecase defines the contract for the use cases related to the
+// retrieval of frequency tables.
+type GetFrequencyTableUsecase interface {
+	// Get retrieves a single frequency table and the requested page of its words.
+	Get(ctx context.Context, id int64, query WordsQuery) (entity.FrequencyTable, []entity.WordCount, error)
+	// Find retrieves a single frequency table, along with its unsorted values.
+	Find(ctx context.Context, id int64) (entity.FrequencyTable, error)
+	// List retrieves a page of frequency tables matching the given filter, and the cursor for the next page.
+	List(ctx context.Context, filter repository.FrequencyTableFilter) ([]entity.FrequencyTableSummary, int64, error)
+}
+
+// defaultListLimit defines the number of frequency tables retrieved when no limit is given.
+const defaultListLimit = 50
+
+// NewGetFrequencyTableUsecase initializes a new GetFrequencyTableUsecase handler
+// with the given repository.
+func NewGetFrequencyTableUsecase(ftr repository.FrequencyTableRepository) getFrequencyTableUsecase {
+	return getFrequencyTableUsecase{
+		ftr: ftr,
+	}
+}
+
+type getFrequencyTableUsecase struct {
+	ftr repository.FrequencyTableRepository
+}
+
+// Get retrieves the entity.FrequencyTable identified by the given ID, and sorts and paginates
+// its values according to the given query.
+func (uc getFrequencyTableUsecase) Get(ctx context.Context, id int64, query WordsQuery) (entity.FrequencyTable, []entity.WordCount, error) {
+	ft, err := uc.ftr.Get(ctx, id)
+	if err != nil {
+		return entity.FrequencyTable{}, nil, err
+	}
+
+	words := make([]entity.WordCount, 0, len(ft.Values))
+	for word, count := range ft.Values {
+		words = append(words, entity.WordCount{Word: word, Count: count})
+	}
+
+	switch query.Order {
+	case OrderByWord:
+		sort.Slice(words, func(i, j int) bool {
+			return words[i].Word < words[j].Word
+		})
+	default:
+		sort.Slice(words, func(i, j int) bool {
+			if words[i].Count != words[j].Count {
+				return words[i].Count > words[j].Count
+			}
+			return words[i].Word < words[j].Word
+		})
+	}
+
+	return ft, paginate(words, query.Offset, query.Limit), nil
+}
+
+// Find retrieves the entity.FrequencyTable identified by the given ID, without sorting its values.
+func (uc getFrequencyTableUsecase) Find(ctx context.Context, id int64) (entity.FrequencyTable, error) {
+	return uc.ftr.Get(ctx, id)
+}
+
+// List retrieves the summaries of the frequency tables matching the given filter, sorted by ID.
+func (uc getFrequencyTableUsecase) List(ctx context.Context, filter repository.FrequencyTableFilter) ([]entity.FrequencyTableSummary, int64, error) {
+	if filter.Limit <= 0 {
+		filter.Limit = defaultListLimit
+	}
+
+	return uc.ftr.List(ctx, filter)
+}
+
+// paginate returns the elements within the given offset and limit. A non-positive limit
+// returns every element after the offset.
+func paginate(words []entity.WordCount, offset int, limit int) []entity.WordCount {
+	if offset < 0 || offset >= len(words) {
+		return []entity.WordCount{}
+	}
+
+	end := len(words)
+	if limit > 0 && offset+limit < end {
+		end = offset + limit
+	}
+
+	return words[offset:end]
+}
@@ -0,0 +1,150 @@
+package usecase_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/eroatta/freqtable/entity"
+	"github.com/eroatta/freqtable/repository"
+	"github.com/eroatta/freqtable/usecase"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewGetFrequencyTableUsecase_ShouldReturnNewInstance(t *testing.T) {
+	uc := usecase.NewGetFrequencyTableUsecase(nil)
+
+	assert.NotNil(t, uc)
+}
+
+func TestGet_OnGetFrequencyTableUsecase_WhenMissingFrequencyTable_ShouldReturnError(t *testing.T) {
+	ftr := testFrequencyTableRepository{
+		frequencyTables: map[int64]entity.FrequencyTable{},
+	}
+
+	uc := usecase.NewGetFrequencyTableUsecase(ftr)
+	ft, words, err := uc.Get(context.TODO(), 1, usecase.WordsQuery{})
+
+	assert.Equal(t, repository.ErrNoResults, err)
+	assert.Equal(t, entity.FrequencyTable{}, ft)
+	assert.Nil(t, words)
+}
+
+func TestGet_OnGetFrequencyTableUsecase_ShouldSortAndPaginateWords(t *testing.T) {
+	ftr := testFrequencyTableRepository{
+		frequencyTables: map[int64]entity.FrequencyTable{
+			1: {
+				ID:   1,
+				Name: "https://github.com/eroatta/freqtable",
+				Values: map[string]int{
+					"frequency": 2,
+					"table":     5,
+					"word":      2,
+					"count":     1,
+				},
+			},
+		},
+	}
+
+	var tests = []struct {
+		name     string
+		query    usecase.WordsQuery
+		expected []entity.WordCount
+	}{
+		{"ByCount", usecase.WordsQuery{Order: usecase.OrderByCount},
+			[]entity.WordCount{{Word: "table", Count: 5}, {Word: "frequency", Count: 2}, {Word: "word", Count: 2}, {Word: "count", Count: 1}}},
+		{"ByWord", usecase.WordsQuery{Order: usecase.OrderByWord},
+			[]entity.WordCount{{Word: "count", Count: 1}, {Word: "frequency", Count: 2}, {Word: "table", Count: 5}, {Word: "word", Count: 2}}},
+		{"ByCountWithOffsetAndLimit", usecase.WordsQuery{Order: usecase.OrderByCount, Offset: 1, Limit: 2},
+			[]entity.WordCount{{Word: "frequency", Count: 2}, {Word: "word", Count: 2}}},
+		{"ByWordWithLimitBeyondEnd", usecase.WordsQuery{Order: usecase.OrderByWord, Offset: 3, Limit: 10},
+			[]entity.WordCount{{Word: "word", Count: 2}}},
+		{"OffsetBeyondEnd", usecase.WordsQuery{Offset: 4, Limit: 10},
+			[]entity.WordCount{}},
+	}
+
+	uc := usecase.NewGetFrequencyTableUsecase(ftr)
+	for _, fixture := range tests {
+		t.Run(fixture.name, func(t *testing.T) {
+			ft, words, err := uc.Get(context.TODO(), 1, fixture.query)
+
+			assert.NoError(t, err)
+			assert.Equal(t, int64(1), ft.ID)
+			assert.Equal(t, 4, len(ft.Values))
+			assert.Equal(t, fixture.expected, words)
+		})
+	}
+}
+
+func TestFind_OnGetFrequencyTableUsecase_ShouldReturnFrequencyTable(t *testing.T) {
+	ftr := testFrequencyTableRepository{
+		frequencyTables: map[int64]entity.FrequencyTable{
+			1: {ID: 1, Name: "https://github.com/eroatta/freqtable", Values: map[string]int{"table": 5}},
+		},
+	}
+
+	uc := usecase.NewGetFrequencyTableUsecase(ftr)
+	ft, err := uc.Find(context.TODO(), 1)
+
+	assert.NoError(t, err)
+	assert.Equal(t, "https://github.com/eroatta/freqtable", ft.Name)
+	assert.Equal(t, map[string]int{"table": 5}, ft.Values)
+
+	_, err = uc.Find(context.TODO(), 2)
+	assert.Equal(t, repository.ErrNoResults, err)
+}
+
+func TestList_OnGetFrequencyTableUsecase_WithoutLimit_ShouldUseDefaultLimit(t *testing.T) {
+	var filter repository.FrequencyTableFilter
+	ftr := testFrequencyTableRepository{
+		summaries: []entity.FrequencyTableSummary{
+			{ID: 1, Name: "https://github.com/eroatta/freqtable", Vocabulary: 10},
+		},
+		filter: &filter,
+	}
+
+	uc := usecase.NewGetFrequencyTableUsecase(ftr)
+	summaries, next, err := uc.List(context.TODO(), repository.FrequencyTableFilter{Name: "eroatta"})
+
+	assert.NoError(t, err)
+	assert.Equal(t, int64(0), next)
+	assert.Equal(t, 1, len(summaries))
+	assert.Equal(t, "eroatta", filter.Name)
+	assert.Equal(t, 50, filter.Limit)
+}
+
+func TestList_OnGetFrequencyTableUsecase_ShouldReturnSummariesAndCursor(t *testing.T) {
+	now := time.Now()
+	var filter repository.FrequencyTableFilter
+	ftr := testFrequencyTableRepository{
+		summaries: []entity.FrequencyTableSummary{
+			{ID: 1, Name: "https://github.com/eroatta/freqtable", DateCreated: now, Vocabulary: 10},
+			{ID: 2, Name: "https://github.com/eroatta/token", DateCreated: now, Vocabulary: 20},
+		},
+		filter: &filter,
+		id:     2,
+	}
+
+	uc := usecase.NewGetFrequencyTableUsecase(ftr)
+	summaries, next, err := uc.List(context.TODO(), repository.FrequencyTableFilter{MinVocabulary: 5, Limit: 2})
+
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2), next)
+	assert.Equal(t, 2, len(summaries))
+	assert.Equal(t, 5, filter.MinVocabulary)
+	assert.Equal(t, 2, filter.Limit)
+}
+
+func TestList_OnGetFrequencyTableUsecase_WhenRepositoryFails_ShouldReturnError(t *testing.T) {
+	ftr := testFrequencyTableRepository{
+		err: errors.New("connection refused"),
+	}
+
+	uc := usecase.NewGetFrequencyTableUsecase(ftr)
+	summaries, next, err := uc.List(context.TODO(), repository.FrequencyTableFilter{})
+
+	assert.EqualError(t, err, "connection refused")
+	assert.Equal(t, int64(0), next)
+	assert.Nil(t, summaries)
+}
@@ -0,0 +1,68 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/eroatta/freqtable/entity"
+	"github.com/eroatta/freqtable/repository"
+)
+
+var (
+	// ErrNotEnoughFrequencyTables indicates that less than two frequency tables were provided for merging.
+	ErrNotEnoughFrequencyTables = errors.New("At least two frequency tables are required for merging")
+)
+
+// MergeFrequencyTableUsecase defines the contract for the use case related to the
+// merging of several frequency tables into a new one.
+type MergeFrequencyTableUsecase interface {
+	// Merge creates a new frequency table by merging the frequency tables identified by the given IDs.
+	Merge(ctx context.Context, name string, ids []int64) (entity.FrequencyTable, error)
+}
+
+// NewMergeFrequencyTableUsecase initializes a new MergeFrequencyTableUsecase handler
+// with the given repository.
+func NewMergeFrequencyTableUsecase(ftr repository.FrequencyTableRepository) mergeFrequencyTableUsecase {
+	return mergeFrequencyTableUsecase{
+		ftr: ftr,
+	}
+}
+
+type mergeFrequencyTableUsecase struct {
+	ftr repository.FrequencyTableRepository
+}
+
+// Merge retrieves every entity.FrequencyTable identified by the given IDs, sums their values
+// and saves the result as a new entity.FrequencyTable under the given name.
+func (uc mergeFrequencyTableUsecase) Merge(ctx context.Context, name string, ids []int64) (entity.FrequencyTable, error) {
+	if len(ids) < 2 {
+		return entity.FrequencyTable{}, ErrNotEnoughFrequencyTables
+	}
+
+	values := make(map[string]int)
+	for _, id := range ids {
+		ft, err := uc.ftr.Get(ctx, id)
+		if err != nil {
+			return entity.FrequencyTable{}, err
+		}
+
+		for word, count := range ft.Values {
+			values[word] += count
+		}
+	}
+
+	merged := entity.FrequencyTable{
+		Name:        name,
+		DateCreated: time.Now(),
+		Values:      values,
+	}
+
+	id, err := uc.ftr.Save(ctx, merged)
+	if err != nil {
+		return entity.FrequencyTable{}, err
+	}
+	merged.ID = id
+
+	return merged, nil
+}
@@ -0,0 +1,78 @@
+package usecase_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/eroatta/freqtable/entity"
+	"github.com/eroatta/freqtable/repository"
+	"github.com/eroatta/freqtable/usecase"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewMergeFrequencyTableUsecase_ShouldReturnNewInstance(t *testing.T) {
+	uc := usecase.NewMergeFrequencyTableUsecase(nil)
+
+	assert.NotNil(t, uc)
+}
+
+func TestMerge_OnMergeFrequencyTableUsecase_WhenLessThanTwoIDs_ShouldReturnError(t *testing.T) {
+	uc := usecase.NewMergeFrequencyTableUsecase(nil)
+	ft, err := uc.Merge(context.TODO(), "global", []int64{1})
+
+	assert.Equal(t, usecase.ErrNotEnoughFrequencyTables, err)
+	assert.Equal(t, entity.FrequencyTable{}, ft)
+}
+
+func TestMerge_OnMergeFrequencyTableUsecase_WhenMissingFrequencyTable_ShouldReturnError(t *testing.T) {
+	ftr := testFrequencyTableRepository{
+		frequencyTables: map[int64]entity.FrequencyTable{
+			1: {ID: 1, Values: map[string]int{"frequency": 2}},
+		},
+	}
+
+	uc := usecase.NewMergeFrequencyTableUsecase(ftr)
+	ft, err := uc.Merge(context.TODO(), "global", []int64{1, 2})
+
+	assert.Equal(t, repository.ErrNoResults, err)
+	assert.Equal(t, entity.FrequencyTable{}, ft)
+}
+
+func TestMerge_OnMergeFrequencyTableUsecase_WhenSavingResults_ShouldReturnError(t *testing.T) {
+	ftr := testFrequencyTableRepository{
+		frequencyTables: map[int64]entity.FrequencyTable{
+			1: {ID: 1, Values: map[string]int{"frequency": 2}},
+			2: {ID: 2, Values: map[string]int{"table": 3}},
+		},
+		err: errors.New("error while persisting"),
+	}
+
+	uc := usecase.NewMergeFrequencyTableUsecase(ftr)
+	ft, err := uc.Merge(context.TODO(), "global", []int64{1, 2})
+
+	assert.EqualError(t, err, "error while persisting")
+	assert.Equal(t, entity.FrequencyTable{}, ft)
+}
+
+func TestMerge_OnMergeFrequencyTableUsecase_ShouldSumValues(t *testing.T) {
+	ftr := testFrequencyTableRepository{
+		frequencyTables: map[int64]entity.FrequencyTable{
+			1: {ID: 1, Values: map[string]int{"frequency": 2, "table": 1}},
+			2: {ID: 2, Values: map[string]int{"table": 3, "word": 4}},
+		},
+		id: 1234567890,
+	}
+
+	uc := usecase.NewMergeFrequencyTableUsecase(ftr)
+	ft, err := uc.Merge(context.TODO(), "global", []int64{1, 2})
+
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1234567890), ft.ID)
+	assert.Equal(t, "global", ft.Name)
+	assert.Equal(t, map[string]int{
+		"frequency": 2,
+		"table":     4,
+		"word":      4,
+	}, ft.Values)
+}
@@ -0,0 +1,79 @@
+package usecase
+
+import (
+	"context"
+
+	"github.com/eroatta/freqtable/entity"
+	"github.com/eroatta/freqtable/repository"
+)
+
+// QueryFrequencyTableUsecase defines the contract for the use cases related to the
+// analysis of the words stored on the frequency tables.
+type QueryFrequencyTableUsecase interface {
+	// Top retrieves the most frequent words of a frequency table, ranked from the most frequent one.
+	Top(ctx context.Context, id int64, n int, min int) ([]entity.RankedWord, error)
+	// Lookup retrieves the usage of a word on every frequency table containing it.
+	Lookup(ctx context.Context, word string) ([]entity.WordUsage, error)
+}
+
+// defaultTopLimit defines the number of words retrieved when no limit is given.
+const defaultTopLimit = 100
+
+// NewQueryFrequencyTableUsecase initializes a new QueryFrequencyTableUsecase handler
+// with the given repository.
+func NewQueryFrequencyTableUsecase(ftr repository.FrequencyTableRepository) queryFrequencyTableUsecase {
+	return queryFrequencyTableUsecase{
+		ftr: ftr,
+	}
+}
+
+type queryFrequencyTableUsecase struct {
+	ftr repository.FrequencyTableRepository
+}
+
+// Top retrieves up to n words with at least min occurrences from the entity.FrequencyTable identified
+// by the given ID, along with their rank and their frequency relative to the total number of occurrences.
+func (uc queryFrequencyTableUsecase) Top(ctx context.Context, id int64, n int, min int) ([]entity.RankedWord, error) {
+	if n <= 0 {
+		n = defaultTopLimit
+	}
+
+	words, total, err := uc.ftr.Top(ctx, id, n, min)
+	if err != nil {
+		return nil, err
+	}
+
+	ranked := make([]entity.RankedWord, 0, len(words))
+	for i, wc := range words {
+		var frequency float64
+		if total > 0 {
+			frequency = float64(wc.Count) / float64(total)
+		}
+
+		ranked = append(ranked, entity.RankedWord{
+			Rank:      i + 1,
+			Word:      wc.Word,
+			Count:     wc.Count,
+			Frequency: frequency,
+		})
+	}
+
+	return ranked, nil
+}
+
+// Lookup retrieves the occurrences of the given word on every entity.FrequencyTable, along with
+// its frequency relative to the total number of occurrences on each of them.
+func (uc queryFrequencyTableUsecase) Lookup(ctx context.Context, word string) ([]entity.WordUsage, error) {
+	usages, err := uc.ftr.FindWord(ctx, word)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range usages {
+		if usages[i].Total > 0 {
+			usages[i].Frequency = float64(usages[i].Count) / float64(usages[i].Total)
+		}
+	}
+
+	return usages, nil
+}
@@ -0,0 +1,99 @@
+package usecase_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/eroatta/freqtable/entity"
+	"github.com/eroatta/freqtable/repository"
+	"github.com/eroatta/freqtable/usecase"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewQueryFrequencyTableUsecase_ShouldReturnNewInstance(t *testing.T) {
+	uc := usecase.NewQueryFrequencyTableUsecase(nil)
+
+	assert.NotNil(t, uc)
+}
+
+func TestTop_OnQueryFrequencyTableUsecase_WhenMissingFrequencyTable_ShouldReturnError(t *testing.T) {
+	ftr := testFrequencyTableRepository{
+		err: repository.ErrNoResults,
+	}
+
+	uc := usecase.NewQueryFrequencyTableUsecase(ftr)
+	words, err := uc.Top(context.TODO(), 1, 10, 0)
+
+	assert.Equal(t, repository.ErrNoResults, err)
+	assert.Nil(t, words)
+}
+
+func TestTop_OnQueryFrequencyTableUsecase_ShouldReturnRankedWords(t *testing.T) {
+	var limit int
+	ftr := testFrequencyTableRepository{
+		words: []entity.WordCount{
+			{Word: "ctx", Count: 8},
+			{Word: "err", Count: 6},
+			{Word: "cfg", Count: 2},
+		},
+		total:    40,
+		topLimit: &limit,
+	}
+
+	uc := usecase.NewQueryFrequencyTableUsecase(ftr)
+	words, err := uc.Top(context.TODO(), 1, 3, 2)
+
+	assert.NoError(t, err)
+	assert.Equal(t, 3, limit)
+	assert.Equal(t, []entity.RankedWord{
+		{Rank: 1, Word: "ctx", Count: 8, Frequency: 0.2},
+		{Rank: 2, Word: "err", Count: 6, Frequency: 0.15},
+		{Rank: 3, Word: "cfg", Count: 2, Frequency: 0.05},
+	}, words)
+}
+
+func TestTop_OnQueryFrequencyTableUsecase_WhenNoLimit_ShouldUseDefaultLimit(t *testing.T) {
+	var limit int
+	ftr := testFrequencyTableRepository{
+		words:    []entity.WordCount{},
+		topLimit: &limit,
+	}
+
+	uc := usecase.NewQueryFrequencyTableUsecase(ftr)
+	words, err := uc.Top(context.TODO(), 1, 0, 0)
+
+	assert.NoError(t, err)
+	assert.Equal(t, 100, limit)
+	assert.Empty(t, words)
+}
+
+func TestLookup_OnQueryFrequencyTableUsecase_WhenErrorFinding_ShouldReturnError(t *testing.T) {
+	ftr := testFrequencyTableRepository{
+		err: errors.New("error while finding"),
+	}
+
+	uc := usecase.NewQueryFrequencyTableUsecase(ftr)
+	usages, err := uc.Lookup(context.TODO(), "ctx")
+
+	assert.EqualError(t, err, "error while finding")
+	assert.Nil(t, usages)
+}
+
+func TestLookup_OnQueryFrequencyTableUsecase_ShouldReturnUsagesWithFrequency(t *testing.T) {
+	ftr := testFrequencyTableRepository{
+		usages: []entity.WordUsage{
+			{FrequencyTableID: 2, FrequencyTableName: "https://github.com/eroatta/token", Count: 10, Total: 40},
+			{FrequencyTableID: 1, FrequencyTableName: "https://github.com/eroatta/freqtable", Count: 5, Total: 100},
+		},
+	}
+
+	uc := usecase.NewQueryFrequencyTableUsecase(ftr)
+	usages, err := uc.Lookup(context.TODO(), "ctx")
+
+	assert.NoError(t, err)
+	assert.Equal(t, []entity.WordUsage{
+		{FrequencyTableID: 2, FrequencyTableName: "https://github.com/eroatta/token", Count: 10, Total: 40, Frequency: 0.25},
+		{FrequencyTableID: 1, FrequencyTableName: "https://github.com/eroatta/freqtable", Count: 5, Total: 100, Frequency: 0.05},
+	}, usages)
+}
@@ -0,0 +1,269 @@
+package usecase
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/eroatta/freqtable/entity"
+	"github.com/eroatta/freqtable/repository"
+)
+
+// SeriesUsecase defines the contract for the use cases related to the series of frequency
+// tables extracted from the history of a repository.
+type SeriesUsecase interface {
+	// Create extracts a frequency table from each selected revision of a repository, and links
+	// them together as a series.
+	Create(ctx context.Context, url string, selection entity.RevisionSelection, options entity.ExtractionOptions) (entity.Series, error)
+	// Get retrieves a single series, along with its entries.
+	Get(ctx context.Context, id int64) (entity.Series, error)
+	// Diff retrieves the changes of vocabulary between each pair of consecutive entries of a series.
+	Diff(ctx context.Context, id int64, limit int) ([]entity.VocabularyChange, error)
+}
+
+// defaultDiffLimit defines the number of added, removed and changed words retrieved on each
+// change of vocabulary when no limit is given.
+const defaultDiffLimit = 20
+
+// NewSeriesUsecase initializes a new SeriesUsecase handler with the given repositories.
+func NewSeriesUsecase(wcr repository.WordCountRepository, ftr repository.FrequencyTableRepository, sr repository.SeriesRepository) seriesUsecase {
+	return seriesUsecase{
+		wcr: wcr,
+		ftr: ftr,
+		sr:  sr,
+	}
+}
+
+type seriesUsecase struct {
+	wcr repository.WordCountRepository
+	ftr repository.FrequencyTableRepository
+	sr  repository.SeriesRepository
+}
+
+// Create extracts the revisions of the given URL chosen by the selection, and stores a new
+// entity.FrequencyTable for each one, named after the URL and the tag or commit hash of the
+// revision. A frequency table already extracted from the same revision is updated with the new
+// extraction, so several series can share it, unless it was extracted with other options, in which
+// case repository.ErrDuplicated is returned. Each table is stored as soon as its revision is
+// extracted, and the ones already stored are kept if a later revision fails, so they're updated
+// instead of duplicated on a retry. The series is stored once every table is stored.
+func (uc seriesUsecase) Create(ctx context.Context, url string, selection entity.RevisionSelection, options entity.ExtractionOptions) (entity.Series, error) {
+	series := entity.Series{
+		Name:        url,
+		Selection:   selection,
+		Options:     options,
+		DateCreated: time.Now(),
+	}
+
+	series.Entries = make([]entity.SeriesEntry, 0)
+	err := uc.wcr.ExtractHistory(ctx, url, selection, options, func(extraction entity.Extraction) error {
+		id, err := uc.save(ctx, url, extraction)
+		if err != nil {
+			return err
+		}
+
+		entry := entity.SeriesEntry{
+			FrequencyTableID: id,
+			Ref:              extraction.Options.Ref,
+		}
+		if extraction.Revision != nil {
+			entry.Revision = *extraction.Revision
+		}
+		series.Entries = append(series.Entries, entry)
+		return nil
+	})
+	if err != nil {
+		return entity.Series{}, err
+	}
+
+	id, err := uc.sr.Save(ctx, series)
+	if err != nil {
+		return entity.Series{}, err
+	}
+	series.ID = id
+
+	return series, nil
+}
+
+// save stores the extraction of a revision as a new entity.FrequencyTable, or updates the
+// existing one with the same name, along with the child tables of its modules or packages, and
+// returns its ID. An existing table is only updated if it was extracted from the same URL with the
+// same options, so a table created on its own with other options is never overwritten, and
+// repository.ErrDuplicated is returned instead.
+func (uc seriesUsecase) save(ctx context.Context, url string, extraction entity.Extraction) (int64, error) {
+	now := time.Now()
+	ft := entity.FrequencyTable{
+		Name:        tableName(url, extraction.Options.Ref),
+		Source:      url,
+		Values:      extraction.Values,
+		DateCreated: now,
+		Options:     &extraction.Options,
+		Revision:    extraction.Revision,
+		Report:      &extraction.Report,
+	}
+
+	id, err := uc.ftr.SaveWithChildren(ctx, ft, newChildren(ft, extraction.Parts))
+	switch err {
+	case nil:
+		return id, nil
+	case repository.ErrDuplicated:
+		id, err := uc.ftr.FindByName(ctx, ft.Name)
+		if err != nil {
+			return 0, err
+		}
+
+		existing, err := uc.ftr.Get(ctx, id)
+		if err != nil {
+			return 0, err
+		}
+		if existing.Source != url || !sameOptions(existing.Options, extraction.Options) {
+			return 0, repository.ErrDuplicated
+		}
+		ft.ID = id
+		ft.LastUpdated = now
+
+		if err := uc.ftr.Update(ctx, ft); err != nil {
+			return 0, err
+		}
+	default:
+		return 0, err
+	}
+
+	return ft.ID, saveParts(ctx, uc.ftr, ft, extraction.Parts)
+}
+
+// sameOptions checks if the stored options of a frequency table match the given ones. Missing and
+// empty patterns are the same.
+func sameOptions(stored *entity.ExtractionOptions, options entity.ExtractionOptions) bool {
+	if stored == nil {
+		return false
+	}
+
+	sameSkipGenerated := stored.SkipGenerated == nil && options.SkipGenerated == nil ||
+		stored.SkipGenerated != nil && options.SkipGenerated != nil && *stored.SkipGenerated == *options.SkipGenerated
+
+	return stored.Ref == options.Ref &&
+		samePatterns(stored.Include, options.Include) &&
+		samePatterns(stored.Exclude, options.Exclude) &&
+		sameSkipGenerated &&
+		stored.TestFiles == options.TestFiles &&
+		stored.Granularity == options.Granularity
+}
+
+// samePatterns checks if both lists hold the same patterns, in the same order.
+func samePatterns(a []string, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+
+	return true
+}
+
+// Get retrieves the entity.Series identified by the given ID.
+func (uc seriesUsecase) Get(ctx context.Context, id int64) (entity.Series, error) {
+	return uc.sr.Get(ctx, id)
+}
+
+// Diff compares the frequency tables of each pair of consecutive entries of the entity.Series
+// identified by the given ID. Each change holds up to limit added and removed words, sorted from
+// the most frequent, and up to limit changed words, sorted from the largest change of count.
+func (uc seriesUsecase) Diff(ctx context.Context, id int64, limit int) ([]entity.VocabularyChange, error) {
+	if limit <= 0 {
+		limit = defaultDiffLimit
+	}
+
+	series, err := uc.sr.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	changes := make([]entity.VocabularyChange, 0)
+	var previous entity.FrequencyTable
+	for i, entry := range series.Entries {
+		current, err := uc.ftr.Get(ctx, entry.FrequencyTableID)
+		if err != nil {
+			return nil, err
+		}
+
+		if i > 0 {
+			changes = append(changes, diff(series.Entries[i-1], previous, entry, current, limit))
+		}
+		previous = current
+	}
+
+	return changes, nil
+}
+
+// diff compares the values of two frequency tables, keeping up to limit words of each kind.
+func diff(fromEntry entity.SeriesEntry, from entity.FrequencyTable, toEntry entity.SeriesEntry, to entity.FrequencyTable, limit int) entity.VocabularyChange {
+	change := entity.VocabularyChange{
+		From:           fromEntry,
+		To:             toEntry,
+		FromVocabulary: len(from.Values),
+		ToVocabulary:   len(to.Values),
+		Added:          make([]entity.WordCount, 0),
+		Removed:        make([]entity.WordCount, 0),
+		Changed:        make([]entity.WordChange, 0),
+	}
+
+	for word, count := range to.Values {
+		before, ok := from.Values[word]
+		switch {
+		case !ok:
+			change.Added = append(change.Added, entity.WordCount{Word: word, Count: count})
+		case before != count:
+			change.Changed = append(change.Changed, entity.WordChange{Word: word, Before: before, After: count})
+		}
+	}
+	for word, count := range from.Values {
+		if _, ok := to.Values[word]; !ok {
+			change.Removed = append(change.Removed, entity.WordCount{Word: word, Count: count})
+		}
+	}
+
+	sortWordCounts(change.Added)
+	sortWordCounts(change.Removed)
+	sort.Slice(change.Changed, func(i, j int) bool {
+		di, dj := delta(change.Changed[i]), delta(change.Changed[j])
+		if di != dj {
+			return di > dj
+		}
+		return change.Changed[i].Word < change.Changed[j].Word
+	})
+
+	if len(change.Added) > limit {
+		change.Added = change.Added[:limit]
+	}
+	if len(change.Removed) > limit {
+		change.Removed = change.Removed[:limit]
+	}
+	if len(change.Changed) > limit {
+		change.Changed = change.Changed[:limit]
+	}
+
+	return change
+}
+
+// sortWordCounts sorts the words from the most frequent, and then alphabetically.
+func sortWordCounts(words []entity.WordCount) {
+	sort.Slice(words, func(i, j int) bool {
+		if words[i].Count != words[j].Count {
+			return words[i].Count > words[j].Count
+		}
+		return words[i].Word < words[j].Word
+	})
+}
+
+// delta provides the absolute change of count of a word.
+func delta(change entity.WordChange) int {
+	if change.After > change.Before {
+		return change.After - change.Before
+	}
+	return change.Before - change.After
+}
@@ -0,0 +1,198 @@
+package usecase_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/eroatta/freqtable/entity"
+	"github.com/eroatta/freqtable/repository"
+	"github.com/eroatta/freqtable/usecase"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewSeriesUsecase_ShouldReturnNewInstance(t *testing.T) {
+	uc := usecase.NewSeriesUsecase(nil, nil, nil)
+
+	assert.NotNil(t, uc)
+}
+
+func TestCreate_OnSeriesUsecase_WhenErrorExtracting_ShouldReturnError(t *testing.T) {
+	sr := &testSeriesRepository{}
+	uc := usecase.NewSeriesUsecase(testWordCountRepository{err: repository.ErrHistoryUnsupported}, testFrequencyTableRepository{}, sr)
+
+	series, err := uc.Create(context.TODO(), "https://github.com/eroatta/freqtable.tar.gz", entity.RevisionSelection{Tags: true}, entity.ExtractionOptions{})
+
+	assert.Empty(t, series)
+	assert.Equal(t, repository.ErrHistoryUnsupported, err)
+	assert.Nil(t, sr.saved)
+}
+
+func TestCreate_OnSeriesUsecase_ShouldCreateFrequencyTableForEachRevision(t *testing.T) {
+	date := time.Date(2020, time.March, 1, 10, 0, 0, 0, time.UTC)
+	wcr := testWordCountRepository{
+		history: []entity.Extraction{
+			{Values: map[string]int{"table": 1}, Options: entity.ExtractionOptions{Ref: "v0.1.0"},
+				Revision: &entity.Revision{Hash: "a1", Date: date}},
+			{Values: map[string]int{"table": 2}, Options: entity.ExtractionOptions{Ref: "v0.2.0"},
+				Revision: &entity.Revision{Hash: "b2", Date: date}},
+		},
+	}
+	sr := &testSeriesRepository{id: 42}
+	uc := usecase.NewSeriesUsecase(wcr, testFrequencyTableRepository{id: 7}, sr)
+
+	selection := entity.RevisionSelection{Tags: true, From: "v0.1.0"}
+	series, err := uc.Create(context.TODO(), "https://github.com/eroatta/freqtable", selection, entity.ExtractionOptions{})
+
+	assert.NoError(t, err)
+	assert.Equal(t, int64(42), series.ID)
+	assert.Equal(t, "https://github.com/eroatta/freqtable", series.Name)
+	assert.Equal(t, selection, series.Selection)
+	assert.Equal(t, []entity.SeriesEntry{
+		{FrequencyTableID: 7, Ref: "v0.1.0", Revision: entity.Revision{Hash: "a1", Date: date}},
+		{FrequencyTableID: 7, Ref: "v0.2.0", Revision: entity.Revision{Hash: "b2", Date: date}},
+	}, series.Entries)
+	assert.Equal(t, series.Entries, sr.saved.Entries)
+}
+
+func TestCreate_OnSeriesUsecase_WhenRevisionAlreadyExtracted_ShouldUpdateExistingFrequencyTable(t *testing.T) {
+	wcr := testWordCountRepository{
+		history: []entity.Extraction{
+			{Values: map[string]int{"table": 1}, Options: entity.ExtractionOptions{Ref: "v0.1.0"}},
+		},
+	}
+	var updated entity.FrequencyTable
+	ftr := testFrequencyTableRepository{
+		saveErr: repository.ErrDuplicated,
+		names: map[string]int64{
+			"https://github.com/eroatta/freqtable@v0.1.0-rc1": 5,
+			"https://github.com/eroatta/freqtable@v0.1.0":     6,
+		},
+		frequencyTables: map[int64]entity.FrequencyTable{
+			6: {ID: 6, Name: "https://github.com/eroatta/freqtable@v0.1.0", Source: "https://github.com/eroatta/freqtable",
+				Options: &entity.ExtractionOptions{Ref: "v0.1.0"}},
+		},
+		updated: &updated,
+	}
+	uc := usecase.NewSeriesUsecase(wcr, ftr, &testSeriesRepository{id: 42})
+
+	series, err := uc.Create(context.TODO(), "https://github.com/eroatta/freqtable", entity.RevisionSelection{Tags: true}, entity.ExtractionOptions{})
+
+	assert.NoError(t, err)
+	assert.Equal(t, int64(6), updated.ID)
+	assert.Equal(t, map[string]int{"table": 1}, updated.Values)
+	assert.Equal(t, int64(6), series.Entries[0].FrequencyTableID)
+}
+
+func TestCreate_OnSeriesUsecase_WhenRevisionExtractedWithOtherOptions_ShouldReturnErrorWithoutUpdating(t *testing.T) {
+	wcr := testWordCountRepository{
+		history: []entity.Extraction{
+			{Values: map[string]int{"table": 1}, Options: entity.ExtractionOptions{Ref: "v0.1.0", Granularity: entity.GranularityPackage}},
+		},
+	}
+	var updated entity.FrequencyTable
+	ftr := testFrequencyTableRepository{
+		saveErr: repository.ErrDuplicated,
+		names:   map[string]int64{"https://github.com/eroatta/freqtable@v0.1.0": 6},
+		frequencyTables: map[int64]entity.FrequencyTable{
+			6: {ID: 6, Name: "https://github.com/eroatta/freqtable@v0.1.0", Source: "https://github.com/eroatta/freqtable",
+				Options: &entity.ExtractionOptions{Ref: "v0.1.0"}},
+		},
+		updated: &updated,
+	}
+	sr := &testSeriesRepository{id: 42}
+	uc := usecase.NewSeriesUsecase(wcr, ftr, sr)
+
+	series, err := uc.Create(context.TODO(), "https://github.com/eroatta/freqtable", entity.RevisionSelection{Tags: true}, entity.ExtractionOptions{})
+
+	assert.Empty(t, series)
+	assert.Equal(t, repository.ErrDuplicated, err)
+	assert.Empty(t, updated)
+	assert.Nil(t, sr.saved)
+}
+
+func TestCreate_OnSeriesUsecase_WhenErrorExtractingLaterRevision_ShouldKeepStoredFrequencyTables(t *testing.T) {
+	wcr := testWordCountRepository{
+		history: []entity.Extraction{
+			{Values: map[string]int{"table": 1}, Options: entity.ExtractionOptions{Ref: "v0.1.0"}},
+		},
+		err: repository.ErrRepositoryTooLarge,
+	}
+	ftr := testHierarchyRepository{tables: make(map[int64]entity.FrequencyTable)}
+	sr := &testSeriesRepository{id: 42}
+	uc := usecase.NewSeriesUsecase(wcr, ftr, sr)
+
+	series, err := uc.Create(context.TODO(), "https://github.com/eroatta/freqtable", entity.RevisionSelection{Tags: true}, entity.ExtractionOptions{})
+
+	assert.Empty(t, series)
+	assert.Equal(t, repository.ErrRepositoryTooLarge, err)
+	assert.Nil(t, sr.saved)
+	assert.Equal(t, 1, len(ftr.tables))
+	assert.Equal(t, "https://github.com/eroatta/freqtable@v0.1.0", ftr.tables[1].Name)
+}
+
+func TestDiff_OnSeriesUsecase_WhenMissingSeries_ShouldReturnError(t *testing.T) {
+	uc := usecase.NewSeriesUsecase(nil, testFrequencyTableRepository{}, &testSeriesRepository{err: repository.ErrNoResults})
+
+	changes, err := uc.Diff(context.TODO(), 42, 0)
+
+	assert.Nil(t, changes)
+	assert.Equal(t, repository.ErrNoResults, err)
+}
+
+func TestDiff_OnSeriesUsecase_ShouldReturnChangesBetweenConsecutiveEntries(t *testing.T) {
+	entries := []entity.SeriesEntry{
+		{FrequencyTableID: 1, Ref: "v0.1.0"},
+		{FrequencyTableID: 2, Ref: "v0.2.0"},
+		{FrequencyTableID: 3, Ref: "v0.3.0"},
+	}
+	sr := &testSeriesRepository{series: entity.Series{ID: 42, Entries: entries}}
+	ftr := testFrequencyTableRepository{
+		frequencyTables: map[int64]entity.FrequencyTable{
+			1: {ID: 1, Values: map[string]int{"table": 2, "frequency": 1, "word": 4}},
+			2: {ID: 2, Values: map[string]int{"table": 8, "frequency": 1, "word": 3, "series": 2, "revision": 5}},
+			3: {ID: 3, Values: map[string]int{"table": 8}},
+		},
+	}
+	uc := usecase.NewSeriesUsecase(nil, ftr, sr)
+
+	changes, err := uc.Diff(context.TODO(), 42, 1)
+
+	assert.NoError(t, err)
+	assert.Equal(t, []entity.VocabularyChange{
+		{
+			From:           entries[0],
+			To:             entries[1],
+			FromVocabulary: 3,
+			ToVocabulary:   5,
+			Added:          []entity.WordCount{{Word: "revision", Count: 5}},
+			Removed:        []entity.WordCount{},
+			Changed:        []entity.WordChange{{Word: "table", Before: 2, After: 8}},
+		},
+		{
+			From:           entries[1],
+			To:             entries[2],
+			FromVocabulary: 5,
+			ToVocabulary:   1,
+			Added:          []entity.WordCount{},
+			Removed:        []entity.WordCount{{Word: "revision", Count: 5}},
+			Changed:        []entity.WordChange{},
+		},
+	}, changes)
+}
+
+type testSeriesRepository struct {
+	series entity.Series
+	saved  *entity.Series
+	id     int64
+	err    error
+}
+
+func (ts *testSeriesRepository) Get(ctx context.Context, id int64) (entity.Series, error) {
+	return ts.series, ts.err
+}
+
+func (ts *testSeriesRepository) Save(ctx context.Context, series entity.Series) (int64, error) {
+	ts.saved = &series
+	return ts.id, ts.err
+}
@@ -0,0 +1,79 @@
+package usecase
+
+import (
+	"context"
+	"time"
+
+	"github.com/eroatta/freqtable/entity"
+	"github.com/eroatta/freqtable/repository"
+)
+
+// UpdateFrequencyTableUsecase defines the contract for the use cases related to the
+// update of existing frequency tables.
+type UpdateFrequencyTableUsecase interface {
+	// Refresh extracts again the values of an existing frequency table.
+	Refresh(ctx context.Context, id int64) (entity.FrequencyTable, error)
+}
+
+// NewUpdateFrequencyTableUsecase initializes a new UpdateFrequencyTableUsecase handler
+// with the given repositories.
+func NewUpdateFrequencyTableUsecase(wcr repository.WordCountRepository, ftr repository.FrequencyTableRepository) updateFrequencyTableUsecase {
+	return updateFrequencyTableUsecase{
+		wcr: wcr,
+		ftr: ftr,
+	}
+}
+
+type updateFrequencyTableUsecase struct {
+	wcr repository.WordCountRepository
+	ftr repository.FrequencyTableRepository
+}
+
+// Refresh retrieves the entity.FrequencyTable identified by the given ID, extracts again the
+// word count from its source code repository, with the same options used on its creation,
+// and replaces its values and revision. A branch is extracted at its latest commit. The child
+// frequency tables of its modules or packages are refreshed along with it, so refreshing a child
+// refreshes its parent instead. The tables without a source, such as the merged ones, can't be
+// refreshed, so repository.ErrNotExtracted is returned.
+func (uc updateFrequencyTableUsecase) Refresh(ctx context.Context, id int64) (entity.FrequencyTable, error) {
+	ft, err := uc.ftr.Get(ctx, id)
+	if err != nil {
+		return entity.FrequencyTable{}, err
+	}
+
+	if ft.ParentID != 0 {
+		if _, err := uc.Refresh(ctx, ft.ParentID); err != nil {
+			return entity.FrequencyTable{}, err
+		}
+		return uc.ftr.Get(ctx, id)
+	}
+
+	if ft.Source == "" {
+		return entity.FrequencyTable{}, repository.ErrNotExtracted
+	}
+
+	var options entity.ExtractionOptions
+	if ft.Options != nil {
+		options = *ft.Options
+	}
+
+	extraction, err := uc.wcr.Extract(ctx, ft.Source, options)
+	if err != nil {
+		return entity.FrequencyTable{}, err
+	}
+	ft.Values = extraction.Values
+	ft.Options = &extraction.Options
+	ft.Revision = extraction.Revision
+	ft.Report = &extraction.Report
+	ft.LastUpdated = time.Now()
+
+	if err := uc.ftr.Update(ctx, ft); err != nil {
+		return entity.FrequencyTable{}, err
+	}
+
+	if err := saveParts(ctx, uc.ftr, ft, extraction.Parts); err != nil {
+		return entity.FrequencyTable{}, err
+	}
+
+	return ft, nil
+}
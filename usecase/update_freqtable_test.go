@@ -0,0 +1,156 @@
+package usecase_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/eroatta/freqtable/entity"
+	"github.com/eroatta/freqtable/repository"
+	"github.com/eroatta/freqtable/usecase"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewUpdateFrequencyTableUsecase_ShouldReturnNewInstance(t *testing.T) {
+	uc := usecase.NewUpdateFrequencyTableUsecase(nil, nil)
+
+	assert.NotNil(t, uc)
+}
+
+func TestRefresh_OnUpdateFrequencyTableUsecase_WhenMissingFrequencyTable_ShouldReturnError(t *testing.T) {
+	ftr := testFrequencyTableRepository{
+		frequencyTables: map[int64]entity.FrequencyTable{},
+	}
+
+	uc := usecase.NewUpdateFrequencyTableUsecase(nil, ftr)
+	ft, err := uc.Refresh(context.TODO(), 1)
+
+	assert.Equal(t, repository.ErrNoResults, err)
+	assert.Equal(t, entity.FrequencyTable{}, ft)
+}
+
+func TestRefresh_OnUpdateFrequencyTableUsecase_WhenErrorCounting_ShouldReturnError(t *testing.T) {
+	wcr := testWordCountRepository{
+		extractions: map[string]map[string]int{},
+		err:         errors.New("error while extracting"),
+	}
+
+	ftr := testFrequencyTableRepository{
+		frequencyTables: map[int64]entity.FrequencyTable{
+			1: {ID: 1, Name: "https://github.com/eroatta/freqtable", Source: "https://github.com/eroatta/freqtable", Values: map[string]int{"frequency": 2}},
+		},
+	}
+
+	uc := usecase.NewUpdateFrequencyTableUsecase(wcr, ftr)
+	ft, err := uc.Refresh(context.TODO(), 1)
+
+	assert.EqualError(t, err, "error while extracting")
+	assert.Equal(t, entity.FrequencyTable{}, ft)
+}
+
+func TestRefresh_OnUpdateFrequencyTableUsecase_WhenUpdatingResults_ShouldReturnError(t *testing.T) {
+	wcr := testWordCountRepository{
+		extractions: map[string]map[string]int{
+			"https://github.com/eroatta/freqtable": map[string]int{"frequency": 3},
+		},
+	}
+
+	ftr := testFrequencyTableRepository{
+		frequencyTables: map[int64]entity.FrequencyTable{
+			1: {ID: 1, Name: "https://github.com/eroatta/freqtable", Source: "https://github.com/eroatta/freqtable", Values: map[string]int{"frequency": 2}},
+		},
+		err: errors.New("error while persisting"),
+	}
+
+	uc := usecase.NewUpdateFrequencyTableUsecase(wcr, ftr)
+	ft, err := uc.Refresh(context.TODO(), 1)
+
+	assert.EqualError(t, err, "error while persisting")
+	assert.Equal(t, entity.FrequencyTable{}, ft)
+}
+
+func TestRefresh_OnUpdateFrequencyTableUsecase_ShouldReplaceValues(t *testing.T) {
+	wcr := testWordCountRepository{
+		extractions: map[string]map[string]int{
+			"https://github.com/eroatta/freqtable": map[string]int{
+				"frequency": 3,
+				"table":     1,
+			},
+		},
+		revision: &entity.Revision{Hash: "0a1b2c3d4e5f60718293a4b5c6d7e8f901234567"},
+	}
+
+	created := time.Now().Add(-24 * time.Hour)
+	var updated entity.FrequencyTable
+	ftr := testFrequencyTableRepository{
+		frequencyTables: map[int64]entity.FrequencyTable{
+			1: {ID: 1, Name: "https://github.com/eroatta/freqtable@main", Source: "https://github.com/eroatta/freqtable", DateCreated: created,
+				Values: map[string]int{"frequency": 2}, Options: &entity.ExtractionOptions{Ref: "main", Exclude: []string{"vendor"}}},
+		},
+		updated: &updated,
+	}
+
+	uc := usecase.NewUpdateFrequencyTableUsecase(wcr, ftr)
+	ft, err := uc.Refresh(context.TODO(), 1)
+
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), ft.ID)
+	assert.Equal(t, created, ft.DateCreated)
+	assert.False(t, ft.LastUpdated.IsZero())
+	assert.Equal(t, map[string]int{"frequency": 3, "table": 1}, ft.Values)
+	assert.Equal(t, &entity.ExtractionOptions{Ref: "main", Exclude: []string{"vendor"}}, ft.Options)
+	assert.Equal(t, &entity.Revision{Hash: "0a1b2c3d4e5f60718293a4b5c6d7e8f901234567"}, ft.Revision)
+	assert.Equal(t, &entity.ExtractionReport{GoFiles: 1, ParsedFiles: 1}, ft.Report)
+	assert.Equal(t, ft, updated)
+}
+
+func TestRefresh_OnUpdateFrequencyTableUsecase_WhenChildFrequencyTable_ShouldRefreshParentAndChildren(t *testing.T) {
+	wcr := testWordCountRepository{
+		extractions: map[string]map[string]int{
+			"https://github.com/eroatta/freqtable": map[string]int{"frequency": 3, "table": 4},
+		},
+		parts: []entity.ExtractionPart{
+			{Path: "api", Values: map[string]int{"table": 4}},
+			{Path: "storage", Values: map[string]int{"frequency": 3}},
+		},
+	}
+	options := entity.ExtractionOptions{Granularity: entity.GranularityModule}
+	tables := map[int64]entity.FrequencyTable{
+		1: {ID: 1, Name: "https://github.com/eroatta/freqtable", Source: "https://github.com/eroatta/freqtable", Options: &options, Values: map[string]int{"table": 1}},
+		2: {ID: 2, Name: "https://github.com/eroatta/freqtable#api", Source: "https://github.com/eroatta/freqtable", ParentID: 1, Path: "api", Values: map[string]int{"table": 1}},
+	}
+
+	uc := usecase.NewUpdateFrequencyTableUsecase(wcr, testHierarchyRepository{tables: tables})
+	ft, err := uc.Refresh(context.TODO(), 2)
+
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2), ft.ID)
+	assert.Equal(t, map[string]int{"table": 4}, ft.Values)
+	assert.False(t, ft.LastUpdated.IsZero())
+	assert.Equal(t, map[string]int{"frequency": 3, "table": 4}, tables[1].Values)
+	assert.Equal(t, 3, len(tables))
+	assert.Equal(t, "https://github.com/eroatta/freqtable#storage", tables[3].Name)
+	assert.Equal(t, "https://github.com/eroatta/freqtable", tables[3].Source)
+	assert.Equal(t, int64(1), tables[3].ParentID)
+}
+
+func TestRefresh_OnUpdateFrequencyTableUsecase_WhenMergedFrequencyTable_ShouldReturnError(t *testing.T) {
+	wcr := testWordCountRepository{
+		extractions: map[string]map[string]int{
+			"global": map[string]int{"frequency": 3},
+		},
+	}
+
+	ftr := testFrequencyTableRepository{
+		frequencyTables: map[int64]entity.FrequencyTable{
+			1: {ID: 1, Name: "global", Values: map[string]int{"frequency": 2}},
+		},
+	}
+
+	uc := usecase.NewUpdateFrequencyTableUsecase(wcr, ftr)
+	ft, err := uc.Refresh(context.TODO(), 1)
+
+	assert.Equal(t, repository.ErrNotExtracted, err)
+	assert.Equal(t, entity.FrequencyTable{}, ft)
+}
@@ -0,0 +1,13 @@
+package usecase
+
+// Usecases groups the use cases of the application, so the adapters exposing them, such as the
+// REST API or the command line, share a single set.
+type Usecases struct {
+	Create CreateFrequencyTableUsecase
+	Merge  MergeFrequencyTableUsecase
+	Get    GetFrequencyTableUsecase
+	Update UpdateFrequencyTableUsecase
+	Job    ExtractionJobUsecase
+	Query  QueryFrequencyTableUsecase
+	Series SeriesUsecase
+}